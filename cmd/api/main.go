@@ -13,7 +13,9 @@ import (
 
 	"github.com/arasvet/microtube/internal/config"
 	apihttp "github.com/arasvet/microtube/internal/http"
+	"github.com/arasvet/microtube/internal/jobs"
 	"github.com/arasvet/microtube/internal/repo"
+	"github.com/arasvet/microtube/internal/usecase"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -63,10 +65,21 @@ func main() {
 	// Repos
 	repos := repo.New(dbpool, rdb)
 
+	// Background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	jobs.Setup(jobsCtx, repos, cfg)
+	// Агрегатор live-статистики публикует события реплики и принимает чужие, пока работают фоновые задачи
+	liveStatsUC := usecase.NewLiveStatsUC(repos.Postgres, repos.Redis, cfg.LiveViewerWindow)
+	go liveStatsUC.Run(jobsCtx)
+	// Выдачи пишутся в БД пачками
+	impressionsUC := usecase.NewImpressionsUC(repos.Postgres, cfg)
+	go impressionsUC.Run(jobsCtx)
+
 	// Router
 	r := chi.NewRouter()
-	apihttp.SetupMiddleware(r, cfg.JWTSecret)
-	apihttp.SetupRoutes(r, repos, cfg)
+	apihttp.SetupMiddleware(r, cfg.JWTSecret, cfg.TrustedProxies)
+	apihttp.SetupRoutes(r, repos, cfg, liveStatsUC, impressionsUC)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.APIHttpPort,
@@ -95,4 +108,6 @@ func main() {
 	ctxShutdown, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancelShutdown()
 	_ = srv.Shutdown(ctxShutdown)
+	stopJobs()
+	impressionsUC.Flush(ctxShutdown)
 }
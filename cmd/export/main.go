@@ -0,0 +1,99 @@
+// Команда export выгружает аналитику (video_daily, timeseries, events) за период
+// в CSV, NDJSON или Parquet — в файл или stdout, тем же кодом, что и GET /export/{dataset}.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/arasvet/microtube/internal/usecase"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	// Логи в stderr: stdout может быть самой выгрузкой
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	slog.SetDefault(logger)
+
+	cfg := config.MustLoad()
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	dataset := flag.String("dataset", string(domain.ExportVideoDaily), "набор: video_daily, timeseries, events")
+	format := flag.String("format", string(domain.ExportCSV), "формат: csv, ndjson, parquet")
+	from := flag.String("from", time.Now().UTC().AddDate(0, 0, -29).Format(time.DateOnly), "первый день (YYYY-MM-DD)")
+	to := flag.String("to", today, "последний день включительно (YYYY-MM-DD)")
+	columns := flag.String("columns", "", "колонки через запятую (по умолчанию все)")
+	gz := flag.Bool("gzip", false, "сжать выгрузку gzip")
+	out := flag.String("out", "", "файл выгрузки (по умолчанию stdout)")
+	granularity := flag.String("granularity", string(domain.GranularityDay), "timeseries: hour, day, week, month")
+	breakdown := flag.String("breakdown", "", "timeseries: tag, lang, author")
+	top := flag.Int("top", 0, "timeseries: сколько значений разбивки выгружать")
+	flag.Parse()
+
+	p := domain.ExportParams{
+		Dataset: domain.ExportDataset(*dataset),
+		Format:  domain.ExportFormat(*format),
+		Columns: domain.ParseExportColumns(*columns),
+		Gzip:    *gz,
+		Timeseries: domain.TimeseriesParams{
+			Granularity: domain.StatsGranularity(*granularity),
+			Breakdown:   domain.StatsBreakdown(*breakdown),
+			Top:         *top,
+		},
+	}
+	var err error
+	if p.From, err = time.Parse(time.DateOnly, *from); err != nil {
+		fail("invalid -from", err)
+	}
+	if p.To, err = time.Parse(time.DateOnly, *to); err != nil {
+		fail("invalid -to", err)
+	}
+	if err := p.Validate(); err != nil {
+		fail("invalid export parameters", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	dbpool, err := pgxpool.New(ctx, cfg.PostgresURL())
+	if err != nil {
+		fail("cannot create postgres pool", err)
+	}
+	defer dbpool.Close()
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fail("cannot create output file", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	buf := bufio.NewWriterSize(w, 1<<20)
+
+	started := time.Now()
+	uc := usecase.NewExportUC(&repo.PostgresRepo{DB: dbpool})
+	if err := uc.Export(ctx, p, buf); err != nil {
+		fail("export failed", err)
+	}
+	if err := buf.Flush(); err != nil {
+		fail("cannot write output", err)
+	}
+	slog.Info("export done", "dataset", p.Dataset, "format", p.Format, "took", time.Since(started))
+}
+
+func fail(msg string, err error) {
+	slog.Error(msg, slog.String("err", err.Error()))
+	os.Exit(1)
+}
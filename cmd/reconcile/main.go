@@ -0,0 +1,93 @@
+// Команда reconcile пересчитывает video_counters, video_daily и video_hourly из сырых событий
+// для набора видео или периода, печатает отчёт о расхождениях (JSON в stdout)
+// и с -repair исправляет их, не останавливая приём событий.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/arasvet/microtube/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	// Логи в stderr: stdout — отчёт
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	slog.SetDefault(logger)
+
+	cfg := config.MustLoad()
+
+	today := time.Now().UTC().Format(time.DateOnly)
+	from := flag.String("from", today, "первый день (YYYY-MM-DD)")
+	to := flag.String("to", today, "последний день включительно (YYYY-MM-DD)")
+	videos := flag.String("videos", "", "id видео через запятую (по умолчанию все)")
+	tables := flag.String("tables", "", "таблицы через запятую: video_counters, video_daily, video_hourly (по умолчанию все)")
+	repair := flag.Bool("repair", false, "исправить расхождения")
+	flag.Parse()
+
+	p := domain.ReconcileParams{Repair: *repair}
+	var err error
+	if p.From, err = time.Parse(time.DateOnly, *from); err != nil {
+		fail("invalid -from", err)
+	}
+	if p.To, err = time.Parse(time.DateOnly, *to); err != nil {
+		fail("invalid -to", err)
+	}
+	for _, s := range strings.Split(*videos, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := uuid.Parse(s)
+		if err != nil {
+			fail("invalid -videos", err)
+		}
+		p.VideoIDs = append(p.VideoIDs, id)
+	}
+	for _, s := range strings.Split(*tables, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			p.Tables = append(p.Tables, domain.CounterTable(s))
+		}
+	}
+	if err := p.Validate(); err != nil {
+		fail("invalid reconcile parameters", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	dbpool, err := pgxpool.New(ctx, cfg.PostgresURL())
+	if err != nil {
+		fail("cannot create postgres pool", err)
+	}
+	defer dbpool.Close()
+
+	started := time.Now()
+	uc := usecase.NewReconcileUC(&repo.PostgresRepo{DB: dbpool})
+	rep, err := uc.Reconcile(ctx, p)
+	if err != nil {
+		fail("reconcile failed", err)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(rep); err != nil {
+		fail("cannot write report", err)
+	}
+	slog.Info("reconcile done", "diffs", rep.Diffs, "repaired", rep.Repaired, "took", time.Since(started))
+}
+
+func fail(msg string, err error) {
+	slog.Error(msg, slog.String("err", err.Error()))
+	os.Exit(1)
+}
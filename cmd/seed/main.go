@@ -99,9 +99,15 @@ func main() {
 		etype := eventTypes[rand.Intn(len(eventTypes))]
 
 		_, err := pool.Exec(ctx, `
+			WITH id AS (
+				INSERT INTO app.event_ids (event_id, ts)
+				VALUES ($1, now() - ($2 * interval '1 minute'))
+				ON CONFLICT (event_id) DO NOTHING
+				RETURNING event_id, ts
+			)
 			INSERT INTO app.events (event_id, ts, type, session_id, video_id, query, dwell_ms)
-			VALUES ($1, now() - ($2 * interval '1 minute'), $3, $4, $5, $6, $7)
-			ON CONFLICT (event_id) DO NOTHING`,
+			SELECT event_id, ts, $3::app.event_type, $4::text, $5::uuid, $6::text, $7::int FROM id
+			ON CONFLICT (event_id, ts) DO NOTHING`,
 			eventID,
 			rand.Intn(60*24*30), // минуты за последний месяц
 			etype,
@@ -0,0 +1,150 @@
+// Команда train обучает ALS-модель по событиям из app.events
+// и выгружает факторы пользователей и видео в Postgres (или в JSON-файл с -out).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/arasvet/microtube/internal/als"
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// fileModel формат выгрузки в файл
+type fileModel struct {
+	Version   int64                   `json:"version"`
+	Factors   int                     `json:"factors"`
+	Lambda    float64                 `json:"lambda"`
+	Alpha     float64                 `json:"alpha"`
+	TrainedAt time.Time               `json:"trained_at"`
+	Users     map[uuid.UUID][]float64 `json:"users"`
+	Videos    map[uuid.UUID][]float64 `json:"videos"`
+}
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	slog.SetDefault(logger)
+
+	cfg := config.MustLoad()
+	p := als.DefaultParams()
+
+	flag.IntVar(&p.Factors, "factors", p.Factors, "размерность факторов")
+	flag.IntVar(&p.Iterations, "iterations", p.Iterations, "число итераций ALS")
+	flag.Float64Var(&p.Lambda, "lambda", p.Lambda, "L2-регуляризация")
+	flag.Float64Var(&p.Alpha, "alpha", p.Alpha, "масштаб уверенности для неявных сигналов")
+	flag.IntVar(&p.Workers, "workers", 0, "число горутин (0 — по числу CPU)")
+	lookback := flag.Duration("lookback", cfg.ALSLookback, "за какой период брать события")
+	out := flag.String("out", "", "записать модель в JSON-файл вместо Postgres")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	dbpool, err := pgxpool.New(ctx, cfg.PostgresURL())
+	if err != nil {
+		slog.Error("cannot create postgres pool", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	defer dbpool.Close()
+
+	store := &repo.PostgresRepo{DB: dbpool}
+
+	started := time.Now()
+	interactions, err := store.Interactions(ctx, started.Add(-*lookback), nil)
+	if err != nil {
+		slog.Error("cannot load interactions", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	if len(interactions) == 0 {
+		slog.Warn("no interactions, nothing to train")
+		return
+	}
+
+	// Индексы пользователей и видео в матрице
+	userIdx := map[uuid.UUID]int{}
+	itemIdx := map[uuid.UUID]int{}
+	var userIDs, itemIDs []uuid.UUID
+	data := make([]als.Interaction, 0, len(interactions))
+	for _, in := range interactions {
+		u, ok := userIdx[in.UserID]
+		if !ok {
+			u = len(userIDs)
+			userIdx[in.UserID] = u
+			userIDs = append(userIDs, in.UserID)
+		}
+		i, ok := itemIdx[in.VideoID]
+		if !ok {
+			i = len(itemIDs)
+			itemIdx[in.VideoID] = i
+			itemIDs = append(itemIDs, in.VideoID)
+		}
+		data = append(data, als.Interaction{User: u, Item: i, Value: in.Weight})
+	}
+
+	slog.Info("training",
+		slog.Int("users", len(userIDs)),
+		slog.Int("videos", len(itemIDs)),
+		slog.Int("interactions", len(data)),
+		slog.Int("factors", p.Factors),
+		slog.Int("iterations", p.Iterations))
+
+	model := als.Train(len(userIDs), len(itemIDs), data, p)
+
+	users := make(map[uuid.UUID][]float64, len(userIDs))
+	for u, id := range userIDs {
+		users[id] = model.UserFactors[u]
+	}
+	videos := make(map[uuid.UUID][]float64, len(itemIDs))
+	for i, id := range itemIDs {
+		videos[id] = model.ItemFactors[i]
+	}
+
+	meta := domain.FactorModel{
+		Version: started.Unix(),
+		Factors: p.Factors,
+		Lambda:  p.Lambda,
+		Alpha:   p.Alpha,
+	}
+
+	if *out != "" {
+		err = writeFile(*out, fileModel{
+			Version:   meta.Version,
+			Factors:   meta.Factors,
+			Lambda:    meta.Lambda,
+			Alpha:     meta.Alpha,
+			TrainedAt: time.Now().UTC(),
+			Users:     users,
+			Videos:    videos,
+		})
+	} else {
+		err = store.SaveFactorModel(ctx, meta, users, videos)
+	}
+	if err != nil {
+		slog.Error("cannot save model", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+
+	slog.Info("model saved",
+		slog.Int64("version", meta.Version),
+		slog.Duration("took", time.Since(started)))
+}
+
+func writeFile(path string, m fileModel) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(m); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
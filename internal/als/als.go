@@ -0,0 +1,181 @@
+// Package als обучает матричную факторизацию для неявной обратной связи
+// (implicit ALS, Hu, Koren, Volinsky 2008) на CPU без внешних зависимостей.
+package als
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+// Interaction агрегированный сигнал пользователя по видео (индексы в матрице)
+type Interaction struct {
+	User  int
+	Item  int
+	Value float64 // сила сигнала r_ui > 0; уверенность c_ui = 1 + Alpha*r_ui
+}
+
+// Params гиперпараметры обучения
+type Params struct {
+	Factors    int     // размерность факторов
+	Iterations int     // число проходов (пользователи + видео)
+	Lambda     float64 // L2-регуляризация
+	Alpha      float64 // масштаб уверенности
+	Seed       int64   // seed начальной инициализации
+	Workers    int     // число горутин (0 — по числу CPU)
+}
+
+// DefaultParams разумные значения для небольшого каталога
+func DefaultParams() Params {
+	return Params{Factors: 32, Iterations: 10, Lambda: 0.1, Alpha: 40, Seed: 1}
+}
+
+// Model обученные факторы: UserFactors[u] и ItemFactors[i] длины Factors
+type Model struct {
+	Factors     int
+	UserFactors [][]float64
+	ItemFactors [][]float64
+}
+
+// Train обучает модель на interactions для users пользователей и items видео
+func Train(users, items int, interactions []Interaction, p Params) *Model {
+	if p.Workers <= 0 {
+		p.Workers = runtime.NumCPU()
+	}
+
+	byUser := make([][]entry, users)
+	byItem := make([][]entry, items)
+	for _, in := range interactions {
+		byUser[in.User] = append(byUser[in.User], entry{idx: in.Item, value: in.Value})
+		byItem[in.Item] = append(byItem[in.Item], entry{idx: in.User, value: in.Value})
+	}
+
+	rnd := rand.New(rand.NewSource(p.Seed))
+	m := &Model{
+		Factors:     p.Factors,
+		UserFactors: randomMatrix(rnd, users, p.Factors),
+		ItemFactors: randomMatrix(rnd, items, p.Factors),
+	}
+
+	for it := 0; it < p.Iterations; it++ {
+		solveAll(m.UserFactors, m.ItemFactors, byUser, p)
+		solveAll(m.ItemFactors, m.UserFactors, byItem, p)
+	}
+	return m
+}
+
+// Gram возвращает YᵀY для факторов видео — нужен для FoldIn
+func Gram(factors [][]float64, k int) [][]float64 {
+	g := newSquare(k)
+	for _, y := range factors {
+		for a := 0; a < k; a++ {
+			for b := a; b < k; b++ {
+				g[a][b] += y[a] * y[b]
+			}
+		}
+	}
+	for a := 0; a < k; a++ {
+		for b := 0; b < a; b++ {
+			g[a][b] = g[b][a]
+		}
+	}
+	return g
+}
+
+// FoldIn считает факторы нового пользователя по его сигналам при фиксированных факторах видео
+// (один шаг ALS без переобучения). items — строки факторов видео, values — силы сигналов.
+func FoldIn(gram [][]float64, items [][]float64, values []float64, p Params) []float64 {
+	entries := make([]entry, len(items))
+	for i := range items {
+		entries[i] = entry{idx: i, value: values[i]}
+	}
+	x := make([]float64, len(gram))
+	solveOne(x, gram, items, entries, p)
+	return x
+}
+
+type entry struct {
+	idx   int
+	value float64
+}
+
+// solveAll пересчитывает все строки target при фиксированных fixed
+func solveAll(target, fixed [][]float64, rows [][]entry, p Params) {
+	k := p.Factors
+	gram := Gram(fixed, k)
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < p.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range next {
+				solveOne(target[u], gram, fixed, rows[u], p)
+			}
+		}()
+	}
+	for u := range target {
+		next <- u
+	}
+	close(next)
+	wg.Wait()
+}
+
+// solveOne решает (YᵀY + Yᵀ(Cu−I)Y + λI) x = Yᵀ Cu p(u) и пишет результат в x
+func solveOne(x []float64, gram [][]float64, fixed [][]float64, row []entry, p Params) {
+	k := len(x)
+	if len(row) == 0 {
+		for i := range x {
+			x[i] = 0
+		}
+		return
+	}
+
+	a := newSquare(k)
+	for i := 0; i < k; i++ {
+		copy(a[i], gram[i])
+		a[i][i] += p.Lambda
+	}
+	b := make([]float64, k)
+	for _, e := range row {
+		y := fixed[e.idx]
+		c := 1 + p.Alpha*e.value
+		for i := 0; i < k; i++ {
+			b[i] += c * y[i]
+			for j := i; j < k; j++ {
+				a[i][j] += (c - 1) * y[i] * y[j]
+			}
+		}
+	}
+	for i := 0; i < k; i++ {
+		for j := 0; j < i; j++ {
+			a[i][j] = a[j][i]
+		}
+	}
+
+	if !choleskySolve(a, b, x) {
+		for i := range x {
+			x[i] = 0
+		}
+	}
+}
+
+func randomMatrix(rnd *rand.Rand, rows, cols int) [][]float64 {
+	m := make([][]float64, rows)
+	for i := range m {
+		m[i] = make([]float64, cols)
+		for j := range m[i] {
+			m[i][j] = rnd.NormFloat64() * 0.01
+		}
+	}
+	return m
+}
+
+func newSquare(k int) [][]float64 {
+	m := make([][]float64, k)
+	for i := range m {
+		m[i] = make([]float64, k)
+	}
+	return m
+}
@@ -0,0 +1,66 @@
+package als
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCholeskySolve(t *testing.T) {
+	a := [][]float64{{4, 2}, {2, 3}}
+	b := []float64{2, 1}
+	x := make([]float64, 2)
+
+	assert.True(t, choleskySolve(a, b, x))
+	assert.InDelta(t, 0.5, x[0], 1e-9)
+	assert.InDelta(t, 0, x[1], 1e-9)
+}
+
+func TestTrain_RecoversBlocks(t *testing.T) {
+	// Две группы пользователей смотрят две непересекающиеся группы видео:
+	// после обучения своё непросмотренное видео должно быть ближе чужого.
+	var data []Interaction
+	for u := 0; u < 10; u++ {
+		base := 0
+		if u >= 5 {
+			base = 4
+		}
+		for i := base; i < base+4; i++ {
+			if u%4 == i%4 {
+				continue // оставляем по одному "непросмотренному" видео своей группы
+			}
+			data = append(data, Interaction{User: u, Item: i, Value: 1})
+		}
+	}
+
+	p := DefaultParams()
+	p.Factors = 4
+	m := Train(10, 8, data, p)
+
+	for u := 0; u < 10; u++ {
+		own, other := u%4, 4+u%4
+		if u >= 5 {
+			own, other = other, own
+		}
+		assert.Greater(t, Dot(m.UserFactors[u], m.ItemFactors[own]), Dot(m.UserFactors[u], m.ItemFactors[other]), "user %d", u)
+	}
+}
+
+func TestFoldIn_MatchesTrainedUser(t *testing.T) {
+	data := []Interaction{
+		{User: 0, Item: 0, Value: 1}, {User: 0, Item: 1, Value: 2},
+		{User: 1, Item: 1, Value: 1}, {User: 1, Item: 2, Value: 1},
+	}
+	p := DefaultParams()
+	p.Factors = 3
+	p.Iterations = 5
+	m := Train(2, 3, data, p)
+
+	// Fold-in по сигналам пользователя 0 даёт вектор, близкий к его видео, а не к чужому
+	x := FoldIn(Gram(m.ItemFactors, p.Factors), [][]float64{m.ItemFactors[0], m.ItemFactors[1]}, []float64{1, 2}, p)
+	for i := range x {
+		assert.False(t, math.IsNaN(x[i]))
+	}
+	assert.Greater(t, Dot(x, m.ItemFactors[1]), Dot(x, m.ItemFactors[2]))
+}
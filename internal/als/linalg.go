@@ -0,0 +1,53 @@
+package als
+
+import "math"
+
+// choleskySolve решает a·x = b для симметричной положительно определённой a.
+// a перезаписывается разложением; false — если матрица не положительно определена.
+func choleskySolve(a [][]float64, b, x []float64) bool {
+	n := len(b)
+	for j := 0; j < n; j++ {
+		sum := a[j][j]
+		for k := 0; k < j; k++ {
+			sum -= a[j][k] * a[j][k]
+		}
+		if sum <= 0 {
+			return false
+		}
+		a[j][j] = math.Sqrt(sum)
+		for i := j + 1; i < n; i++ {
+			s := a[i][j]
+			for k := 0; k < j; k++ {
+				s -= a[i][k] * a[j][k]
+			}
+			a[i][j] = s / a[j][j]
+		}
+	}
+
+	// L·y = b
+	for i := 0; i < n; i++ {
+		s := b[i]
+		for k := 0; k < i; k++ {
+			s -= a[i][k] * x[k]
+		}
+		x[i] = s / a[i][i]
+	}
+	// Lᵀ·x = y
+	for i := n - 1; i >= 0; i-- {
+		s := x[i]
+		for k := i + 1; k < n; k++ {
+			s -= a[k][i] * x[k]
+		}
+		x[i] = s / a[i][i]
+	}
+	return true
+}
+
+// Dot скалярное произведение векторов одинаковой длины
+func Dot(a, b []float64) float64 {
+	var s float64
+	for i := range a {
+		s += a[i] * b[i]
+	}
+	return s
+}
@@ -3,8 +3,10 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,9 +22,91 @@ type Config struct {
 	RedisDB   int
 
 	APIHttpPort string
+	// Прокси перед API (IP и подсети): только от них принимаются X-Forwarded-For и X-Real-IP.
+	// Пусто — адрес клиента берётся из соединения.
+	TrustedProxies []netip.Prefix
 
 	JWTSecret []byte
 	AuthTTL   time.Duration
+
+	// Фоновые задачи (0 — задача выключена)
+	SearchVocabularyRefresh time.Duration
+	TrendingRefresh         time.Duration
+	CovisitationRefresh     time.Duration
+	ProductMetricsRefresh   time.Duration
+	EventPartitionsRefresh  time.Duration
+	ServesRetentionRefresh  time.Duration
+
+	// Продуктовые метрики (DAU/WAU/MAU, когорты, воронка)
+	ProductMetricsLookback time.Duration // сколько последних дней пересчитывать заново: события приходят с опозданием
+
+	// Партиции events (помесячные) и срок хранения сырых событий
+	EventPartitionsAhead  int // на сколько месяцев вперёд создавать партиции
+	EventsRetentionMonths int // сколько полных месяцев хранить помимо текущего (0 — хранить всё)
+
+	// Показы выдачи (serves, impressions)
+	ImpressionsFlushInterval time.Duration // как часто выдачи пишутся в БД пачкой
+	ServesRetention          time.Duration // сколько хранить выдачи и показы, округляется до суток (0 — хранить всё)
+
+	// Live-статистика (/stats/live)
+	LiveViewerWindow time.Duration // зрители — сессии с view_start за это окно; за него же считается live-топ видео
+
+	// Trending-фид
+	TrendingMinEvents   int           // минимум событий за неделю, чтобы попасть в trending
+	TrendingHalfLife    time.Duration // период полураспада активности по дням
+	TrendingAgeHalfLife time.Duration // период полураспада по возрасту видео (0 — без штрафа)
+
+	// Seen-set: недавно досмотренные и показанные видео не рекомендуются повторно
+	SeenCompleteCooldown   time.Duration // через сколько досмотренное видео можно рекомендовать снова
+	SeenImpressionCooldown time.Duration // через сколько показанное в рекомендациях видео можно показать снова (0 — показы не учитываются)
+	SeenMaxItems           int           // сколько последних видео хранить на пользователя
+
+	// Co-visitation: соседи видео по совместным просмотрам в сессиях
+	CovisitationLookback    time.Duration // за какой период берутся события
+	CovisitationMaxGap      int           // максимальное расстояние между просмотрами в сессии
+	CovisitationMinSessions int           // минимум сессий, в которых пара встретилась вместе
+	CovisitationTopN        int           // сколько соседей хранить на видео
+
+	// Коллаборативная фильтрация (ALS, обучается cmd/train)
+	ALSLookback time.Duration // за какой период берутся сигналы для обучения и fold-in
+	ALSCacheTTL time.Duration // как часто API перечитывает факторы видео
+
+	// Конвейер рекомендаций: источники кандидатов в формате name:quota:weight[:timeout]
+	RecsPersonalSources []RecSource   // для авторизованных пользователей
+	RecsColdSources     []RecSource   // для гостей
+	RecsSourceTimeout   time.Duration // таймаут источника по умолчанию
+
+	// MMR-диверсификация выдачи
+	RecsDiversity    float64 // вес разнообразия по умолчанию: 0 — только релевантность, 1 — только разнообразие
+	RecsMaxAuthorRun int     // сколько видео одного автора допустимо подряд (0 — без ограничения)
+
+	// Бандит исследования свежих видео (слот exploration)
+	BanditFreshWindow   time.Duration // сколько после загрузки видео участвует в исследовании
+	BanditGraduateViews int           // после стольких просмотров видео выходит из пула и ранжируется как обычное
+	BanditPoolSize      int           // сколько самых свежих видео рассматривает бандит
+
+	// A/B-эксперименты
+	ExperimentsCacheTTL time.Duration // как часто перечитывается список идущих экспериментов
+
+	// Выгрузки аналитики: каждая держит соединение пула БД до конца потока
+	ExportMaxConcurrent int // сколько выгрузок API отдаёт одновременно, остальным — 429
+
+	// Антифрод событий: помеченные события хранятся, но не учитываются в счётчиках и статистике.
+	// session_id выбирает клиент, поэтому лимит на сессию отсекает лишь наивные повторы,
+	// от накрутки защищает лимит на IP (адрес из соединения или от TrustedProxies).
+	FraudBotUserAgents []string       // подстроки User-Agent ботов, без учёта регистра
+	FraudBlockedIPs    []netip.Prefix // IP и подсети, события с которых помечаются
+	FraudRateWindow    time.Duration  // окно лимитов на просмотры и лайки
+	FraudSessionCap    int            // сколько view_start (и отдельно like) одного видео засчитывается сессии за окно (0 — без лимита)
+	FraudIPCap         int            // то же на IP
+}
+
+// RecSource источник кандидатов в конвейере рекомендаций
+type RecSource struct {
+	Name    string        // имя генератора кандидатов
+	Quota   float64       // доля выдачи, гарантированная источнику
+	Weight  float64       // базовый score кандидатов источника
+	Timeout time.Duration // 0 — RecsSourceTimeout
 }
 
 func MustLoad() Config {
@@ -48,10 +132,67 @@ func MustLoad() Config {
 		RedisPort: getEnv("REDIS_PORT", "6379"),
 		RedisDB:   redisDB,
 
-		APIHttpPort: getEnv("API_HTTP_PORT", "8080"),
+		APIHttpPort:    getEnv("API_HTTP_PORT", "8080"),
+		TrustedProxies: mustPrefixes("TRUSTED_PROXIES", ""),
 
 		JWTSecret: []byte(getEnv("JWT_SECRET", "devsecret")),
 		AuthTTL:   authTTL,
+
+		SearchVocabularyRefresh: mustDuration("SEARCH_VOCABULARY_REFRESH", "10m"),
+		TrendingRefresh:         mustDuration("TRENDING_REFRESH", "5m"),
+		CovisitationRefresh:     mustDuration("COVISITATION_REFRESH", "1h"),
+		ProductMetricsRefresh:   mustDuration("PRODUCT_METRICS_REFRESH", "15m"),
+		EventPartitionsRefresh:  mustDuration("EVENT_PARTITIONS_REFRESH", "6h"),
+		ServesRetentionRefresh:  mustDuration("SERVES_RETENTION_REFRESH", "1h"),
+
+		ProductMetricsLookback: mustDuration("PRODUCT_METRICS_LOOKBACK", "48h"),
+
+		EventPartitionsAhead:  mustInt("EVENT_PARTITIONS_AHEAD", "3"),
+		EventsRetentionMonths: mustInt("EVENTS_RETENTION_MONTHS", "0"),
+
+		ImpressionsFlushInterval: mustDuration("IMPRESSIONS_FLUSH_INTERVAL", "1s"),
+		ServesRetention:          mustDuration("SERVES_RETENTION", "2160h"),
+
+		LiveViewerWindow: mustDuration("LIVE_VIEWER_WINDOW", "5m"),
+
+		TrendingMinEvents:   mustInt("TRENDING_MIN_EVENTS", "5"),
+		TrendingHalfLife:    mustDuration("TRENDING_HALF_LIFE", "48h"),
+		TrendingAgeHalfLife: mustDuration("TRENDING_AGE_HALF_LIFE", "168h"),
+
+		SeenCompleteCooldown:   mustDuration("SEEN_COMPLETE_COOLDOWN", "168h"),
+		SeenImpressionCooldown: mustDuration("SEEN_IMPRESSION_COOLDOWN", "1h"),
+		SeenMaxItems:           mustInt("SEEN_MAX_ITEMS", "500"),
+
+		CovisitationLookback:    mustDuration("COVISITATION_LOOKBACK", "720h"),
+		CovisitationMaxGap:      mustInt("COVISITATION_MAX_GAP", "5"),
+		CovisitationMinSessions: mustInt("COVISITATION_MIN_SESSIONS", "2"),
+		CovisitationTopN:        mustInt("COVISITATION_TOP_N", "20"),
+
+		ALSLookback: mustDuration("ALS_LOOKBACK", "2160h"),
+		ALSCacheTTL: mustDuration("ALS_CACHE_TTL", "10m"),
+
+		RecsPersonalSources: mustRecSources("RECS_PERSONAL_SOURCES",
+			"collaborative:0.2:0.85,user_tags:0.2:0.9,similar:0.2:0.8,popular:0.2:0.7,diversify:0.1:0.6,exploration:0.1:0.5"),
+		RecsColdSources: mustRecSources("RECS_COLD_SOURCES",
+			"popular:0.5:0.8,session_tags:0.3:0.7,diversify:0.2:0.6"),
+		RecsSourceTimeout: mustDuration("RECS_SOURCE_TIMEOUT", "300ms"),
+		RecsDiversity:     mustFloat("RECS_DIVERSITY", "0.3"),
+		RecsMaxAuthorRun:  mustInt("RECS_MAX_AUTHOR_RUN", "2"),
+
+		BanditFreshWindow:   mustDuration("BANDIT_FRESH_WINDOW", "168h"),
+		BanditGraduateViews: mustInt("BANDIT_GRADUATE_VIEWS", "100"),
+		BanditPoolSize:      mustInt("BANDIT_POOL_SIZE", "500"),
+
+		ExperimentsCacheTTL: mustDuration("EXPERIMENTS_CACHE_TTL", "30s"),
+
+		ExportMaxConcurrent: mustInt("EXPORT_MAX_CONCURRENT", "2"),
+
+		FraudBotUserAgents: getList("FRAUD_BOT_USER_AGENTS",
+			"bot,crawler,spider,curl,wget,python-requests,go-http-client,headless,phantomjs,scrapy"),
+		FraudBlockedIPs: mustPrefixes("FRAUD_BLOCKED_IPS", ""),
+		FraudRateWindow: mustDuration("FRAUD_RATE_WINDOW", "1h"),
+		FraudSessionCap: mustInt("FRAUD_SESSION_CAP", "3"),
+		FraudIPCap:      mustInt("FRAUD_IP_CAP", "30"),
 	}
 }
 
@@ -68,3 +209,94 @@ func getEnv(key, def string) string {
 	}
 	return def
 }
+
+func mustDuration(key, def string) time.Duration {
+	d, err := time.ParseDuration(getEnv(key, def))
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return d
+}
+
+func mustInt(key, def string) int {
+	n, err := strconv.Atoi(getEnv(key, def))
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return n
+}
+
+func mustFloat(key, def string) float64 {
+	f, err := strconv.ParseFloat(getEnv(key, def), 64)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return f
+}
+
+// getList значения через запятую в нижнем регистре, пустые пропускаются
+func getList(key, def string) []string {
+	var out []string
+	for _, v := range strings.Split(getEnv(key, def), ",") {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// mustPrefixes разбирает список IP и подсетей вида "203.0.113.7,198.51.100.0/24"
+func mustPrefixes(key, def string) []netip.Prefix {
+	var out []netip.Prefix
+	for _, v := range getList(key, def) {
+		if p, err := netip.ParsePrefix(v); err == nil {
+			out = append(out, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			log.Fatalf("invalid %s: %q is neither IP nor CIDR", key, v)
+		}
+		out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return out
+}
+
+// mustRecSources разбирает список источников из переменной окружения key
+func mustRecSources(key, def string) []RecSource {
+	sources, err := ParseRecSources(getEnv(key, def))
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return sources
+}
+
+// ParseRecSources разбирает список источников вида "popular:0.5:0.8,session_tags:0.3:0.7:200ms"
+func ParseRecSources(s string) ([]RecSource, error) {
+	var sources []RecSource
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) < 3 || len(parts) > 4 {
+			return nil, fmt.Errorf("%q must be name:quota:weight[:timeout]", item)
+		}
+		src := RecSource{Name: parts[0]}
+		var err error
+		if src.Quota, err = strconv.ParseFloat(parts[1], 64); err != nil || src.Quota < 0 || src.Quota > 1 {
+			return nil, fmt.Errorf("bad quota in %q", item)
+		}
+		if src.Weight, err = strconv.ParseFloat(parts[2], 64); err != nil {
+			return nil, fmt.Errorf("bad weight in %q", item)
+		}
+		if len(parts) == 4 {
+			if src.Timeout, err = time.ParseDuration(parts[3]); err != nil {
+				return nil, fmt.Errorf("bad timeout in %q", item)
+			}
+		}
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
@@ -0,0 +1,15 @@
+package domain
+
+// BanditArm свежее видео в пуле исследования со статистикой показов в слоте exploration
+type BanditArm struct {
+	Video       Video
+	Impressions int64 // показов в слоте exploration
+	Clicks      int64 // кликов и начатых просмотров по таким показам (AttributeClick)
+}
+
+// Posterior параметры бета-распределения CTR при равномерном априорном Beta(1, 1).
+// Показы пишутся асинхронно и могут отстать от кликов: клики сверх показов не делают видео лучше, чем 100% CTR.
+func (a BanditArm) Posterior() (alpha, beta float64) {
+	clicks := min(a.Clicks, a.Impressions)
+	return float64(1 + clicks), float64(1 + a.Impressions - clicks)
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxCommentLength максимальная длина комментария в символах
+const MaxCommentLength = 5000
+
+// Comment комментарий к видео. Треды двухуровневые: ответ на ответ
+// прикрепляется к корневому комментарию.
+type Comment struct {
+	ID        uuid.UUID
+	VideoID   uuid.UUID
+	UserID    uuid.UUID
+	ParentID  *uuid.UUID
+	Body      string
+	Likes     int64
+	Replies   int64 // число неудалённых ответов
+	Deleted   bool  // удалён автором; остаётся в выдаче, пока у него есть ответы
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NormalizeCommentBody обрезает пробелы и проверяет длину текста комментария
+func NormalizeCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || len([]rune(body)) > MaxCommentLength {
+		return "", ErrInvalidComment
+	}
+	return body, nil
+}
+
+// CommentListParams параметры получения комментариев
+type CommentListParams struct {
+	VideoID  uuid.UUID
+	ParentID *uuid.UUID // nil — корневые комментарии, иначе ответы на указанный комментарий
+	Cursor   *Cursor
+	Limit    int
+}
+
+// Validate проверяет корректность параметров
+func (p *CommentListParams) Validate() error {
+	if p.VideoID == uuid.Nil {
+		return ErrVideoNotFound
+	}
+	if p.Limit <= 0 {
+		p.Limit = 20 // значение по умолчанию
+	}
+	if p.Limit > 100 { // ограничиваем максимальный размер выборки
+		p.Limit = 100
+	}
+	return nil
+}
+
+// CommentPage страница комментариев
+type CommentPage struct {
+	Comments   []Comment
+	NextCursor string // пусто, если страниц больше нет
+}
+
+var (
+	ErrInvalidComment   = errors.New("comment body must be 1..5000 characters")
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrCommentForbidden = errors.New("only the author can modify the comment")
+	ErrVideoNotFound    = errors.New("video not found")
+)
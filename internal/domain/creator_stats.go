@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Источники трафика просмотров: выдача, из которой пришёл view_start (по serve_id)
+const (
+	TrafficSearch          = "search"
+	TrafficFeed            = "feed"
+	TrafficRecommendations = "recommendations"
+	TrafficOther           = "other" // без serve_id: прямая ссылка, плейлист, старые клиенты
+)
+
+const (
+	// MaxCreatorStatsDays на сколько дней назад можно смотреть статистику автора
+	MaxCreatorStatsDays = 365
+	// CreatorTopQueries сколько поисковых запросов отдавать
+	CreatorTopQueries = 10
+	// CreatorTopVideos сколько видео канала отдавать в сводке
+	CreatorTopVideos = 20
+)
+
+// CreatorStatsFilter видео автора, по которым считается статистика.
+// VideoID == nil — все видео автора (канал).
+type CreatorStatsFilter struct {
+	AuthorID uuid.UUID
+	VideoID  *uuid.UUID
+	From     time.Time // первый день периода (UTC)
+	To       time.Time // последний день периода включительно
+}
+
+// Validate проверяет период: не пустой и не длиннее MaxCreatorStatsDays
+func (f *CreatorStatsFilter) Validate() error {
+	f.From = f.From.UTC().Truncate(24 * time.Hour)
+	f.To = f.To.UTC().Truncate(24 * time.Hour)
+	if f.To.Before(f.From) || f.To.Sub(f.From) > MaxCreatorStatsDays*24*time.Hour {
+		return ErrInvalidStatsPeriod
+	}
+	return nil
+}
+
+// CreatorDayRaw сырые суммы за день из video_daily
+type CreatorDayRaw struct {
+	Day        time.Time
+	Views      int64
+	Completes  int64
+	Likes      int64
+	DwellMsSum int64
+	ViewSecs   int64 // сумма views × DurationS: сколько секунд было бы при досмотре каждого просмотра
+}
+
+// CreatorDayStats метрики за день
+type CreatorDayStats struct {
+	Day              time.Time
+	Views            int64
+	Completes        int64
+	Likes            int64
+	CompletionRate   float64 // completes / views
+	AvgWatchFraction float64 // среднее время просмотра в долях DurationS
+	LikeRate         float64 // likes / views
+}
+
+// NewCreatorDayStats считает доли по сырым суммам
+func NewCreatorDayStats(r CreatorDayRaw) CreatorDayStats {
+	s := CreatorDayStats{Day: r.Day, Views: r.Views, Completes: r.Completes, Likes: r.Likes}
+	if r.Views > 0 {
+		s.CompletionRate = float64(r.Completes) / float64(r.Views)
+		s.LikeRate = float64(r.Likes) / float64(r.Views)
+	}
+	if r.ViewSecs > 0 {
+		s.AvgWatchFraction = float64(r.DwellMsSum) / 1000 / float64(r.ViewSecs)
+	}
+	return s
+}
+
+// TrafficSource доля просмотров из источника
+type TrafficSource struct {
+	Source string
+	Views  int64
+	Share  float64
+}
+
+// QueryCount поисковый запрос, приведший к видео, и число сессий
+type QueryCount struct {
+	Query    string
+	Sessions int64
+}
+
+// VideoStatsSummary итоги видео канала за период
+type VideoStatsSummary struct {
+	Video Video
+	Stats CreatorDayStats // Day не заполнен
+}
+
+// CreatorStats статистика видео или канала за период
+type CreatorStats struct {
+	AuthorID   uuid.UUID
+	Video      *Video // nil для канала
+	From       time.Time
+	To         time.Time
+	Totals     CreatorDayStats // итоги периода, Day не заполнен
+	Daily      []CreatorDayStats
+	Traffic    []TrafficSource
+	TopQueries []QueryCount
+	Videos     []VideoStatsSummary // только для канала
+}
+
+var (
+	ErrVideoStatsForbidden = errors.New("only the author can view video stats")
+	ErrInvalidStatsPeriod  = errors.New("invalid period: from must not be after to, at most 365 days")
+)
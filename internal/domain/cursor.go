@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor позиция keyset-пагинации: записи упорядочены по (TS, ID) по убыванию
+type Cursor struct {
+	TS time.Time
+	ID uuid.UUID
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Encode возвращает непрозрачную строку курсора для клиента
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.TS.UnixNano(), 10) + ":" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor разбирает строку курсора; пустая строка означает первую страницу
+func DecodeCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	tsStr, idStr, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(tsStr, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{TS: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
@@ -15,23 +15,38 @@ const (
 	EventLike         EventType = "like"
 	EventSearchQuery  EventType = "search_query"
 	EventClickResult  EventType = "click_result"
+	EventProgress     EventType = "progress" // позиция воспроизведения, для кривых удержания
+
+	// Негативные сигналы
+	EventDislike          EventType = "dislike"            // дизлайк видео, понижает его теги для пользователя
+	EventUnlike           EventType = "unlike"             // снятие лайка
+	EventHideVideo        EventType = "hide_video"         // скрыть видео из рекомендаций и фидов
+	EventNotInterestedTag EventType = "not_interested_tag" // не интересна тема (tag)
 )
 
 var ErrInvalidEvent = errors.New("invalid event")
 
 type Event struct {
-	EventID   uuid.UUID
-	TS        time.Time
-	Type      EventType
-	SessionID string
-	UserID    uuid.UUID
-	VideoID   uuid.UUID
-	Query     string
-	DwellMs   int
+	EventID    uuid.UUID
+	TS         time.Time
+	Type       EventType
+	SessionID  string
+	UserID     uuid.UUID
+	VideoID    uuid.UUID
+	Query      string
+	DwellMs    int
+	Tag        string    // для not_interested_tag
+	ServeID    uuid.UUID // выдача, из которой пришёл клик или просмотр (uuid.Nil — неизвестна)
+	PositionMs int       // для progress: позиция воспроизведения от начала видео
+
+	// Антифрод: IP и User-Agent запроса не сохраняются, Flag пишется в events
+	IP        string
+	UserAgent string
+	Flag      EventFlag // пусто — событие учитывается
 }
 
 func (e *Event) Validate() error {
-	if e.EventID == uuid.Nil || e.SessionID == "" || e.Type == "" || e.TS.IsZero() {
+	if e.EventID == uuid.Nil || e.SessionID == "" || e.Type == "" || e.TS.IsZero() || e.DwellMs < 0 {
 		return ErrInvalidEvent
 	}
 	switch e.Type {
@@ -47,6 +62,19 @@ func (e *Event) Validate() error {
 		if e.VideoID.String() == "" || e.Query == "" {
 			return errors.New("video_id and query required for click_result")
 		}
+	case EventDislike, EventUnlike, EventHideVideo:
+		if e.VideoID == uuid.Nil {
+			return errors.New("video_id required for dislike/unlike/hide_video")
+		}
+	case EventProgress:
+		if e.VideoID == uuid.Nil || e.PositionMs < 0 {
+			return errors.New("video_id and non-negative position_ms required for progress")
+		}
+	case EventNotInterestedTag:
+		e.Tag = NormalizeTag(e.Tag)
+		if e.Tag == "" {
+			return errors.New("tag required for not_interested_tag")
+		}
 	default:
 		return ErrInvalidEvent
 	}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// EventPartition помесячная партиция app.events: события за [From, To)
+type EventPartition struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+// EventPartitionLayout имя партиции как макет time.Parse; партиции создаёт ensure_event_partitions
+const EventPartitionLayout = "events_2006_01"
+
+// ParseEventPartition разбирает имя партиции; ok == false для events_default и чужих таблиц
+func ParseEventPartition(name string) (EventPartition, bool) {
+	from, err := time.Parse(EventPartitionLayout, name)
+	if err != nil {
+		return EventPartition{}, false
+	}
+	return EventPartition{Name: name, From: from, To: from.AddDate(0, 1, 0)}, true
+}
+
+// EventsRetentionCutoff начало хранимых событий при сроке хранения months полных месяцев
+// помимо текущего; партиции, закончившиеся до него, удаляются. months <= 0 — храним всё.
+func EventsRetentionCutoff(now time.Time, months int) time.Time {
+	if months <= 0 {
+		return time.Time{}
+	}
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -months, 0)
+}
+
+var ErrEventTooOld = errors.New("event is older than the events retention period")
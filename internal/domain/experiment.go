@@ -0,0 +1,215 @@
+package domain
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExperimentSurface выдача, на которой идёт эксперимент
+type ExperimentSurface string
+
+const (
+	SurfaceFeed            ExperimentSurface = "feed"
+	SurfaceRecommendations ExperimentSurface = "recommendations"
+	SurfaceSearch          ExperimentSurface = "search"
+)
+
+func (s ExperimentSurface) Valid() bool {
+	switch s {
+	case SurfaceFeed, SurfaceRecommendations, SurfaceSearch:
+		return true
+	}
+	return false
+}
+
+// ExperimentStatus состояние эксперимента: draft → running → stopped
+type ExperimentStatus string
+
+const (
+	ExperimentDraft   ExperimentStatus = "draft"
+	ExperimentRunning ExperimentStatus = "running"
+	ExperimentStopped ExperimentStatus = "stopped"
+)
+
+// Параметры вариантов, которые понимают поверхности
+const (
+	ParamPersonalSources = "personal_sources" // recommendations: источники в формате RECS_PERSONAL_SOURCES
+	ParamColdSources     = "cold_sources"     // recommendations: источники в формате RECS_COLD_SOURCES
+	ParamDiversity       = "diversity"        // recommendations: вес разнообразия MMR по умолчанию
+	ParamPersonalize     = "personalize"      // feed: "false" — без учёта скрытых и просмотренных видео
+	ParamTrendingWindow  = "trending_window"  // feed: окно trending по умолчанию
+	ParamSynonyms        = "synonyms"         // search: "false" — без расширения синонимами
+)
+
+const MaxExperimentVariants = 10
+
+var experimentKeyRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// Variant вариант эксперимента: доля трафика Weight и параметры выдачи
+type Variant struct {
+	Name   string
+	Weight int
+	Params map[string]string
+}
+
+// Experiment эксперимент над одной поверхностью
+type Experiment struct {
+	ID          uuid.UUID
+	Key         string
+	Description string
+	Surface     ExperimentSurface
+	Status      ExperimentStatus
+	Variants    []Variant
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	StartedAt   *time.Time
+	StoppedAt   *time.Time
+}
+
+// Validate проверяет описание эксперимента перед созданием
+func (e *Experiment) Validate() error {
+	if !experimentKeyRe.MatchString(e.Key) || !e.Surface.Valid() {
+		return ErrInvalidExperiment
+	}
+	if len(e.Variants) < 2 || len(e.Variants) > MaxExperimentVariants {
+		return ErrInvalidExperiment
+	}
+	names := make(map[string]bool, len(e.Variants))
+	for _, v := range e.Variants {
+		if v.Name == "" || v.Weight <= 0 || names[v.Name] {
+			return ErrInvalidExperiment
+		}
+		names[v.Name] = true
+	}
+	return nil
+}
+
+// Assign детерминированно выбирает вариант для единицы трафика:
+// одна и та же единица в одном эксперименте всегда получает один вариант
+func (e *Experiment) Assign(unitID string) Variant {
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(e.Key + ":" + unitID))
+	bucket := int(h.Sum64() % uint64(total))
+	for _, v := range e.Variants {
+		if bucket < v.Weight {
+			return v
+		}
+		bucket -= v.Weight
+	}
+	return e.Variants[len(e.Variants)-1]
+}
+
+// ExperimentUnitID единица рандомизации: пользователь, а для гостя — сессия.
+// Пустая строка — назначить вариант некому.
+func ExperimentUnitID(userID uuid.UUID, sessionID string) string {
+	if userID != uuid.Nil {
+		return "u:" + userID.String()
+	}
+	if sessionID != "" {
+		return "s:" + sessionID
+	}
+	return ""
+}
+
+// Assignment назначенный вариант эксперимента
+type Assignment struct {
+	ExperimentID uuid.UUID
+	Key          string
+	Variant      string
+	Params       map[string]string
+}
+
+// Exposure факт выдачи варианта единице трафика
+type Exposure struct {
+	ExperimentID uuid.UUID
+	UnitID       string
+	Variant      string
+	UserID       *uuid.UUID
+	SessionID    *string
+}
+
+// VariantCounts сырые счётчики событий единиц варианта после первой выдачи
+type VariantCounts struct {
+	Variant     string
+	Units       int64   // единиц с выдачей варианта
+	ClickUnits  int64   // из них с хотя бы одним просмотром или кликом
+	Starts      int64   // начатых просмотров
+	Completes   int64   // досмотров
+	DwellN      int64   // событий с dwell_ms
+	DwellSum    float64 // сумма dwell_ms
+	DwellSqrSum float64 // сумма квадратов dwell_ms
+}
+
+// Metric оценка метрики с 95% доверительным интервалом
+type Metric struct {
+	Value float64
+	Low   float64
+	High  float64
+	N     int64 // объём выборки
+}
+
+// VariantResult метрики варианта
+type VariantResult struct {
+	Variant        string
+	Units          int64
+	CTR            Metric // доля единиц с просмотром или кликом
+	CompletionRate Metric // досмотры / начатые просмотры
+	DwellMs        Metric // среднее время просмотра
+}
+
+// ExperimentResults результаты эксперимента по вариантам
+type ExperimentResults struct {
+	Experiment Experiment
+	Variants   []VariantResult
+}
+
+// z95 квантиль нормального распределения для 95% интервала
+const z95 = 1.96
+
+// WilsonInterval доля successes/n с интервалом Уилсона
+func WilsonInterval(successes, n int64) Metric {
+	if n == 0 {
+		return Metric{}
+	}
+	p := float64(successes) / float64(n)
+	if p > 1 {
+		p = 1
+	}
+	nf := float64(n)
+	denom := 1 + z95*z95/nf
+	center := (p + z95*z95/(2*nf)) / denom
+	margin := z95 * math.Sqrt(p*(1-p)/nf+z95*z95/(4*nf*nf)) / denom
+	return Metric{Value: p, Low: math.Max(0, center-margin), High: math.Min(1, center+margin), N: n}
+}
+
+// MeanInterval среднее с нормальным интервалом по сумме и сумме квадратов
+func MeanInterval(n int64, sum, sqrSum float64) Metric {
+	if n == 0 {
+		return Metric{}
+	}
+	nf := float64(n)
+	mean := sum / nf
+	var margin float64
+	if n > 1 {
+		variance := math.Max(0, (sqrSum-nf*mean*mean)/(nf-1))
+		margin = z95 * math.Sqrt(variance/nf)
+	}
+	return Metric{Value: mean, Low: mean - margin, High: mean + margin, N: n}
+}
+
+var (
+	ErrInvalidExperiment  = errors.New("experiment needs a key, a valid surface and 2-10 uniquely named variants with positive weights")
+	ErrExperimentNotFound = errors.New("experiment not found")
+	ErrExperimentExists   = errors.New("experiment with this key already exists")
+	ErrExperimentConflict = errors.New("another experiment is already running on this surface")
+	ErrExperimentState    = errors.New("experiment cannot change to this status")
+)
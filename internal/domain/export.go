@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ExportFormat формат выгрузки
+type ExportFormat string
+
+const (
+	ExportCSV     ExportFormat = "csv"
+	ExportNDJSON  ExportFormat = "ndjson"
+	ExportParquet ExportFormat = "parquet"
+)
+
+func (f ExportFormat) Valid() bool {
+	switch f {
+	case ExportCSV, ExportNDJSON, ExportParquet:
+		return true
+	}
+	return false
+}
+
+// ExportDataset что выгружается
+type ExportDataset string
+
+const (
+	ExportVideoDaily ExportDataset = "video_daily" // суточная аналитика видео
+	ExportTimeseries ExportDataset = "timeseries"  // ряды /stats/timeseries, строка — корзина значения разбивки
+	ExportEvents     ExportDataset = "events"      // сырые события app.events
+)
+
+// ExportColumnType тип колонки выгрузки; nil-значение в любой колонке — NULL
+type ExportColumnType int
+
+const (
+	ExportString ExportColumnType = iota // string
+	ExportInt                            // int64
+	ExportFloat                          // float64
+	ExportTime                           // time.Time
+)
+
+// ExportColumn колонка выгрузки
+type ExportColumn struct {
+	Name string
+	Type ExportColumnType
+}
+
+// MaxExportDays максимальный период выгрузки
+const MaxExportDays = 366
+
+var exportColumns = map[ExportDataset][]ExportColumn{
+	ExportVideoDaily: {
+		{"day", ExportTime}, {"video_id", ExportString},
+		{"views", ExportInt}, {"completes", ExportInt}, {"likes", ExportInt}, {"clicks", ExportInt},
+		{"impressions", ExportInt}, {"dwell_ms_sum", ExportInt},
+		{"dislikes", ExportInt}, {"unlikes", ExportInt}, {"hides", ExportInt},
+	},
+	ExportTimeseries: {
+		{"ts", ExportTime}, {"key", ExportString},
+		{"views", ExportInt}, {"completes", ExportInt}, {"likes", ExportInt}, {"clicks", ExportInt},
+		{"impressions", ExportInt}, {"dwell", ExportInt},
+	},
+	ExportEvents: {
+		{"event_id", ExportString}, {"ts", ExportTime}, {"type", ExportString}, {"session_id", ExportString},
+		{"user_id", ExportString}, {"video_id", ExportString}, {"query", ExportString},
+		{"dwell_ms", ExportInt}, {"tag", ExportString}, {"serve_id", ExportString}, {"position_ms", ExportInt},
+		{"flag", ExportString},
+	},
+}
+
+// Columns все колонки набора в порядке выгрузки; nil для неизвестного набора
+func (d ExportDataset) Columns() []ExportColumn {
+	return exportColumns[d]
+}
+
+// ExportParams параметры выгрузки за дни [From, To] (UTC, включительно)
+type ExportParams struct {
+	Dataset ExportDataset
+	Format  ExportFormat
+	From    time.Time
+	To      time.Time
+	Columns []string // пусто — все колонки набора
+	Gzip    bool
+
+	// Для timeseries: гранулярность, разбивка и число значений разбивки; период и метрики
+	// берутся из From/To и выбранных колонок
+	Timeseries TimeseriesParams
+}
+
+// Validate проверяет набор, формат, период и колонки; приводит период к дням
+func (p *ExportParams) Validate() error {
+	all := p.Dataset.Columns()
+	if all == nil {
+		return ErrUnknownExportDataset
+	}
+	if !p.Format.Valid() {
+		return ErrInvalidExport
+	}
+	p.From = p.From.UTC().Truncate(24 * time.Hour)
+	p.To = p.To.UTC().Truncate(24 * time.Hour)
+	if p.To.Before(p.From) || p.To.Sub(p.From) >= MaxExportDays*24*time.Hour {
+		return ErrInvalidExport
+	}
+	for _, c := range p.Columns {
+		if !slices.ContainsFunc(all, func(col ExportColumn) bool { return col.Name == c }) {
+			return ErrInvalidExport
+		}
+	}
+
+	if p.Dataset == ExportTimeseries {
+		p.Timeseries.From = p.From
+		p.Timeseries.To = p.To.AddDate(0, 0, 1)
+		p.Timeseries.Metrics = nil
+		for _, c := range p.SelectedColumns() {
+			if m := StatsMetric(c.Name); m.Valid() {
+				p.Timeseries.Metrics = append(p.Timeseries.Metrics, m)
+			}
+		}
+		if len(p.Timeseries.Metrics) == 0 {
+			p.Timeseries.Metrics = []StatsMetric{MetricViews}
+		}
+		if err := p.Timeseries.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SelectedColumns выбранные колонки в порядке запроса
+func (p ExportParams) SelectedColumns() []ExportColumn {
+	all := p.Dataset.Columns()
+	if len(p.Columns) == 0 {
+		return all
+	}
+	res := make([]ExportColumn, 0, len(p.Columns))
+	for _, name := range p.Columns {
+		for _, c := range all {
+			if c.Name == name {
+				res = append(res, c)
+			}
+		}
+	}
+	return res
+}
+
+// ParseExportColumns список колонок через запятую
+func ParseExportColumns(s string) []string {
+	var res []string
+	for _, c := range strings.Split(s, ",") {
+		if c = strings.TrimSpace(c); c != "" && !slices.Contains(res, c) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+var (
+	ErrUnknownExportDataset = errors.New("unknown export dataset")
+	ErrInvalidExport        = errors.New("invalid export: unknown format or column, or period longer than 366 days")
+)
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Interaction агрегированный неявный сигнал пользователя по видео для коллаборативной фильтрации
+type Interaction struct {
+	UserID  uuid.UUID
+	VideoID uuid.UUID
+	Weight  float64 // просмотр 1, досмотр 2, лайк 3; дизлайкнутые и скрытые видео не учитываются
+}
+
+// FactorModel метаданные обученной ALS-модели
+type FactorModel struct {
+	Version   int64
+	Factors   int
+	Lambda    float64
+	Alpha     float64
+	Users     int
+	Videos    int
+	TrainedAt time.Time
+}
@@ -1,30 +1,84 @@
 package domain
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 // FeedType тип фида видео
 type FeedType string
 
 const (
 	FeedTypePopular   FeedType = "popular"   // популярное видео на основе просмотров и лайков с затуханием по времени
-	FeedTypeCommented FeedType = "commented" // прокси по лайкам и завершениям
+	FeedTypeCommented FeedType = "commented" // по активности комментариев за последнюю неделю
 	FeedTypeRandom    FeedType = "random"    // случайная выборка
+	FeedTypeTrending  FeedType = "trending"  // скорость набора активности за скользящие окна с экспоненциальным затуханием
+
+	FeedTypeSubscriptions FeedType = "subscriptions" // новые видео авторов, на которых подписан пользователь
+)
+
+// TrendingWindow окно, по которому считается скорость для trending-фида
+type TrendingWindow string
+
+const (
+	TrendingWindowBlended TrendingWindow = ""     // взвешенная смесь всех окон
+	TrendingWindowHour    TrendingWindow = "hour" // последний час
+	TrendingWindowDay     TrendingWindow = "day"  // последние сутки
+	TrendingWindowWeek    TrendingWindow = "week" // последняя неделя
 )
 
+// TrendingWindows все окна, для которых предрасчитывается trending
+var TrendingWindows = []TrendingWindow{
+	TrendingWindowBlended, TrendingWindowHour, TrendingWindowDay, TrendingWindowWeek,
+}
+
+// TrendingCounters активность видео по окнам для расчёта trending-скора.
+// Активность взвешена: просмотр 1, досмотр 1.5, лайк 2.
+type TrendingCounters struct {
+	VideoID    uuid.UUID
+	UploadedAt time.Time
+	Hour       float64 // активность за последний час
+	Day        float64 // активность за последние 24 часа
+	Week       float64 // активность за 7 дней с экспоненциальным затуханием по дням
+	WeekRaw    int64   // невзвешенное число событий за 7 дней (для порога минимального трафика)
+}
+
 // FeedParams параметры для получения фида
 type FeedParams struct {
-	Type  FeedType
-	Limit int
+	Type   FeedType
+	Limit  int
+	Window TrendingWindow // только для trending
+	UserID uuid.UUID      // авторизованный пользователь (обязателен для subscriptions)
+	Cursor *Cursor        // позиция пагинации (только для subscriptions)
+
+	SessionID string // сессия гостя, для назначения варианта эксперимента
 }
 
 // Validate проверяет корректность параметров фида
 func (fp *FeedParams) Validate() error {
 	// Если тип не указан или неверный, используем popular по умолчанию
 	switch fp.Type {
-	case FeedTypePopular, FeedTypeCommented, FeedTypeRandom:
+	case FeedTypePopular, FeedTypeCommented, FeedTypeRandom, FeedTypeTrending, FeedTypeSubscriptions:
 		// тип корректен
 	default:
 		fp.Type = FeedTypePopular // используем popular по умолчанию
 	}
 
+	switch fp.Window {
+	case TrendingWindowBlended, TrendingWindowHour, TrendingWindowDay, TrendingWindowWeek:
+		// окно корректно
+	default:
+		fp.Window = TrendingWindowBlended
+	}
+	if fp.Type != FeedTypeTrending {
+		fp.Window = TrendingWindowBlended
+	}
+
+	if fp.Type == FeedTypeSubscriptions && fp.UserID == uuid.Nil {
+		return ErrAuthRequired
+	}
+
 	if fp.Limit <= 0 {
 		fp.Limit = 20 // значение по умолчанию
 	}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DislikeTagWeight       = 0.5 // вклад дизлайка видео в неприязнь к каждому его тегу
+	NotInterestedTagWeight = 1.0 // вклад явного "не интересно" к тегу
+	DislikedTagPenalty     = 0.1 // снижение score за единицу неприязни к тегу
+	MaxDislikedTagPenalty  = 0.5 // потолок снижения score одного видео
+)
+
+// NegativeFeedback негативные сигналы пользователя для фильтрации и понижения выдачи
+type NegativeFeedback struct {
+	Hidden     map[uuid.UUID]bool // скрытые видео, не показываются совсем
+	TagWeights map[string]float64 // накопленная неприязнь к тегам
+}
+
+// Empty true, если сигналов нет и выдачу менять не нужно
+func (f NegativeFeedback) Empty() bool {
+	return len(f.Hidden) == 0 && len(f.TagWeights) == 0
+}
+
+// IsHidden скрыто ли видео пользователем
+func (f NegativeFeedback) IsHidden(videoID uuid.UUID) bool {
+	return f.Hidden[videoID]
+}
+
+// Penalty снижение score видео с тегами tags, не больше MaxDislikedTagPenalty
+func (f NegativeFeedback) Penalty(tags []string) float64 {
+	var weight float64
+	for _, tag := range tags {
+		weight += f.TagWeights[NormalizeTag(tag)]
+	}
+	penalty := weight * DislikedTagPenalty
+	if penalty > MaxDislikedTagPenalty {
+		penalty = MaxDislikedTagPenalty
+	}
+	return penalty
+}
+
+// NormalizeTag приводит тег к виду, в котором он хранится у видео
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
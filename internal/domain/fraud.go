@@ -0,0 +1,22 @@
+package domain
+
+import "errors"
+
+// EventFlag почему событие признано ботом или накруткой. Помеченное событие сохраняется
+// в events, но не учитывается в счётчиках, статистике и обучении рекомендаций; история
+// просмотров и скрытия пользователя по нему обновляются как обычно.
+type EventFlag string
+
+const (
+	FlagBotUserAgent EventFlag = "bot_user_agent" // User-Agent из FRAUD_BOT_USER_AGENTS
+	FlagBlockedIP    EventFlag = "blocked_ip"     // IP из FRAUD_BLOCKED_IPS
+	FlagSessionRate  EventFlag = "session_rate"   // сверх лимита на сессию и видео
+	FlagIPRate       EventFlag = "ip_rate"        // сверх лимита на IP и видео
+)
+
+// RateLimitedEvent учитывается ли тип в лимитах на сессию и IP: накручивают просмотры и лайки
+func RateLimitedEvent(t EventType) bool {
+	return t == EventViewStart || t == EventLike
+}
+
+var ErrDwellTooLong = errors.New("dwell_ms exceeds video duration")
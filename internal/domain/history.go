@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResumeDoneShare доля длительности, после которой видео считается досмотренным для continue-watching
+const ResumeDoneShare = 0.95
+
+// HistoryItem запись истории просмотров пользователя (одна на видео)
+type HistoryItem struct {
+	Video          Video
+	LastWatchedAt  time.Time
+	WatchedMs      int64
+	Completed      bool
+	ResumePosition int     // позиция продолжения просмотра, секунды
+	Progress       float64 // доля просмотренного от DurationS, 0..1
+}
+
+// FillResume вычисляет позицию продолжения и прогресс по просмотренному времени и длительности видео
+func (h *HistoryItem) FillResume() {
+	if h.Video.DurationS <= 0 {
+		return
+	}
+	pos := int(h.WatchedMs / 1000)
+	if pos > h.Video.DurationS {
+		pos = h.Video.DurationS
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	h.ResumePosition = pos
+	h.Progress = float64(pos) / float64(h.Video.DurationS)
+	if h.Completed {
+		h.Progress = 1
+	}
+}
+
+// HistoryParams параметры получения истории
+type HistoryParams struct {
+	UserID uuid.UUID
+	Cursor *Cursor
+	Limit  int
+}
+
+// Validate проверяет корректность параметров
+func (p *HistoryParams) Validate() error {
+	if p.UserID == uuid.Nil {
+		return ErrAuthRequired
+	}
+	if p.Limit <= 0 {
+		p.Limit = 20 // значение по умолчанию
+	}
+	if p.Limit > 100 { // ограничиваем максимальный размер выборки
+		p.Limit = 100
+	}
+	return nil
+}
+
+// HistoryPage страница истории
+type HistoryPage struct {
+	Items      []HistoryItem
+	NextCursor string
+}
+
+// HistorySettings настройки истории пользователя
+type HistorySettings struct {
+	Paused bool // просмотры не записываются в историю и не влияют на персонализацию
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Serve выдача одного запроса к /search, /videos/feed или /recommendations.
+// ID отдаётся клиенту как serve_id и возвращается им в событиях click_result и view_start.
+type Serve struct {
+	ID        uuid.UUID
+	TS        time.Time
+	Surface   ExperimentSurface
+	UserID    uuid.UUID // uuid.Nil для гостя
+	SessionID string
+	Query     string // поисковый запрос (только search)
+	Items     []Impression
+}
+
+// Impression показ видео в выдаче
+type Impression struct {
+	VideoID  uuid.UUID
+	Position int    // с 1, с учётом offset
+	Source   string // источник кандидата: тип фида, search или генератор рекомендаций
+	Reason   string // причина рекомендации, если есть
+}
+
+// PositionCTR кликабельность позиции выдачи
+type PositionCTR struct {
+	Position    int
+	Impressions int64
+	Clicks      int64
+	CTR         float64
+}
+
+// MaxStatsPosition сколько первых позиций выдачи считает статистика по позициям
+const MaxStatsPosition = 50
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// LiveRateWindow за сколько последних завершённых секунд усредняется поток событий
+	LiveRateWindow = 5
+	// LiveTopVideos сколько видео в live-топе
+	LiveTopVideos = 10
+)
+
+// LiveView начатый просмотр (view_start) в дельте live-статистики
+type LiveView struct {
+	SessionID string
+	VideoID   uuid.UUID
+}
+
+// LiveDelta события, принятые одной репликой API за секунду TS; рассылается всем репликам
+type LiveDelta struct {
+	Replica string
+	TS      time.Time // начало секунды
+	ByType  map[EventType]int64
+	Views   []LiveView
+}
+
+// LiveVideo видео live-топа: начатые просмотры за окно зрителей
+type LiveVideo struct {
+	VideoID uuid.UUID
+	Title   string
+	Views   int64
+}
+
+// LiveSnapshot live-статистика по всем репликам на момент TS
+type LiveSnapshot struct {
+	TS           time.Time
+	EventsPerSec float64             // среднее за LiveRateWindow секунд
+	ByType       map[EventType]int64 // события по типам за те же секунды
+	Viewers      int                 // сессии с view_start за окно зрителей
+	TopVideos    []LiveVideo
+}
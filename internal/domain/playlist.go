@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxPlaylistItems       = 500 // максимальное число видео в плейлисте
+	MaxPlaylistTitleLength = 150
+	WatchLaterTitle        = "Watch later"
+)
+
+// PlaylistVisibility видимость плейлиста
+type PlaylistVisibility string
+
+const (
+	PlaylistPublic   PlaylistVisibility = "public"   // виден всем и в списке плейлистов автора
+	PlaylistUnlisted PlaylistVisibility = "unlisted" // доступен по ссылке, но не в списке
+	PlaylistPrivate  PlaylistVisibility = "private"  // только владельцу
+)
+
+// PlaylistKind тип плейлиста
+type PlaylistKind string
+
+const (
+	PlaylistKindUser       PlaylistKind = "user"        // обычный пользовательский плейлист
+	PlaylistKindWatchLater PlaylistKind = "watch_later" // встроенный "смотреть позже", по одному на пользователя
+)
+
+// Playlist плейлист пользователя
+type Playlist struct {
+	ID         uuid.UUID
+	OwnerID    uuid.UUID
+	Title      string
+	Visibility PlaylistVisibility
+	Kind       PlaylistKind
+	ItemsCount int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// CanView может ли пользователь (uuid.Nil — гость) открыть плейлист
+func (p *Playlist) CanView(viewerID uuid.UUID) bool {
+	return p.Visibility != PlaylistPrivate || p.OwnerID == viewerID
+}
+
+// PlaylistItem видео в плейлисте; позиции начинаются с 0
+type PlaylistItem struct {
+	Video    Video
+	Position int
+	AddedAt  time.Time
+}
+
+// PlaylistWithItems плейлист вместе с видео по порядку
+type PlaylistWithItems struct {
+	Playlist
+	Items []PlaylistItem
+}
+
+// PlaylistUpdate изменения плейлиста; nil — поле не меняется
+type PlaylistUpdate struct {
+	Title      *string
+	Visibility *PlaylistVisibility
+}
+
+// NormalizePlaylistTitle обрезает пробелы и проверяет длину названия
+func NormalizePlaylistTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || len([]rune(title)) > MaxPlaylistTitleLength {
+		return "", ErrInvalidPlaylist
+	}
+	return title, nil
+}
+
+// Valid проверяет значение видимости
+func (v PlaylistVisibility) Valid() bool {
+	switch v {
+	case PlaylistPublic, PlaylistUnlisted, PlaylistPrivate:
+		return true
+	}
+	return false
+}
+
+var (
+	ErrInvalidPlaylist      = errors.New("playlist title must be 1..150 characters and visibility one of public, unlisted, private")
+	ErrPlaylistNotFound     = errors.New("playlist not found")
+	ErrPlaylistForbidden    = errors.New("only the owner can modify the playlist")
+	ErrPlaylistFull         = errors.New("playlist is full")
+	ErrPlaylistItemNotFound = errors.New("video is not in the playlist")
+	ErrPlaylistItemExists   = errors.New("video is already in the playlist")
+	ErrWatchLaterReadOnly   = errors.New("watch later list cannot be renamed or deleted")
+)
@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+const (
+	// MaxProductStatsDays максимальный период отчётов по активным пользователям и воронке
+	MaxProductStatsDays = 365
+	// DefaultCohortWeeks и MaxCohortWeeks сколько недельных когорт отдавать
+	DefaultCohortWeeks = 12
+	MaxCohortWeeks     = 52
+)
+
+// ActiveUsers активные пользователи и анонимные сессии на день Day.
+// WAU и MAU — уникальные за 7 и 30 дней, заканчивающихся Day.
+type ActiveUsers struct {
+	Day        time.Time
+	DAU        int64
+	WAU        int64
+	MAU        int64
+	Stickiness float64 // DAU / MAU
+}
+
+// CohortCell пользователи когорты Cohort (неделя регистрации), активные в неделю Week.
+// Для размеров когорт Week == Cohort, а Users — все зарегистрированные.
+type CohortCell struct {
+	Cohort time.Time
+	Week   time.Time
+	Users  int64
+}
+
+// Cohort удержание недельной когорты: Active[n] и Retention[n] — через n недель после регистрации.
+// Длина рядов — число прошедших недель, включая текущую.
+type Cohort struct {
+	Week      time.Time
+	Users     int64
+	Active    []int64
+	Retention []float64
+}
+
+// FunnelCounts сессии, дошедшие до каждого шага воронки поиска
+type FunnelCounts struct {
+	Searched  int64
+	Clicked   int64
+	Viewed    int64
+	Completed int64
+}
+
+// Шаги воронки поиска
+const (
+	FunnelSearch       = "search"
+	FunnelClick        = "click"
+	FunnelViewStart    = "view_start"
+	FunnelViewComplete = "view_complete"
+)
+
+// FunnelStep шаг воронки: Conversion — доля от предыдущего шага, Overall — от первого
+type FunnelStep struct {
+	Step       string
+	Sessions   int64
+	Conversion float64
+	Overall    float64
+}
+
+// Funnel воронка поиска за дни [From, To]
+type Funnel struct {
+	From  time.Time
+	To    time.Time
+	Steps []FunnelStep
+}
+
+// StartOfWeek понедельник недели t (UTC), как date_trunc('week') в Postgres
+func StartOfWeek(t time.Time) time.Time {
+	d := t.UTC().Truncate(24 * time.Hour)
+	return d.AddDate(0, 0, -(int(d.Weekday())+6)%7)
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
 
 // RecommendationType тип рекомендации
 type RecommendationType string
@@ -13,9 +17,28 @@ const (
 
 // RecommendationParams параметры для получения рекомендаций
 type RecommendationParams struct {
-	UserID    *string // идентификатор пользователя (для авторизованных)
-	SessionID *string // идентификатор сессии (для гостей)
-	Limit     int     // количество рекомендаций
+	UserID    *string  // идентификатор пользователя (для авторизованных)
+	SessionID *string  // идентификатор сессии (для гостей)
+	Limit     int      // количество рекомендаций
+	Diversity *float64 // вес разнообразия в MMR от 0 до 1; nil — значение по умолчанию
+
+	PlaylistID *uuid.UUID // плейлист, который сейчас проигрывается: в начало выдачи попадут следующие видео
+	VideoID    *uuid.UUID // текущее видео плейлиста; без него подсказки начинаются с начала плейлиста
+	ViewerID   uuid.UUID  // пользователь из JWT: доступ к приватному плейлисту и единица трафика экспериментов
+
+	Debug bool // добавить в объяснения слагаемые score (только для админов)
+	Admin bool // запрос от админа: объяснения по чужому user_id не скрываются
+}
+
+// IsViewer запрошены рекомендации для самого пользователя из JWT. user_id в запросе
+// может подставить кто угодно, поэтому seen-set, подписки и скрытые видео
+// используются только в этом случае.
+func (rp *RecommendationParams) IsViewer() bool {
+	if rp.UserID == nil || rp.ViewerID == uuid.Nil {
+		return false
+	}
+	uid, err := uuid.Parse(*rp.UserID)
+	return err == nil && uid == rp.ViewerID
 }
 
 // Validate проверяет корректность параметров рекомендаций
@@ -31,6 +54,9 @@ func (rp *RecommendationParams) Validate() error {
 	if rp.Limit > 100 { // ограничиваем максимальный размер выборки
 		rp.Limit = 100
 	}
+	if rp.Diversity != nil && (*rp.Diversity < 0 || *rp.Diversity > 1) {
+		return ErrInvalidDiversity
+	}
 	return nil
 }
 
@@ -38,18 +64,73 @@ func (rp *RecommendationParams) Validate() error {
 type RecommendationReason string
 
 const (
-	ReasonPopular     RecommendationReason = "popular"     // популярное видео
-	ReasonUserTags    RecommendationReason = "user_tags"   // по тегам пользователя
-	ReasonSimilar     RecommendationReason = "similar"     // похожее на просмотренное
-	ReasonDiversify   RecommendationReason = "diversify"   // для диверсификации
-	ReasonExploration RecommendationReason = "exploration" // случайное исследование
+	ReasonPopular       RecommendationReason = "popular"       // популярное видео
+	ReasonUserTags      RecommendationReason = "user_tags"     // по тегам пользователя
+	ReasonSimilar       RecommendationReason = "similar"       // похожее на просмотренное
+	ReasonDiversify     RecommendationReason = "diversify"     // для диверсификации
+	ReasonExploration   RecommendationReason = "exploration"   // исследование свежих видео (бандит)
+	ReasonPlaylistNext  RecommendationReason = "playlist_next" // следующее видео проигрываемого плейлиста
+	ReasonCollaborative RecommendationReason = "collaborative" // похожим пользователям понравилось (ALS)
 )
 
 // RecommendationResult результат рекомендации с объяснением
 type RecommendationResult struct {
-	Video  Video
-	Reason RecommendationReason
-	Score  float64 // релевантность рекомендации
+	Video       Video
+	Reason      RecommendationReason
+	Score       float64 // релевантность рекомендации
+	Explanation Explanation
 }
 
-var ErrInvalidRecommendationParams = errors.New("user_id or session_id must be specified")
+// Explanation структурированное объяснение, почему видео попало в выдачу
+type Explanation struct {
+	Source         string          // источник кандидатов из конфигурации конвейера
+	MatchedTags    []TagWeight     // теги видео, совпавшие с интересами пользователя, и их вес
+	SimilarTo      *uuid.UUID      // просмотренное видео, вместе с которым смотрят это
+	FollowedAuthor *uuid.UUID      // автор из подписок пользователя
+	Scores         *ScoreBreakdown // слагаемые score, только в режиме отладки
+}
+
+// ScoreBreakdown слагаемые итогового score рекомендации
+type ScoreBreakdown struct {
+	SourceWeight  float64 // вес источника
+	PositionDecay float64 // множитель затухания по позиции внутри источника
+	FollowedBoost float64 // бонус за автора из подписок
+	TagPenalty    float64 // штраф за нелюбимые теги
+	BanditSample  float64 // выборка CTR бандита (только exploration): задаёт состав и порядок слота
+	Redundancy    float64 // штраф MMR за сходство с видео выше в выдаче (на порядок, не на score)
+	Final         float64 // итоговый score
+}
+
+// TagWeight тег с весом интереса пользователя или сессии
+type TagWeight struct {
+	Tag    string
+	Weight float64
+}
+
+// TagNames возвращает только названия тегов
+func TagNames(tags []TagWeight) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Tag)
+	}
+	return names
+}
+
+// MatchTags возвращает теги видео, которые есть среди interests, с их весами
+func MatchTags(videoTags []string, interests []TagWeight) []TagWeight {
+	var matched []TagWeight
+	for _, t := range interests {
+		for _, vt := range videoTags {
+			if NormalizeTag(vt) == NormalizeTag(t.Tag) {
+				matched = append(matched, t)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+var (
+	ErrInvalidRecommendationParams = errors.New("user_id or session_id must be specified")
+	ErrInvalidDiversity            = errors.New("diversity must be between 0 and 1")
+)
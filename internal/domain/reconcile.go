@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CounterTable денормализованная таблица счётчиков, сверяемая с сырыми событиями
+type CounterTable string
+
+const (
+	CounterVideoCounters CounterTable = "video_counters" // счётчики за всё время
+	CounterVideoDaily    CounterTable = "video_daily"    // по дням (UTC)
+	CounterVideoHourly   CounterTable = "video_hourly"   // по часам
+)
+
+const (
+	MaxReconcileDays   = 366  // максимальный период сверки
+	MaxReconcileVideos = 1000 // максимум явно заданных видео
+	MaxReconcileItems  = 500  // сколько расхождений попадает в отчёт целиком
+)
+
+// ReconcileParams что сверять. Дни From..To (включительно) пересчитываются в video_daily
+// и video_hourly; video_counters пересчитываются за всё время для VideoIDs,
+// а если они не заданы — для видео с событиями за период.
+type ReconcileParams struct {
+	VideoIDs []uuid.UUID // пусто — все видео
+	From     time.Time
+	To       time.Time
+	Tables   []CounterTable // пусто — все таблицы
+	Repair   bool           // исправить расхождения, иначе только отчёт
+}
+
+// HasTable сверяется ли таблица
+func (p ReconcileParams) HasTable(t CounterTable) bool {
+	return len(p.Tables) == 0 || slices.Contains(p.Tables, t)
+}
+
+func (p ReconcileParams) Validate() error {
+	if p.From.IsZero() || p.To.IsZero() || p.To.Before(p.From) ||
+		p.To.Sub(p.From) >= MaxReconcileDays*24*time.Hour {
+		return ErrInvalidReconcile
+	}
+	if len(p.VideoIDs) > MaxReconcileVideos {
+		return ErrInvalidReconcile
+	}
+	for _, t := range p.Tables {
+		switch t {
+		case CounterVideoCounters, CounterVideoDaily, CounterVideoHourly:
+		default:
+			return ErrInvalidReconcile
+		}
+	}
+	return nil
+}
+
+// CounterValues значения счётчиков строки; колонки, которых нет в таблице, равны нулю
+type CounterValues struct {
+	Views       int64
+	Completes   int64
+	Likes       int64
+	Clicks      int64
+	Impressions int64
+	DwellMsSum  int64
+	Dislikes    int64
+	Unlikes     int64
+	Hides       int64
+}
+
+func (v CounterValues) Sub(o CounterValues) CounterValues {
+	return CounterValues{
+		Views:       v.Views - o.Views,
+		Completes:   v.Completes - o.Completes,
+		Likes:       v.Likes - o.Likes,
+		Clicks:      v.Clicks - o.Clicks,
+		Impressions: v.Impressions - o.Impressions,
+		DwellMsSum:  v.DwellMsSum - o.DwellMsSum,
+		Dislikes:    v.Dislikes - o.Dislikes,
+		Unlikes:     v.Unlikes - o.Unlikes,
+		Hides:       v.Hides - o.Hides,
+	}
+}
+
+// CounterRow строка таблицы счётчиков; Bucket — день или час, для video_counters не заполнен
+type CounterRow struct {
+	VideoID uuid.UUID
+	Bucket  time.Time
+	Values  CounterValues
+}
+
+// CounterDiff расхождение строки: Delta = Expected - Actual прибавляется к таблице при исправлении
+type CounterDiff struct {
+	Table    CounterTable
+	VideoID  uuid.UUID
+	Bucket   time.Time
+	Expected CounterValues
+	Actual   CounterValues
+	Delta    CounterValues
+}
+
+// ReconcileReport итог сверки
+type ReconcileReport struct {
+	From     time.Time
+	To       time.Time
+	Repair   bool
+	Checked  map[CounterTable]int // сверено строк
+	Diffs    map[CounterTable]int // строк с расхождениями
+	Repaired int                  // исправлено строк
+	Items    []CounterDiff        // первые MaxReconcileItems расхождений
+}
+
+// DiffCounters сравнивает ожидаемые строки (пересчёт из событий) с фактическими
+// и возвращает расхождения и число сверенных строк.
+// Строка, которой нет с одной из сторон, считается нулевой.
+func DiffCounters(table CounterTable, expected, actual []CounterRow) ([]CounterDiff, int) {
+	type key struct {
+		videoID uuid.UUID
+		bucket  int64
+	}
+	keyOf := func(r CounterRow) key { return key{r.VideoID, r.Bucket.Unix()} }
+
+	act := make(map[key]CounterRow, len(actual))
+	for _, r := range actual {
+		act[keyOf(r)] = r
+	}
+	var diffs []CounterDiff
+	add := func(r CounterRow, exp, got CounterValues) {
+		if exp == got {
+			return
+		}
+		diffs = append(diffs, CounterDiff{
+			Table: table, VideoID: r.VideoID, Bucket: r.Bucket,
+			Expected: exp, Actual: got, Delta: exp.Sub(got),
+		})
+	}
+	rows := len(expected)
+	for _, e := range expected {
+		k := keyOf(e)
+		a := act[k]
+		delete(act, k)
+		add(e, e.Values, a.Values)
+	}
+	// Фактические строки без единого события: ожидаются нули
+	for _, a := range actual {
+		if _, ok := act[keyOf(a)]; ok {
+			rows++
+			add(a, CounterValues{}, a.Values)
+		}
+	}
+	return diffs, rows
+}
+
+var (
+	ErrInvalidReconcile = errors.New("invalid reconcile: from must not be after to, at most 366 days and 1000 videos")
+	ErrReconcileRunning = errors.New("reconcile is already running")
+	// события раньше границы удалены по сроку хранения, пересчёт обнулил бы video_daily
+	ErrReconcileRetention = errors.New("period starts before the events or serves retention horizon")
+)
@@ -0,0 +1,46 @@
+package domain
+
+import "github.com/google/uuid"
+
+const (
+	// RetentionBuckets число шагов кривой удержания: точки 0%, 5%, ..., 100% длительности
+	RetentionBuckets = 20
+	// RetentionStepPercent шаг кривой удержания в процентах длительности
+	RetentionStepPercent = 100 / RetentionBuckets
+	// RetentionMinViewers сколько зрителей нужно видео, чтобы войти в среднее по каналу
+	RetentionMinViewers = 10
+)
+
+// RetentionCounts сколько зрителей видео дошли до каждой корзины; Viewers[0] — все начавшие
+type RetentionCounts struct {
+	VideoID uuid.UUID
+	Viewers [RetentionBuckets + 1]int64
+}
+
+// Curve доля зрителей, досмотревших до каждой корзины; nil, если зрителей нет
+func (c RetentionCounts) Curve() []float64 {
+	if c.Viewers[0] == 0 {
+		return nil
+	}
+	res := make([]float64, len(c.Viewers))
+	for i, v := range c.Viewers {
+		res[i] = float64(v) / float64(c.Viewers[0])
+	}
+	return res
+}
+
+// RetentionPoint точка кривой удержания
+type RetentionPoint struct {
+	Percent    int     // позиция в процентах длительности
+	Viewers    int64   // зрителей дошло до позиции
+	Retention  float64 // доля от начавших просмотр
+	ChannelAvg float64 // средняя доля по видео канала
+}
+
+// VideoRetention кривая удержания видео в сравнении со средней по каналу
+type VideoRetention struct {
+	Video         Video
+	Viewers       int64 // начавших просмотр
+	ChannelVideos int   // видео канала в среднем (с RetentionMinViewers зрителей и больше)
+	Points        []RetentionPoint
+}
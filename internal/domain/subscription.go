@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// AuthorProfile публичная информация об авторе для подписчиков
+type AuthorProfile struct {
+	AuthorID  uuid.UUID
+	Followers int64
+	Videos    int64
+	Following bool // подписан ли текущий пользователь
+}
+
+var (
+	ErrAuthRequired     = errors.New("authorization required")
+	ErrAuthorNotFound   = errors.New("author not found")
+	ErrCannotFollowSelf = errors.New("cannot follow yourself")
+)
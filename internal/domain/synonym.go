@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Synonym группа синонимов для расширения поискового запроса.
+// Term и Synonyms равноправны: найденное в запросе слово из группы
+// расширяется всеми остальными словами группы.
+type Synonym struct {
+	ID        uuid.UUID
+	Term      string
+	Synonyms  []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Normalize приводит термины к нижнему регистру и убирает пустые и повторяющиеся значения
+func (s *Synonym) Normalize() {
+	s.Term = NormalizeSearchTerm(s.Term)
+
+	seen := map[string]bool{s.Term: true}
+	synonyms := make([]string, 0, len(s.Synonyms))
+	for _, syn := range s.Synonyms {
+		syn = NormalizeSearchTerm(syn)
+		if syn == "" || seen[syn] {
+			continue
+		}
+		seen[syn] = true
+		synonyms = append(synonyms, syn)
+	}
+	s.Synonyms = synonyms
+}
+
+// Validate проверяет корректность группы синонимов (после Normalize)
+func (s *Synonym) Validate() error {
+	if s.Term == "" || strings.ContainsAny(s.Term, " \t") {
+		return ErrInvalidSynonym
+	}
+	if len(s.Synonyms) == 0 {
+		return ErrInvalidSynonym
+	}
+	return nil
+}
+
+// Group возвращает все слова группы, включая Term
+func (s *Synonym) Group() []string {
+	return append([]string{s.Term}, s.Synonyms...)
+}
+
+// NormalizeSearchTerm приводит слово или фразу к виду, в котором они хранятся в словаре синонимов
+func NormalizeSearchTerm(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// SearchTokens разбивает запрос на слова для поиска синонимов и подсказок
+func SearchTokens(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+var (
+	ErrInvalidSynonym  = errors.New("term must be a single word and at least one synonym is required")
+	ErrSynonymNotFound = errors.New("synonym not found")
+	ErrSynonymExists   = errors.New("synonym for this term already exists")
+)
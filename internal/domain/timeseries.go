@@ -0,0 +1,254 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// StatsGranularity размер корзины временного ряда
+type StatsGranularity string
+
+const (
+	GranularityHour  StatsGranularity = "hour"
+	GranularityDay   StatsGranularity = "day"
+	GranularityWeek  StatsGranularity = "week"
+	GranularityMonth StatsGranularity = "month"
+)
+
+func (g StatsGranularity) Valid() bool {
+	switch g {
+	case GranularityHour, GranularityDay, GranularityWeek, GranularityMonth:
+		return true
+	}
+	return false
+}
+
+// Truncate начало корзины, в которую попадает t (UTC, недели с понедельника как в Postgres)
+func (g StatsGranularity) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	switch g {
+	case GranularityHour:
+		return t.Truncate(time.Hour)
+	case GranularityWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case GranularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// Next начало следующей корзины
+func (g StatsGranularity) Next(t time.Time) time.Time {
+	switch g {
+	case GranularityHour:
+		return t.Add(time.Hour)
+	case GranularityWeek:
+		return t.AddDate(0, 0, 7)
+	case GranularityMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// StatsMetric метрика временного ряда
+type StatsMetric string
+
+const (
+	MetricViews       StatsMetric = "views"
+	MetricCompletes   StatsMetric = "completes"
+	MetricLikes       StatsMetric = "likes"
+	MetricClicks      StatsMetric = "clicks"
+	MetricImpressions StatsMetric = "impressions"
+	MetricDwell       StatsMetric = "dwell" // суммарное время просмотра, мс
+)
+
+func (m StatsMetric) Valid() bool {
+	switch m {
+	case MetricViews, MetricCompletes, MetricLikes, MetricClicks, MetricImpressions, MetricDwell:
+		return true
+	}
+	return false
+}
+
+// ParseStatsMetrics разбирает список метрик через запятую; пустая строка — только views
+func ParseStatsMetrics(s string) ([]StatsMetric, error) {
+	if strings.TrimSpace(s) == "" {
+		return []StatsMetric{MetricViews}, nil
+	}
+	var metrics []StatsMetric
+	seen := map[StatsMetric]bool{}
+	for _, part := range strings.Split(s, ",") {
+		m := StatsMetric(strings.TrimSpace(part))
+		if !m.Valid() {
+			return nil, ErrInvalidStatsQuery
+		}
+		if !seen[m] {
+			seen[m] = true
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
+// StatsBreakdown разбивка временного ряда
+type StatsBreakdown string
+
+const (
+	BreakdownNone   StatsBreakdown = ""
+	BreakdownTag    StatsBreakdown = "tag"
+	BreakdownLang   StatsBreakdown = "lang"
+	BreakdownAuthor StatsBreakdown = "author"
+)
+
+func (b StatsBreakdown) Valid() bool {
+	switch b {
+	case BreakdownNone, BreakdownTag, BreakdownLang, BreakdownAuthor:
+		return true
+	}
+	return false
+}
+
+const (
+	// MaxTimeseriesPoints сколько корзин может быть в одном ряду
+	MaxTimeseriesPoints = 1000
+	// DefaultTimeseriesTop сколько значений разбивки отдаётся по умолчанию
+	DefaultTimeseriesTop = 10
+	MaxTimeseriesTop     = 50
+)
+
+// TimeseriesParams параметры /stats/timeseries
+type TimeseriesParams struct {
+	From        time.Time
+	To          time.Time
+	Granularity StatsGranularity
+	Metrics     []StatsMetric
+	Breakdown   StatsBreakdown
+	Top         int  // сколько значений разбивки с наибольшей первой метрикой отдавать
+	Compare     bool // добавить значения предыдущего периода той же длины
+}
+
+// Validate проверяет параметры и выравнивает [From, To) по границам корзин
+func (p *TimeseriesParams) Validate() error {
+	if !p.Granularity.Valid() || !p.Breakdown.Valid() || len(p.Metrics) == 0 {
+		return ErrInvalidStatsQuery
+	}
+	for _, m := range p.Metrics {
+		if !m.Valid() {
+			return ErrInvalidStatsQuery
+		}
+	}
+	if !p.From.Before(p.To) {
+		return ErrInvalidStatsQuery
+	}
+	if p.Top <= 0 {
+		p.Top = DefaultTimeseriesTop
+	}
+	if p.Top > MaxTimeseriesTop {
+		p.Top = MaxTimeseriesTop
+	}
+
+	p.From = p.Granularity.Truncate(p.From)
+	if end := p.Granularity.Truncate(p.To); end.Before(p.To) {
+		p.To = p.Granularity.Next(end)
+	} else {
+		p.To = end
+	}
+	if len(p.Buckets()) > MaxTimeseriesPoints {
+		return ErrStatsRangeTooLarge
+	}
+	return nil
+}
+
+// Buckets начала корзин периода [From, To)
+func (p *TimeseriesParams) Buckets() []time.Time {
+	var buckets []time.Time
+	for t := p.From; t.Before(p.To) && len(buckets) <= MaxTimeseriesPoints; t = p.Granularity.Next(t) {
+		buckets = append(buckets, t)
+	}
+	return buckets
+}
+
+// Previous параметры предыдущего периода: столько же корзин перед From
+func (p *TimeseriesParams) Previous() TimeseriesParams {
+	prev := *p
+	n := len(p.Buckets())
+	prev.To = p.From
+	prev.From = p.From
+	for i := 0; i < n; i++ {
+		prev.From = p.Granularity.Truncate(prev.From.Add(-time.Nanosecond))
+	}
+	return prev
+}
+
+// StatsValues сырые суммы метрик в корзине
+type StatsValues struct {
+	Views       int64
+	Completes   int64
+	Likes       int64
+	Clicks      int64
+	Impressions int64
+	DwellMs     int64
+}
+
+// Get значение метрики
+func (v StatsValues) Get(m StatsMetric) float64 {
+	switch m {
+	case MetricViews:
+		return float64(v.Views)
+	case MetricCompletes:
+		return float64(v.Completes)
+	case MetricLikes:
+		return float64(v.Likes)
+	case MetricClicks:
+		return float64(v.Clicks)
+	case MetricImpressions:
+		return float64(v.Impressions)
+	case MetricDwell:
+		return float64(v.DwellMs)
+	}
+	return 0
+}
+
+// TimeseriesRow строка агрегата из хранилища: корзина × значение разбивки
+type TimeseriesRow struct {
+	Bucket time.Time
+	Key    string // значение разбивки; "" без разбивки
+	Values StatsValues
+}
+
+// TimeseriesPoint точка ряда
+type TimeseriesPoint struct {
+	TS       time.Time
+	Values   map[StatsMetric]float64
+	Previous map[StatsMetric]float64 // та же корзина предыдущего периода (compare)
+}
+
+// TimeseriesSeries ряд одного значения разбивки
+type TimeseriesSeries struct {
+	Key        string
+	Points     []TimeseriesPoint
+	Totals     map[StatsMetric]float64
+	PrevTotals map[StatsMetric]float64
+	Change     map[StatsMetric]float64 // относительное изменение к предыдущему периоду; нет, если там 0
+}
+
+// Timeseries ответ /stats/timeseries
+type Timeseries struct {
+	From        time.Time
+	To          time.Time
+	Granularity StatsGranularity
+	Metrics     []StatsMetric
+	Breakdown   StatsBreakdown
+	PrevFrom    *time.Time
+	PrevTo      *time.Time
+	Series      []TimeseriesSeries
+}
+
+var (
+	ErrInvalidStatsQuery  = errors.New("invalid granularity, metrics, breakdown or period")
+	ErrStatsRangeTooLarge = errors.New("too many buckets for this granularity, narrow the period")
+)
@@ -30,6 +30,11 @@ type SearchParams struct {
 	Query  string
 	Limit  int
 	Offset int
+	// Expansions синонимы слов запроса (слово -> альтернативы), заполняется SearchUC
+	Expansions map[string][]string
+
+	UserID    uuid.UUID // пользователь или сессия — для назначения варианта эксперимента
+	SessionID string
 }
 
 // Validate проверяет корректность параметров поиска
@@ -0,0 +1,65 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+)
+
+// csvWriter CSV с заголовком; NULL — пустая ячейка, время — RFC 3339 в UTC
+type csvWriter struct {
+	w      *csv.Writer
+	cols   []domain.ExportColumn
+	record []string
+}
+
+func newCSVWriter(w io.Writer, cols []domain.ExportColumn) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), cols: cols, record: make([]string, len(cols))}
+	for i, c := range cols {
+		cw.record[i] = c.Name
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) WriteRow(values []any) error {
+	if err := checkRow(cw.cols, values); err != nil {
+		return err
+	}
+	for i, v := range values {
+		s, err := csvValue(cw.cols[i].Type, v)
+		if err != nil {
+			return err
+		}
+		cw.record[i] = s
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func csvValue(t domain.ExportColumnType, v any) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	switch t {
+	case domain.ExportInt:
+		n, err := toInt64(v)
+		return strconv.FormatInt(n, 10), err
+	case domain.ExportFloat:
+		f, err := toFloat64(v)
+		return strconv.FormatFloat(f, 'g', -1, 64), err
+	case domain.ExportTime:
+		ts, err := toTime(v)
+		return ts.Format(time.RFC3339Nano), err
+	}
+	return toString(v), nil
+}
@@ -0,0 +1,225 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+var testCols = []domain.ExportColumn{
+	{Name: "ts", Type: domain.ExportTime},
+	{Name: "video_id", Type: domain.ExportString},
+	{Name: "views", Type: domain.ExportInt},
+	{Name: "ctr", Type: domain.ExportFloat},
+}
+
+var testTS = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func writeAll(t *testing.T, f domain.ExportFormat, cols []domain.ExportColumn, rows [][]any) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(f, &buf, cols)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for _, r := range rows {
+		if !assert.NoError(t, w.WriteRow(r)) {
+			t.FailNow()
+		}
+	}
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	out := writeAll(t, domain.ExportCSV, testCols, [][]any{
+		{testTS, "a,b", int64(3), 0.25},
+		{testTS, nil, 0, nil},
+	})
+	assert.Equal(t, "ts,video_id,views,ctr\n"+
+		"2024-03-01T12:00:00Z,\"a,b\",3,0.25\n"+
+		"2024-03-01T12:00:00Z,,0,\n", string(out))
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	out := writeAll(t, domain.ExportNDJSON, testCols, [][]any{
+		{testTS, "v1", int64(3), math.NaN()},
+		{nil, "v2", nil, 0.5},
+	})
+	assert.Equal(t, `{"ts":"2024-03-01T12:00:00Z","video_id":"v1","views":3,"ctr":null}`+"\n"+
+		`{"ts":null,"video_id":"v2","views":null,"ctr":0.5}`+"\n", string(out))
+}
+
+func TestWriter_RowLengthMismatch(t *testing.T) {
+	w, err := NewWriter(domain.ExportCSV, &bytes.Buffer{}, testCols)
+	assert.NoError(t, err)
+	assert.Error(t, w.WriteRow([]any{testTS}))
+}
+
+func TestParquetWriter(t *testing.T) {
+	out := writeAll(t, domain.ExportParquet, testCols, [][]any{
+		{testTS, "v1", int64(3), 0.25},
+		{testTS, nil, int64(5), nil},
+		{nil, "v3", nil, 1.5},
+	})
+	meta := parquetFooter(t, out)
+
+	assert.Equal(t, int64(1), meta[1])
+	assert.Equal(t, int64(3), meta[3])
+	schema := meta[2].([]any)
+	if !assert.Len(t, schema, len(testCols)+1) {
+		return
+	}
+	assert.Equal(t, int64(len(testCols)), schema[0].(map[int16]any)[5])
+	assert.Equal(t, "views", schema[3].(map[int16]any)[4])
+	assert.Equal(t, int64(parquetTimestampUS), schema[1].(map[int16]any)[6])
+
+	groups := meta[4].([]any)
+	if !assert.Len(t, groups, 1) {
+		return
+	}
+	chunks := groups[0].(map[int16]any)[1].([]any)
+	assert.Len(t, chunks, len(testCols))
+
+	// Колонка views: NULL в третьей строке, значения 3 и 5
+	views := chunks[2].(map[int16]any)[3].(map[int16]any)
+	assert.Equal(t, []any{"views"}, views[3])
+	defs, values := parquetPage(t, out, views[9].(int64))
+	assert.Equal(t, []byte{2 << 1, 1, 1 << 1, 0}, defs)
+	if assert.Len(t, values, 16) {
+		assert.Equal(t, uint64(3), binary.LittleEndian.Uint64(values[:8]))
+		assert.Equal(t, uint64(5), binary.LittleEndian.Uint64(values[8:]))
+	}
+
+	// Колонка video_id: строки с 4-байтовой длиной
+	ids := chunks[1].(map[int16]any)[3].(map[int16]any)
+	_, values = parquetPage(t, out, ids[9].(int64))
+	assert.Equal(t, append(append([]byte{2, 0, 0, 0}, "v1"...), append([]byte{2, 0, 0, 0}, "v3"...)...), values)
+}
+
+func TestParquetWriter_RowGroupsAndWideSchema(t *testing.T) {
+	var cols []domain.ExportColumn
+	for i := range 16 {
+		cols = append(cols, domain.ExportColumn{Name: "c" + strconv.Itoa(i), Type: domain.ExportInt})
+	}
+	rows := make([][]any, parquetRowGroupRows+1)
+	for i := range rows {
+		rows[i] = make([]any, len(cols))
+		for j := range cols {
+			rows[i][j] = i
+		}
+	}
+
+	meta := parquetFooter(t, writeAll(t, domain.ExportParquet, cols, rows))
+
+	assert.Equal(t, int64(len(rows)), meta[3])
+	assert.Len(t, meta[2].([]any), len(cols)+1)
+	groups := meta[4].([]any)
+	if assert.Len(t, groups, 2) {
+		assert.Equal(t, int64(parquetRowGroupRows), groups[0].(map[int16]any)[3])
+		assert.Equal(t, int64(1), groups[1].(map[int16]any)[3])
+	}
+}
+
+// parquetFooter проверяет магические байты и разбирает FileMetaData
+func parquetFooter(t *testing.T, b []byte) map[int16]any {
+	if !assert.Greater(t, len(b), 12) {
+		t.FailNow()
+	}
+	assert.Equal(t, parquetMagic, string(b[:4]))
+	assert.Equal(t, parquetMagic, string(b[len(b)-4:]))
+	n := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	r := &thriftReader{b: b[len(b)-8-n : len(b)-8]}
+	meta := r.readStruct()
+	assert.Equal(t, n, r.pos, "footer length")
+	return meta
+}
+
+// parquetPage разбирает страницу данных по смещению: уровни определения (без длины) и значения
+func parquetPage(t *testing.T, b []byte, offset int64) ([]byte, []byte) {
+	r := &thriftReader{b: b[offset:]}
+	header := r.readStruct()
+	size := int(header[2].(int64))
+	assert.Equal(t, int64(parquetDataPage), header[1])
+	page := b[int(offset)+r.pos : int(offset)+r.pos+size]
+	n := int(binary.LittleEndian.Uint32(page[:4]))
+	return page[4 : 4+n], page[4+n:]
+}
+
+// thriftReader декодер Thrift Compact Protocol для проверки метаданных в тестах
+type thriftReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	res := map[int16]any{}
+	var last int16
+	for {
+		h := r.b[r.pos]
+		r.pos++
+		if h == 0 {
+			return res
+		}
+		typ := h & 0x0F
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		last = id
+		switch typ {
+		case 1, 2:
+			res[id] = typ == 1
+		default:
+			res[id] = r.value(typ)
+		}
+	}
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case 3:
+		r.pos++
+		return int64(r.b[r.pos-1])
+	case 4, 5, 6:
+		return r.zigzag()
+	case 7:
+		r.pos += 8
+		return math.Float64frombits(binary.LittleEndian.Uint64(r.b[r.pos-8:]))
+	case 8:
+		n := int(r.uvarint())
+		r.pos += n
+		return string(r.b[r.pos-n : r.pos])
+	case 9:
+		h := r.b[r.pos]
+		r.pos++
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		res := make([]any, n)
+		for i := range res {
+			res[i] = r.value(h & 0x0F)
+		}
+		return res
+	case 12:
+		return r.readStruct()
+	}
+	panic("unsupported thrift type " + strconv.Itoa(int(typ)))
+}
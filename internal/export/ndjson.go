@@ -0,0 +1,81 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math"
+
+	"github.com/arasvet/microtube/internal/domain"
+)
+
+// ndjsonWriter объект JSON на строку; ключи в порядке колонок
+type ndjsonWriter struct {
+	w    *bufio.Writer
+	cols []domain.ExportColumn
+	keys [][]byte // закодированные имена колонок с двоеточием
+}
+
+func newNDJSONWriter(w io.Writer, cols []domain.ExportColumn) *ndjsonWriter {
+	nw := &ndjsonWriter{w: bufio.NewWriter(w), cols: cols, keys: make([][]byte, len(cols))}
+	for i, c := range cols {
+		k, _ := json.Marshal(c.Name)
+		nw.keys[i] = append(k, ':')
+	}
+	return nw
+}
+
+func (nw *ndjsonWriter) WriteRow(values []any) error {
+	if err := checkRow(nw.cols, values); err != nil {
+		return err
+	}
+	nw.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			nw.w.WriteByte(',')
+		}
+		nw.w.Write(nw.keys[i])
+		b, err := ndjsonValue(nw.cols[i].Type, v)
+		if err != nil {
+			return err
+		}
+		nw.w.Write(b)
+	}
+	nw.w.WriteByte('}')
+	return nw.w.WriteByte('\n')
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nw.w.Flush()
+}
+
+func ndjsonValue(t domain.ExportColumnType, v any) ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	switch t {
+	case domain.ExportInt:
+		n, err := toInt64(v)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(n)
+	case domain.ExportFloat:
+		f, err := toFloat64(v)
+		if err != nil {
+			return nil, err
+		}
+		// NaN и бесконечности в JSON не представимы
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return []byte("null"), nil
+		}
+		return json.Marshal(f)
+	case domain.ExportTime:
+		ts, err := toTime(v)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(ts)
+	}
+	return json.Marshal(toString(v))
+}
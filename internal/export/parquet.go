@@ -0,0 +1,265 @@
+package export
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/arasvet/microtube/internal/domain"
+)
+
+// Parquet без внешних зависимостей: все колонки OPTIONAL, кодирование PLAIN без сжатия
+// (сжатие — gzip всего файла), одна страница данных на колонку в группе строк.
+// Файл пишется потоком: группа строк сбрасывается, когда набирает parquetRowGroupRows строк
+// или parquetRowGroupBytes байт, в памяти остаются только метаданные групп для футера.
+const (
+	parquetMagic         = "PAR1"
+	parquetRowGroupRows  = 64 * 1024
+	parquetRowGroupBytes = 64 << 20
+	parquetCreatedBy     = "microtube export"
+)
+
+// Значения перечислений из parquet.thrift
+const (
+	parquetInt64         = 2
+	parquetDouble        = 5
+	parquetByteArray     = 6
+	parquetOptional      = 1
+	parquetUTF8          = 0
+	parquetTimestampUS   = 10
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+	parquetUncompressed  = 0
+	parquetDataPage      = 0
+)
+
+type parquetColumn struct {
+	col    domain.ExportColumn
+	values []byte // значения не-NULL в PLAIN
+	defs   []bool // уровень определения каждой строки: true — значение есть
+}
+
+type parquetWriter struct {
+	w       io.Writer
+	offset  int64
+	schema  []domain.ExportColumn
+	cols    []*parquetColumn
+	rows    int   // строк в текущей группе
+	size    int   // байт значений в текущей группе
+	total   int64 // всего строк
+	groups  [][]byte
+	scratch [8]byte
+}
+
+func newParquetWriter(w io.Writer, cols []domain.ExportColumn) (*parquetWriter, error) {
+	pw := &parquetWriter{w: w, schema: cols}
+	for _, c := range cols {
+		pw.cols = append(pw.cols, &parquetColumn{col: c})
+	}
+	if err := pw.write([]byte(parquetMagic)); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+func (pw *parquetWriter) write(b []byte) error {
+	n, err := pw.w.Write(b)
+	pw.offset += int64(n)
+	return err
+}
+
+func (pw *parquetWriter) WriteRow(values []any) error {
+	if err := checkRow(pw.schema, values); err != nil {
+		return err
+	}
+	for i, v := range values {
+		c := pw.cols[i]
+		if v == nil {
+			c.defs = append(c.defs, false)
+			continue
+		}
+		before := len(c.values)
+		if err := pw.appendValue(c, v); err != nil {
+			return err
+		}
+		c.defs = append(c.defs, true)
+		pw.size += len(c.values) - before
+	}
+	pw.rows++
+	if pw.rows >= parquetRowGroupRows || pw.size >= parquetRowGroupBytes {
+		return pw.flushRowGroup()
+	}
+	return nil
+}
+
+func (pw *parquetWriter) appendValue(c *parquetColumn, v any) error {
+	switch c.col.Type {
+	case domain.ExportInt:
+		n, err := toInt64(v)
+		if err != nil {
+			return err
+		}
+		c.values = binary.LittleEndian.AppendUint64(c.values, uint64(n))
+	case domain.ExportFloat:
+		f, err := toFloat64(v)
+		if err != nil {
+			return err
+		}
+		c.values = binary.LittleEndian.AppendUint64(c.values, math.Float64bits(f))
+	case domain.ExportTime:
+		t, err := toTime(v)
+		if err != nil {
+			return err
+		}
+		c.values = binary.LittleEndian.AppendUint64(c.values, uint64(t.UnixMicro()))
+	default:
+		s := toString(v)
+		c.values = binary.LittleEndian.AppendUint32(c.values, uint32(len(s)))
+		c.values = append(c.values, s...)
+	}
+	return nil
+}
+
+// flushRowGroup пишет накопленные строки группой: по странице данных на колонку
+func (pw *parquetWriter) flushRowGroup() error {
+	if pw.rows == 0 {
+		return nil
+	}
+	start := pw.offset
+	chunks := make([][]byte, 0, len(pw.cols))
+	for _, c := range pw.cols {
+		offset := pw.offset
+		defs := encodeDefinitionLevels(c.defs)
+
+		page := newThriftWriter()
+		page.beginStruct(0)
+		page.i32(1, parquetDataPage)
+		size := int32(len(defs) + len(c.values))
+		page.i32(2, size)
+		page.i32(3, size)
+		page.beginStruct(5) // DataPageHeader
+		page.i32(1, int32(pw.rows))
+		page.i32(2, parquetEncodingPlain)
+		page.i32(3, parquetEncodingRLE)
+		page.i32(4, parquetEncodingRLE)
+		page.endStruct()
+		page.endStruct()
+
+		for _, b := range [][]byte{page.buf, defs, c.values} {
+			if err := pw.write(b); err != nil {
+				return err
+			}
+		}
+		chunkSize := pw.offset - offset
+
+		chunk := newThriftWriter()
+		chunk.beginStruct(0)
+		chunk.i64(2, offset)
+		chunk.beginStruct(3) // ColumnMetaData
+		chunk.i32(1, parquetPhysicalType(c.col.Type))
+		chunk.listI32(2, parquetEncodingPlain, parquetEncodingRLE)
+		chunk.listBinary(3, c.col.Name)
+		chunk.i32(4, parquetUncompressed)
+		chunk.i64(5, int64(pw.rows))
+		chunk.i64(6, chunkSize)
+		chunk.i64(7, chunkSize)
+		chunk.i64(9, offset)
+		chunk.endStruct()
+		chunk.endStruct()
+		chunks = append(chunks, chunk.buf)
+
+		c.values = c.values[:0]
+		c.defs = c.defs[:0]
+	}
+
+	group := newThriftWriter()
+	group.beginStruct(0)
+	group.list(1, thriftStruct, len(chunks))
+	for _, ch := range chunks {
+		group.buf = append(group.buf, ch...)
+	}
+	group.i64(2, pw.offset-start)
+	group.i64(3, int64(pw.rows))
+	group.endStruct()
+	pw.groups = append(pw.groups, group.buf)
+
+	pw.total += int64(pw.rows)
+	pw.rows, pw.size = 0, 0
+	return nil
+}
+
+// Close сбрасывает последнюю группу и пишет футер FileMetaData
+func (pw *parquetWriter) Close() error {
+	if err := pw.flushRowGroup(); err != nil {
+		return err
+	}
+
+	meta := newThriftWriter()
+	meta.beginStruct(0)
+	meta.i32(1, 1)
+	meta.list(2, thriftStruct, len(pw.cols)+1)
+	meta.beginStruct(0) // корень схемы
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(pw.cols)))
+	meta.endStruct()
+	for _, c := range pw.cols {
+		meta.beginStruct(0)
+		meta.i32(1, parquetPhysicalType(c.col.Type))
+		meta.i32(3, parquetOptional)
+		meta.binary(4, c.col.Name)
+		switch c.col.Type {
+		case domain.ExportString:
+			meta.i32(6, parquetUTF8)
+		case domain.ExportTime:
+			meta.i32(6, parquetTimestampUS)
+		}
+		meta.endStruct()
+	}
+	meta.i64(3, pw.total)
+	meta.list(4, thriftStruct, len(pw.groups))
+	for _, g := range pw.groups {
+		meta.buf = append(meta.buf, g...)
+	}
+	meta.binary(6, parquetCreatedBy)
+	meta.endStruct()
+
+	if err := pw.write(meta.buf); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(pw.scratch[:4], uint32(len(meta.buf)))
+	if err := pw.write(pw.scratch[:4]); err != nil {
+		return err
+	}
+	return pw.write([]byte(parquetMagic))
+}
+
+func parquetPhysicalType(t domain.ExportColumnType) int32 {
+	switch t {
+	case domain.ExportInt, domain.ExportTime:
+		return parquetInt64
+	case domain.ExportFloat:
+		return parquetDouble
+	}
+	return parquetByteArray
+}
+
+// encodeDefinitionLevels уровни определения (0/1) в RLE с 4-байтовой длиной впереди, как в страницах v1
+func encodeDefinitionLevels(defs []bool) []byte {
+	out := make([]byte, 4, 16)
+	for i := 0; i < len(defs); {
+		j := i
+		for j < len(defs) && defs[j] == defs[i] {
+			j++
+		}
+		// RLE-прогон: заголовок (длина << 1), затем значение в одном байте (разрядность 1)
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		if defs[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i = j
+	}
+	binary.LittleEndian.PutUint32(out[:4], uint32(len(out)-4))
+	return out
+}
@@ -0,0 +1,98 @@
+package export
+
+import "encoding/binary"
+
+// Минимальный кодировщик Thrift Compact Protocol для метаданных Parquet:
+// только типы, которые нужны заголовкам страниц и футеру файла.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+type thriftWriter struct {
+	buf    []byte
+	lastID []int16 // id последнего поля для каждой вложенной структуры
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{lastID: []int16{0}}
+}
+
+func (t *thriftWriter) varint(v uint64) {
+	t.buf = binary.AppendUvarint(t.buf, v)
+}
+
+func (t *thriftWriter) zigzag(v int64) {
+	t.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	last := &t.lastID[len(t.lastID)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.zigzag(int64(id))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.zigzag(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.zigzag(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.varint(uint64(len(s)))
+	t.buf = append(t.buf, s...)
+}
+
+// list заголовок списка из n элементов типа elem
+func (t *thriftWriter) list(id int16, elem byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf = append(t.buf, byte(n)<<4|elem)
+		return
+	}
+	t.buf = append(t.buf, 0xF0|elem)
+	t.varint(uint64(n))
+}
+
+// beginStruct начинает структуру: поле id (или элемент списка при id == 0)
+func (t *thriftWriter) beginStruct(id int16) {
+	if id != 0 {
+		t.field(id, thriftStruct)
+	}
+	t.lastID = append(t.lastID, 0)
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf = append(t.buf, 0)
+	t.lastID = t.lastID[:len(t.lastID)-1]
+}
+
+// listI32 элементы списка i32
+func (t *thriftWriter) listI32(id int16, vs ...int32) {
+	t.list(id, thriftI32, len(vs))
+	for _, v := range vs {
+		t.zigzag(int64(v))
+	}
+}
+
+// listBinary элементы списка строк
+func (t *thriftWriter) listBinary(id int16, vs ...string) {
+	t.list(id, thriftBinary, len(vs))
+	for _, v := range vs {
+		t.varint(uint64(len(v)))
+		t.buf = append(t.buf, v...)
+	}
+}
@@ -0,0 +1,101 @@
+// Package export пишет строки выгрузок аналитики в CSV, NDJSON и Parquet потоком:
+// в памяти держится не больше одной группы строк Parquet.
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+)
+
+// Writer пишет строки выгрузки; значения идут в порядке колонок, nil — NULL.
+// Close дописывает хвост формата (футер Parquet, буферы) и не закрывает нижележащий io.Writer.
+type Writer interface {
+	WriteRow(values []any) error
+	Close() error
+}
+
+// NewWriter создаёт писатель формата f с колонками cols
+func NewWriter(f domain.ExportFormat, w io.Writer, cols []domain.ExportColumn) (Writer, error) {
+	switch f {
+	case domain.ExportCSV:
+		return newCSVWriter(w, cols)
+	case domain.ExportNDJSON:
+		return newNDJSONWriter(w, cols), nil
+	case domain.ExportParquet:
+		return newParquetWriter(w, cols)
+	}
+	return nil, domain.ErrInvalidExport
+}
+
+// ContentType MIME-тип выгрузки
+func ContentType(f domain.ExportFormat) string {
+	switch f {
+	case domain.ExportCSV:
+		return "text/csv; charset=utf-8"
+	case domain.ExportNDJSON:
+		return "application/x-ndjson"
+	}
+	return "application/vnd.apache.parquet"
+}
+
+// toInt64 приводит значение к int64 для колонок ExportInt
+func toInt64(v any) (int64, error) {
+	switch x := v.(type) {
+	case int64:
+		return x, nil
+	case int:
+		return int64(x), nil
+	case int32:
+		return int64(x), nil
+	case float64:
+		return int64(x), nil
+	}
+	return 0, fmt.Errorf("export: %T is not an integer", v)
+}
+
+// toFloat64 приводит значение к float64 для колонок ExportFloat
+func toFloat64(v any) (float64, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case float32:
+		return float64(x), nil
+	case int64:
+		return float64(x), nil
+	case int:
+		return float64(x), nil
+	}
+	return 0, fmt.Errorf("export: %T is not a number", v)
+}
+
+// toTime приводит значение к времени UTC для колонок ExportTime
+func toTime(v any) (time.Time, error) {
+	if t, ok := v.(time.Time); ok {
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("export: %T is not a time", v)
+}
+
+// toString строковое значение для колонок ExportString
+func toString(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case fmt.Stringer:
+		return x.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// checkRow проверяет число значений в строке
+func checkRow(cols []domain.ExportColumn, values []any) error {
+	if len(values) != len(cols) {
+		return fmt.Errorf("export: got %d values for %d columns", len(values), len(cols))
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type CommentsHandler struct {
+	UC usecase.CommentsUCInterface
+}
+
+func (h *CommentsHandler) Register(r chi.Router) {
+	r.Get("/videos/{id}/comments", h.list)
+	r.Post("/videos/{id}/comments", h.create)
+	r.Patch("/comments/{id}", h.edit)
+	r.Delete("/comments/{id}", h.delete)
+	r.Post("/comments/{id}/like", h.like)
+	r.Delete("/comments/{id}/like", h.unlike)
+}
+
+type commentIn struct {
+	Body     string `json:"body"`
+	ParentID string `json:"parent_id"`
+}
+
+// list отдаёт корневые комментарии видео (или ответы при parent_id) с курсорной пагинацией
+func (h *CommentsHandler) list(w http.ResponseWriter, r *http.Request) {
+	videoID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid video id", http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	limit := 20
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	cursor, err := domain.DecodeCursor(q.Get("cursor"))
+	if err != nil {
+		http.Error(w, "invalid cursor", http.StatusBadRequest)
+		return
+	}
+
+	params := domain.CommentListParams{VideoID: videoID, Cursor: cursor, Limit: limit}
+	if parent := q.Get("parent_id"); parent != "" {
+		parentID, err := uuid.Parse(parent)
+		if err != nil {
+			http.Error(w, "invalid parent_id", http.StatusBadRequest)
+			return
+		}
+		params.ParentID = &parentID
+	}
+
+	page, err := h.UC.List(r.Context(), params)
+	if err != nil {
+		writeCommentError(w, err)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"total":       len(page.Comments),
+		"comments":    page.Comments,
+		"next_cursor": page.NextCursor,
+	})
+}
+
+func (h *CommentsHandler) create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	videoID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid video id", http.StatusBadRequest)
+		return
+	}
+
+	var in commentIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, "bad JSON body", http.StatusBadRequest)
+		return
+	}
+	var parentID *uuid.UUID
+	if in.ParentID != "" {
+		p, err := uuid.Parse(in.ParentID)
+		if err != nil {
+			http.Error(w, "invalid parent_id", http.StatusUnprocessableEntity)
+			return
+		}
+		parentID = &p
+	}
+
+	c, err := h.UC.Create(r.Context(), userID, videoID, parentID, in.Body)
+	if err != nil {
+		writeCommentError(w, err)
+		return
+	}
+	writeJSONStatus(w, http.StatusCreated, c)
+}
+
+func (h *CommentsHandler) edit(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	commentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid comment id", http.StatusBadRequest)
+		return
+	}
+
+	var in commentIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, "bad JSON body", http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.UC.Edit(r.Context(), userID, commentID, in.Body)
+	if err != nil {
+		writeCommentError(w, err)
+		return
+	}
+	writeJSON(w, c)
+}
+
+func (h *CommentsHandler) delete(w http.ResponseWriter, r *http.Request) {
+	h.withComment(w, r, h.UC.Delete)
+}
+
+func (h *CommentsHandler) like(w http.ResponseWriter, r *http.Request) {
+	h.withComment(w, r, h.UC.Like)
+}
+
+func (h *CommentsHandler) unlike(w http.ResponseWriter, r *http.Request) {
+	h.withComment(w, r, h.UC.Unlike)
+}
+
+// withComment общий разбор для действий пользователя над комментарием без тела ответа
+func (h *CommentsHandler) withComment(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, userID, commentID uuid.UUID) error) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	commentID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid comment id", http.StatusBadRequest)
+		return
+	}
+
+	if err := action(r.Context(), userID, commentID); err != nil {
+		writeCommentError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeCommentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidComment):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, domain.ErrCommentNotFound), errors.Is(err, domain.ErrVideoNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrCommentForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		log.Printf("comments error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCommentsUC - мок для тестирования
+type MockCommentsUC struct {
+	mock.Mock
+}
+
+func (m *MockCommentsUC) List(ctx context.Context, params domain.CommentListParams) (domain.CommentPage, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(domain.CommentPage), args.Error(1)
+}
+
+func (m *MockCommentsUC) Create(ctx context.Context, userID, videoID uuid.UUID, parentID *uuid.UUID, body string) (domain.Comment, error) {
+	args := m.Called(ctx, userID, videoID, parentID, body)
+	return args.Get(0).(domain.Comment), args.Error(1)
+}
+
+func (m *MockCommentsUC) Edit(ctx context.Context, userID, commentID uuid.UUID, body string) (domain.Comment, error) {
+	args := m.Called(ctx, userID, commentID, body)
+	return args.Get(0).(domain.Comment), args.Error(1)
+}
+
+func (m *MockCommentsUC) Delete(ctx context.Context, userID, commentID uuid.UUID) error {
+	return m.Called(ctx, userID, commentID).Error(0)
+}
+
+func (m *MockCommentsUC) Like(ctx context.Context, userID, commentID uuid.UUID) error {
+	return m.Called(ctx, userID, commentID).Error(0)
+}
+
+func (m *MockCommentsUC) Unlike(ctx context.Context, userID, commentID uuid.UUID) error {
+	return m.Called(ctx, userID, commentID).Error(0)
+}
+
+// withUser кладёт user_id в контекст запроса, как это делает JWTAuthMiddleware
+func withUser(req *http.Request, userID uuid.UUID) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), userIDCtxKey, userID.String()))
+}
+
+func newCommentsRouter(uc *MockCommentsUC) chi.Router {
+	r := chi.NewRouter()
+	(&CommentsHandler{UC: uc}).Register(r)
+	return r
+}
+
+func TestCommentsHandler_List(t *testing.T) {
+	videoID := uuid.New()
+	cursor := domain.Cursor{TS: time.Now().UTC(), ID: uuid.New()}
+
+	mockUC := new(MockCommentsUC)
+	mockUC.On("List", mock.Anything, domain.CommentListParams{VideoID: videoID, Limit: 20}).
+		Return(domain.CommentPage{Comments: []domain.Comment{{ID: uuid.New(), VideoID: videoID}}, NextCursor: cursor.Encode()}, nil)
+
+	r := newCommentsRouter(mockUC)
+
+	req := httptest.NewRequest("GET", "/videos/"+videoID.String()+"/comments", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), cursor.Encode())
+
+	// Битый курсор -> 400
+	req = httptest.NewRequest("GET", "/videos/"+videoID.String()+"/comments?cursor=@@@", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUC.AssertExpectations(t)
+}
+
+func TestCommentsHandler_Create(t *testing.T) {
+	videoID := uuid.New()
+	userID := uuid.New()
+
+	mockUC := new(MockCommentsUC)
+	mockUC.On("Create", mock.Anything, userID, videoID, (*uuid.UUID)(nil), "nice video").
+		Return(domain.Comment{ID: uuid.New(), VideoID: videoID, UserID: userID, Body: "nice video"}, nil)
+	mockUC.On("Create", mock.Anything, userID, videoID, (*uuid.UUID)(nil), "").
+		Return(domain.Comment{}, domain.ErrInvalidComment)
+
+	r := newCommentsRouter(mockUC)
+
+	// Без авторизации -> 401
+	req := httptest.NewRequest("POST", "/videos/"+videoID.String()+"/comments", strings.NewReader(`{"body":"nice video"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Успешное создание -> 201
+	req = withUser(httptest.NewRequest("POST", "/videos/"+videoID.String()+"/comments", strings.NewReader(`{"body":"nice video"}`)), userID)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Пустой текст -> 422
+	req = withUser(httptest.NewRequest("POST", "/videos/"+videoID.String()+"/comments", strings.NewReader(`{"body":""}`)), userID)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	mockUC.AssertExpectations(t)
+}
+
+func TestCommentsHandler_EditForbidden(t *testing.T) {
+	commentID := uuid.New()
+	userID := uuid.New()
+
+	mockUC := new(MockCommentsUC)
+	mockUC.On("Edit", mock.Anything, userID, commentID, "edited").
+		Return(domain.Comment{}, domain.ErrCommentForbidden)
+
+	r := newCommentsRouter(mockUC)
+
+	req := withUser(httptest.NewRequest("PATCH", "/comments/"+commentID.String(), strings.NewReader(`{"body":"edited"}`)), userID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockUC.AssertExpectations(t)
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// CreatorStatsHandler статистика авторов по своим видео и каналу
+type CreatorStatsHandler struct {
+	UC usecase.CreatorStatsUCInterface
+}
+
+func (h *CreatorStatsHandler) Register(r chi.Router) {
+	r.Get("/videos/{id}/stats", h.videoStats)
+	r.Get("/videos/{id}/stats/retention", h.videoRetention)
+	r.Get("/me/channel/stats", h.channelStats)
+}
+
+func (h *CreatorStatsHandler) videoStats(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	videoID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid video id", http.StatusBadRequest)
+		return
+	}
+	from, to, ok := parseStatsDays(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.UC.VideoStats(r.Context(), userID, videoID, from, to)
+	if err != nil {
+		writeCreatorStatsError(w, err)
+		return
+	}
+	writeJSON(w, stats)
+}
+
+func (h *CreatorStatsHandler) videoRetention(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	videoID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid video id", http.StatusBadRequest)
+		return
+	}
+
+	retention, err := h.UC.VideoRetention(r.Context(), userID, videoID)
+	if err != nil {
+		writeCreatorStatsError(w, err)
+		return
+	}
+	writeJSON(w, retention)
+}
+
+func (h *CreatorStatsHandler) channelStats(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	from, to, ok := parseStatsDays(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.UC.ChannelStats(r.Context(), userID, from, to)
+	if err != nil {
+		writeCreatorStatsError(w, err)
+		return
+	}
+	writeJSON(w, stats)
+}
+
+// parseStatsDays период from/to (YYYY-MM-DD, включительно); по умолчанию последние 30 дней
+func parseStatsDays(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := to.AddDate(0, 0, -29)
+
+	var err error
+	if s := r.URL.Query().Get("from"); s != "" {
+		if from, err = time.Parse(time.DateOnly, s); err != nil {
+			http.Error(w, "invalid from", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if s := r.URL.Query().Get("to"); s != "" {
+		if to, err = time.Parse(time.DateOnly, s); err != nil {
+			http.Error(w, "invalid to", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	return from, to, true
+}
+
+func writeCreatorStatsError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrVideoNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrVideoStatsForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, domain.ErrInvalidStatsPeriod):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("creator stats error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+	}
+}
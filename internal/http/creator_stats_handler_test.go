@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCreatorStatsUC - мок для тестирования
+type MockCreatorStatsUC struct {
+	mock.Mock
+}
+
+func (m *MockCreatorStatsUC) VideoStats(ctx context.Context, userID, videoID uuid.UUID, from, to time.Time) (domain.CreatorStats, error) {
+	args := m.Called(ctx, userID, videoID, from, to)
+	return args.Get(0).(domain.CreatorStats), args.Error(1)
+}
+
+func (m *MockCreatorStatsUC) ChannelStats(ctx context.Context, userID uuid.UUID, from, to time.Time) (domain.CreatorStats, error) {
+	args := m.Called(ctx, userID, from, to)
+	return args.Get(0).(domain.CreatorStats), args.Error(1)
+}
+
+func (m *MockCreatorStatsUC) VideoRetention(ctx context.Context, userID, videoID uuid.UUID) (domain.VideoRetention, error) {
+	args := m.Called(ctx, userID, videoID)
+	return args.Get(0).(domain.VideoRetention), args.Error(1)
+}
+
+func TestCreatorStatsHandler_VideoStats(t *testing.T) {
+	author, stranger := uuid.New(), uuid.New()
+	videoID := uuid.New()
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC)
+
+	mockUC := new(MockCreatorStatsUC)
+	mockUC.On("VideoStats", mock.Anything, author, videoID, from, to).
+		Return(domain.CreatorStats{AuthorID: author, Totals: domain.CreatorDayStats{Views: 10}}, nil)
+	mockUC.On("VideoStats", mock.Anything, stranger, videoID, from, to).
+		Return(domain.CreatorStats{}, domain.ErrVideoStatsForbidden)
+
+	r := chi.NewRouter()
+	(&CreatorStatsHandler{UC: mockUC}).Register(r)
+	url := "/videos/" + videoID.String() + "/stats?from=2024-03-01&to=2024-03-07"
+
+	// Автор видит статистику
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, withUser(httptest.NewRequest("GET", url, nil), author))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Views":10`)
+
+	// Чужое видео -> 403
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, withUser(httptest.NewRequest("GET", url, nil), stranger))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Без авторизации -> 401, UC не вызывается
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Невалидная дата -> 400
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, withUser(httptest.NewRequest("GET", "/videos/"+videoID.String()+"/stats?from=yesterday", nil), author))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUC.AssertExpectations(t)
+}
+
+func TestCreatorStatsHandler_VideoRetention(t *testing.T) {
+	author := uuid.New()
+	videoID, missing := uuid.New(), uuid.New()
+
+	mockUC := new(MockCreatorStatsUC)
+	mockUC.On("VideoRetention", mock.Anything, author, videoID).
+		Return(domain.VideoRetention{Viewers: 40, Points: []domain.RetentionPoint{{Percent: 0, Viewers: 40, Retention: 1}}}, nil)
+	mockUC.On("VideoRetention", mock.Anything, author, missing).
+		Return(domain.VideoRetention{}, domain.ErrVideoNotFound)
+
+	r := chi.NewRouter()
+	(&CreatorStatsHandler{UC: mockUC}).Register(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, withUser(httptest.NewRequest("GET", "/videos/"+videoID.String()+"/stats/retention", nil), author))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Viewers":40`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, withUser(httptest.NewRequest("GET", "/videos/"+missing.String()+"/stats/retention", nil), author))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockUC.AssertExpectations(t)
+}
@@ -2,7 +2,9 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"time"
 
@@ -13,7 +15,7 @@ import (
 )
 
 type EventsHandler struct {
-	UC *usecase.EventsUC
+	UC usecase.EventsUCInterface
 }
 
 func (h *EventsHandler) Register(r chi.Router) {
@@ -21,14 +23,17 @@ func (h *EventsHandler) Register(r chi.Router) {
 }
 
 type postEventIn struct {
-	EventID   string    `json:"event_id"`
-	TS        time.Time `json:"ts"`
-	Type      string    `json:"type"`
-	SessionID string    `json:"session_id"`
-	UserID    string    `json:"user_id"`
-	VideoID   string    `json:"video_id"`
-	Query     string    `json:"query"`
-	DwellMs   int       `json:"dwell_ms"`
+	EventID    string    `json:"event_id"`
+	TS         time.Time `json:"ts"`
+	Type       string    `json:"type"`
+	SessionID  string    `json:"session_id"`
+	UserID     string    `json:"user_id"`
+	VideoID    string    `json:"video_id"`
+	Query      string    `json:"query"`
+	DwellMs    int       `json:"dwell_ms"`
+	Tag        string    `json:"tag"`
+	ServeID    string    `json:"serve_id"`
+	PositionMs int       `json:"position_ms"` // для progress
 }
 
 func (h *EventsHandler) postEvent(w http.ResponseWriter, r *http.Request) {
@@ -44,16 +49,28 @@ func (h *EventsHandler) postEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// user_id — subject токена: гость не может прислать событие от имени пользователя,
+	// авторизованный — от чужого имени. Без user_id в теле берём его из токена.
 	var uid uuid.UUID
+	if sub, ok := UserIDFromContext(r); ok {
+		u, err := uuid.Parse(sub)
+		if err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		uid = u
+	}
 	if in.UserID != "" {
 		u, err := uuid.Parse(in.UserID)
 		if err != nil {
 			http.Error(w, "invalid user_id", http.StatusUnprocessableEntity)
 			return
 		}
-		uid = u
+		if u != uid {
+			http.Error(w, "user_id does not match the authenticated user", http.StatusForbidden)
+			return
+		}
 	}
-	// Если user_id пустой, оставляем uuid.Nil
 
 	var vid uuid.UUID
 	if in.VideoID != "" {
@@ -66,15 +83,31 @@ func (h *EventsHandler) postEvent(w http.ResponseWriter, r *http.Request) {
 	}
 	// Если video_id пустой, оставляем uuid.Nil
 
+	// serve_id из ответа выдачи, по которой кликнули
+	var serveID uuid.UUID
+	if in.ServeID != "" {
+		s, err := uuid.Parse(in.ServeID)
+		if err != nil {
+			http.Error(w, "invalid serve_id", http.StatusUnprocessableEntity)
+			return
+		}
+		serveID = s
+	}
+
 	e := domain.Event{
-		EventID:   evID,
-		TS:        in.TS,
-		Type:      domain.EventType(in.Type),
-		SessionID: in.SessionID,
-		UserID:    uid,
-		VideoID:   vid,
-		Query:     in.Query,
-		DwellMs:   in.DwellMs,
+		EventID:    evID,
+		TS:         in.TS,
+		Type:       domain.EventType(in.Type),
+		SessionID:  in.SessionID,
+		UserID:     uid,
+		VideoID:    vid,
+		Query:      in.Query,
+		DwellMs:    in.DwellMs,
+		Tag:        in.Tag,
+		ServeID:    serveID,
+		PositionMs: in.PositionMs,
+		IP:         clientIP(r),
+		UserAgent:  r.UserAgent(),
 	}
 	if err := e.Validate(); err != nil {
 		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
@@ -82,6 +115,10 @@ func (h *EventsHandler) postEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ingest, err := h.UC.Ingest(r.Context(), e)
+	if errors.Is(err, domain.ErrEventTooOld) || errors.Is(err, domain.ErrDwellTooLong) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
 	if err != nil {
 		log.Printf("failed to ingest event: %v", err)
 		http.Error(w, "internal", http.StatusInternalServerError)
@@ -96,3 +133,12 @@ func (h *EventsHandler) postEvent(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusCreated)
 }
+
+// clientIP адрес клиента; заголовки доверенных прокси уже разобраны RealIPMiddleware
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
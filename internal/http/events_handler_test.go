@@ -0,0 +1,117 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockEventsUC - мок для тестирования
+type MockEventsUC struct {
+	mock.Mock
+}
+
+func (m *MockEventsUC) Ingest(ctx context.Context, e domain.Event) (usecase.IngestResult, error) {
+	args := m.Called(ctx, e)
+	return args.Get(0).(usecase.IngestResult), args.Error(1)
+}
+
+func eventBody(userID string) string {
+	body := `{"event_id":"` + uuid.NewString() + `","ts":"2024-03-01T12:00:00Z","type":"view_start",` +
+		`"session_id":"sess-1","video_id":"` + uuid.NewString() + `"`
+	if userID != "" {
+		body += `,"user_id":"` + userID + `"`
+	}
+	return body + `}`
+}
+
+func TestEventsHandler_UserFromToken(t *testing.T) {
+	userID := uuid.New()
+	mockUC := new(MockEventsUC)
+	mockUC.On("Ingest", mock.Anything, mock.MatchedBy(func(e domain.Event) bool {
+		return e.UserID == userID && e.IP == "203.0.113.7" && e.UserAgent == "Mozilla/5.0"
+	})).Return(usecase.IngestResult{Inserted: true}, nil).Twice()
+
+	r := chi.NewRouter()
+	(&EventsHandler{UC: mockUC}).Register(r)
+
+	// user_id подставляется из токена и принимается, если совпадает с ним
+	for _, body := range []string{eventBody(""), eventBody(userID.String())} {
+		req := httptest.NewRequest("POST", "/events", strings.NewReader(body))
+		req.RemoteAddr = "203.0.113.7:51234"
+		req.Header.Set("User-Agent", "Mozilla/5.0")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, withUser(req, userID))
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	mockUC.AssertExpectations(t)
+}
+
+func TestEventsHandler_ForeignUserID(t *testing.T) {
+	mockUC := new(MockEventsUC)
+	r := chi.NewRouter()
+	(&EventsHandler{UC: mockUC}).Register(r)
+
+	// чужой user_id с токеном
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/events", strings.NewReader(eventBody(uuid.NewString())))
+	r.ServeHTTP(w, withUser(req, uuid.New()))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// user_id без токена
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/events", strings.NewReader(eventBody(uuid.NewString()))))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	mockUC.AssertNotCalled(t, "Ingest", mock.Anything, mock.Anything)
+}
+
+func TestEventsHandler_DwellTooLong(t *testing.T) {
+	mockUC := new(MockEventsUC)
+	mockUC.On("Ingest", mock.Anything, mock.Anything).Return(usecase.IngestResult{}, domain.ErrDwellTooLong)
+
+	r := chi.NewRouter()
+	(&EventsHandler{UC: mockUC}).Register(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/events", strings.NewReader(eventBody(""))))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestRealIPMiddleware(t *testing.T) {
+	proxy := netip.MustParsePrefix("10.0.0.0/8")
+	var got string
+	record := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) { got = clientIP(r) })
+	h := RealIPMiddleware([]netip.Prefix{proxy})(record)
+	serve := func(remote string, headers map[string]string) string {
+		req := httptest.NewRequest("POST", "/events", nil)
+		req.RemoteAddr = remote
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		return got
+	}
+
+	// клиент без прокси не может подменить адрес заголовком
+	assert.Equal(t, "203.0.113.7", serve("203.0.113.7:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}))
+	// от прокси берётся самый правый недоверенный адрес: левее — то, что прислал клиент
+	assert.Equal(t, "203.0.113.7", serve("10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.7, 10.0.0.5"}))
+	assert.Equal(t, "203.0.113.9", serve("10.0.0.2:5000", map[string]string{"X-Real-IP": "203.0.113.9"}))
+	assert.Equal(t, "10.0.0.2", serve("10.0.0.2:5000", nil))
+
+	// без TRUSTED_PROXIES заголовки не учитываются
+	h = RealIPMiddleware(nil)(record)
+	assert.Equal(t, "10.0.0.2", serve("10.0.0.2:5000", map[string]string{"X-Real-IP": "203.0.113.9"}))
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ExperimentsHandler управление A/B-экспериментами и их результаты (только для админов)
+type ExperimentsHandler struct {
+	UC usecase.ExperimentsUCInterface
+}
+
+func (h *ExperimentsHandler) Register(r chi.Router) {
+	r.Get("/experiments", h.list)
+	r.Post("/experiments", h.create)
+	r.Get("/experiments/{id}", h.get)
+	r.Post("/experiments/{id}/start", h.start)
+	r.Post("/experiments/{id}/stop", h.stop)
+	r.Get("/experiments/{id}/results", h.results)
+}
+
+type variantIn struct {
+	Name   string            `json:"name"`
+	Weight int               `json:"weight"`
+	Params map[string]string `json:"params"`
+}
+
+type experimentIn struct {
+	Key         string      `json:"key"`
+	Description string      `json:"description"`
+	Surface     string      `json:"surface"`
+	Variants    []variantIn `json:"variants"`
+}
+
+func (h *ExperimentsHandler) list(w http.ResponseWriter, r *http.Request) {
+	experiments, err := h.UC.List(r.Context())
+	if err != nil {
+		writeExperimentError(w, err)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"total":       len(experiments),
+		"experiments": experiments,
+	})
+}
+
+func (h *ExperimentsHandler) create(w http.ResponseWriter, r *http.Request) {
+	var in experimentIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, "bad JSON body", http.StatusBadRequest)
+		return
+	}
+
+	e := domain.Experiment{
+		Key:         in.Key,
+		Description: in.Description,
+		Surface:     domain.ExperimentSurface(in.Surface),
+	}
+	for _, v := range in.Variants {
+		e.Variants = append(e.Variants, domain.Variant{Name: v.Name, Weight: v.Weight, Params: v.Params})
+	}
+
+	created, err := h.UC.Create(r.Context(), e)
+	if err != nil {
+		writeExperimentError(w, err)
+		return
+	}
+	writeJSONStatus(w, http.StatusCreated, created)
+}
+
+func (h *ExperimentsHandler) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := experimentID(w, r)
+	if !ok {
+		return
+	}
+	e, err := h.UC.Get(r.Context(), id)
+	if err != nil {
+		writeExperimentError(w, err)
+		return
+	}
+	writeJSON(w, e)
+}
+
+func (h *ExperimentsHandler) start(w http.ResponseWriter, r *http.Request) {
+	id, ok := experimentID(w, r)
+	if !ok {
+		return
+	}
+	e, err := h.UC.Start(r.Context(), id)
+	if err != nil {
+		writeExperimentError(w, err)
+		return
+	}
+	writeJSON(w, e)
+}
+
+func (h *ExperimentsHandler) stop(w http.ResponseWriter, r *http.Request) {
+	id, ok := experimentID(w, r)
+	if !ok {
+		return
+	}
+	e, err := h.UC.Stop(r.Context(), id)
+	if err != nil {
+		writeExperimentError(w, err)
+		return
+	}
+	writeJSON(w, e)
+}
+
+// results отдаёт CTR, долю досмотров и dwell по вариантам с 95% доверительными интервалами
+func (h *ExperimentsHandler) results(w http.ResponseWriter, r *http.Request) {
+	id, ok := experimentID(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.UC.Results(r.Context(), id)
+	if err != nil {
+		writeExperimentError(w, err)
+		return
+	}
+	writeJSON(w, res)
+}
+
+func experimentID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func writeExperimentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidExperiment):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, domain.ErrExperimentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrExperimentExists),
+		errors.Is(err, domain.ErrExperimentConflict),
+		errors.Is(err, domain.ErrExperimentState):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("experiments error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockExperimentsUC - мок для тестирования
+type MockExperimentsUC struct {
+	mock.Mock
+}
+
+func (m *MockExperimentsUC) Create(ctx context.Context, e domain.Experiment) (domain.Experiment, error) {
+	args := m.Called(ctx, e)
+	return args.Get(0).(domain.Experiment), args.Error(1)
+}
+
+func (m *MockExperimentsUC) List(ctx context.Context) ([]domain.Experiment, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Experiment), args.Error(1)
+}
+
+func (m *MockExperimentsUC) Get(ctx context.Context, id uuid.UUID) (domain.Experiment, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Experiment), args.Error(1)
+}
+
+func (m *MockExperimentsUC) Start(ctx context.Context, id uuid.UUID) (domain.Experiment, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Experiment), args.Error(1)
+}
+
+func (m *MockExperimentsUC) Stop(ctx context.Context, id uuid.UUID) (domain.Experiment, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Experiment), args.Error(1)
+}
+
+func (m *MockExperimentsUC) Results(ctx context.Context, id uuid.UUID) (domain.ExperimentResults, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.ExperimentResults), args.Error(1)
+}
+
+func TestExperimentsHandler_Create(t *testing.T) {
+	body := `{"key":"recs-sources","surface":"recommendations","variants":[
+		{"name":"control","weight":1},
+		{"name":"treatment","weight":1,"params":{"diversity":"0.5"}}]}`
+	in := domain.Experiment{
+		Key:     "recs-sources",
+		Surface: domain.SurfaceRecommendations,
+		Variants: []domain.Variant{
+			{Name: "control", Weight: 1},
+			{Name: "treatment", Weight: 1, Params: map[string]string{"diversity": "0.5"}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		ucErr          error
+		expectedStatus int
+	}{
+		{name: "успешное создание", body: body, expectedStatus: http.StatusCreated},
+		{name: "невалидный эксперимент", body: body, ucErr: domain.ErrInvalidExperiment, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "ключ уже занят", body: body, ucErr: domain.ErrExperimentExists, expectedStatus: http.StatusConflict},
+		{name: "невалидный JSON", body: `{`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUC := new(MockExperimentsUC)
+			if tt.expectedStatus != http.StatusBadRequest {
+				out := in
+				out.ID = uuid.New()
+				mockUC.On("Create", mock.Anything, in).Return(out, tt.ucErr)
+			}
+
+			handler := &ExperimentsHandler{UC: mockUC}
+
+			req := httptest.NewRequest("POST", "/experiments", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.create(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExperimentsHandler_Start(t *testing.T) {
+	id := uuid.New()
+	other := uuid.New()
+
+	mockUC := new(MockExperimentsUC)
+	mockUC.On("Start", mock.Anything, id).Return(domain.Experiment{ID: id, Status: domain.ExperimentRunning}, nil)
+	mockUC.On("Start", mock.Anything, other).Return(domain.Experiment{}, domain.ErrExperimentConflict)
+
+	r := chi.NewRouter()
+	(&ExperimentsHandler{UC: mockUC}).Register(r)
+
+	req := httptest.NewRequest("POST", "/experiments/"+id.String()+"/start", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Status":"running"`)
+
+	// На поверхности уже идёт другой эксперимент -> 409
+	req = httptest.NewRequest("POST", "/experiments/"+other.String()+"/start", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Невалидный id -> 400, UC не вызывается
+	req = httptest.NewRequest("POST", "/experiments/not-a-uuid/start", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUC.AssertExpectations(t)
+}
+
+func TestExperimentsHandler_Results(t *testing.T) {
+	id := uuid.New()
+
+	mockUC := new(MockExperimentsUC)
+	mockUC.On("Results", mock.Anything, id).Return(domain.ExperimentResults{
+		Experiment: domain.Experiment{ID: id},
+		Variants: []domain.VariantResult{
+			{Variant: "control", Units: 100, CTR: domain.WilsonInterval(30, 100)},
+		},
+	}, nil)
+	missing := uuid.New()
+	mockUC.On("Results", mock.Anything, missing).Return(domain.ExperimentResults{}, domain.ErrExperimentNotFound)
+
+	r := chi.NewRouter()
+	(&ExperimentsHandler{UC: mockUC}).Register(r)
+
+	req := httptest.NewRequest("GET", "/experiments/"+id.String()+"/results", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Variant":"control"`)
+
+	req = httptest.NewRequest("GET", "/experiments/"+missing.String()+"/results", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockUC.AssertExpectations(t)
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/export"
+	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/go-chi/chi/v5"
+)
+
+// ExportHandler выгрузки аналитики файлом (только для админов)
+type ExportHandler struct {
+	UC usecase.ExportUCInterface
+	// Slots ограничивает число одновременных выгрузок: каждая держит соединение
+	// пула БД на всё время потока. nil — без ограничения.
+	Slots chan struct{}
+}
+
+func (h *ExportHandler) Register(r chi.Router) {
+	r.Get("/export/{dataset}", h.export)
+}
+
+// export выгрузка набора video_daily, timeseries или events за дни from..to (YYYY-MM-DD)
+func (h *ExportHandler) export(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, to, ok := parseStatsDays(w, r)
+	if !ok {
+		return
+	}
+	p := domain.ExportParams{
+		Dataset: domain.ExportDataset(chi.URLParam(r, "dataset")),
+		Format:  domain.ExportFormat(q.Get("format")),
+		From:    from,
+		To:      to,
+		Columns: domain.ParseExportColumns(q.Get("columns")),
+		Gzip:    q.Get("gzip") == "true",
+		Timeseries: domain.TimeseriesParams{
+			Granularity: domain.StatsGranularity(q.Get("granularity")),
+			Breakdown:   domain.StatsBreakdown(q.Get("breakdown")),
+		},
+	}
+	if p.Format == "" {
+		p.Format = domain.ExportCSV
+	}
+	if p.Timeseries.Granularity == "" {
+		p.Timeseries.Granularity = domain.GranularityDay
+	}
+	// top <= 0 или невалидный — значение по умолчанию (см. TimeseriesParams.Validate)
+	p.Timeseries.Top, _ = strconv.Atoi(q.Get("top"))
+
+	// Проверяем до заголовков ответа: после начала потока статус уже не поменять
+	if err := p.Validate(); err != nil {
+		writeExportError(w, err)
+		return
+	}
+
+	if h.Slots != nil {
+		select {
+		case h.Slots <- struct{}{}:
+			defer func() { <-h.Slots }()
+		default:
+			w.Header().Set("Retry-After", "30")
+			http.Error(w, "too many exports in progress", http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	name := fmt.Sprintf("%s_%s_%s.%s", p.Dataset, p.From.Format(time.DateOnly), p.To.Format(time.DateOnly), p.Format)
+	contentType := export.ContentType(p.Format)
+	if p.Gzip {
+		name += ".gz"
+		contentType = "application/gzip"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+
+	// Большая выгрузка пишется дольше WriteTimeout сервера; отмена — по закрытию соединения
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	out := &exportWriter{ResponseWriter: w}
+	if err := h.UC.Export(r.Context(), p, out); err != nil {
+		if !out.wrote {
+			// Ничего не отправлено: ещё можно ответить ошибкой вместо обрыва
+			w.Header().Del("Content-Disposition")
+			writeExportError(w, err)
+			return
+		}
+		// Заголовки уже отправлены: обрываем ответ, клиент получит неполный файл
+		log.Printf("export %s error: %v", p.Dataset, err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// exportWriter запоминает, начался ли ответ
+type exportWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *exportWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
+
+func writeExportError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUnknownExportDataset):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrInvalidExport),
+		errors.Is(err, domain.ErrInvalidStatsQuery),
+		errors.Is(err, domain.ErrStatsRangeTooLarge):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("export error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+	}
+}
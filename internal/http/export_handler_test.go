@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockExportUC - мок для тестирования
+type MockExportUC struct {
+	mock.Mock
+}
+
+func (m *MockExportUC) Export(ctx context.Context, p domain.ExportParams, w io.Writer) error {
+	args := m.Called(ctx, p, w)
+	return args.Error(0)
+}
+
+func TestExportHandler_Export(t *testing.T) {
+	mockUC := new(MockExportUC)
+	mockUC.On("Export", mock.Anything, mock.MatchedBy(func(p domain.ExportParams) bool {
+		return p.Dataset == domain.ExportEvents && p.Format == domain.ExportNDJSON && p.Gzip &&
+			p.From.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) &&
+			assert.ObjectsAreEqual([]string{"ts", "type"}, p.Columns)
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		_, _ = args.Get(2).(io.Writer).Write([]byte("data"))
+	}).Return(nil)
+
+	r := chi.NewRouter()
+	(&ExportHandler{UC: mockUC}).Register(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET",
+		"/export/events?format=ndjson&from=2024-03-01&to=2024-03-02&columns=ts,type&gzip=true", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/gzip", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="events_2024-03-01_2024-03-02.ndjson.gz"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "data", w.Body.String())
+
+	mockUC.AssertExpectations(t)
+}
+
+func TestExportHandler_InvalidParams(t *testing.T) {
+	mockUC := new(MockExportUC)
+	r := chi.NewRouter()
+	(&ExportHandler{UC: mockUC}).Register(r)
+
+	cases := map[string]int{
+		"/export/users":                                http.StatusNotFound,
+		"/export/events?format=xlsx":                   http.StatusBadRequest,
+		"/export/video_daily?columns=views,bogus":      http.StatusBadRequest,
+		"/export/events?from=2023-01-01&to=2024-06-01": http.StatusBadRequest,
+		"/export/timeseries?granularity=minute":        http.StatusBadRequest,
+	}
+	for url, code := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		assert.Equal(t, code, w.Code, url)
+	}
+	// UC не вызывается при невалидных параметрах
+	mockUC.AssertNotCalled(t, "Export", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExportHandler_ErrorBeforeWrite(t *testing.T) {
+	mockUC := new(MockExportUC)
+	mockUC.On("Export", mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError)
+
+	r := chi.NewRouter()
+	(&ExportHandler{UC: mockUC}).Register(r)
+
+	// Ошибка до первого байта — обычный 500, а не оборванное соединение
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/export/events?from=2024-03-01&to=2024-03-02", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+}
+
+func TestExportHandler_ConcurrencyLimit(t *testing.T) {
+	mockUC := new(MockExportUC)
+	slots := make(chan struct{}, 1)
+	r := chi.NewRouter()
+	(&ExportHandler{UC: mockUC, Slots: slots}).Register(r)
+
+	// Слот занят другой выгрузкой
+	slots <- struct{}{}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/export/events?from=2024-03-01&to=2024-03-02", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	mockUC.AssertNotCalled(t, "Export", mock.Anything, mock.Anything, mock.Anything)
+
+	// Освободившийся слот снова доступен и возвращается после выгрузки
+	<-slots
+	mockUC.On("Export", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/export/events?from=2024-03-01&to=2024-03-02", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, slots)
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -9,10 +10,12 @@ import (
 	"github.com/arasvet/microtube/internal/domain"
 	"github.com/arasvet/microtube/internal/usecase"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type FeedHandler struct {
-	UC usecase.FeedUCInterface
+	UC     usecase.FeedUCInterface
+	Serves usecase.ServeLogger
 }
 
 func (h *FeedHandler) Register(r chi.Router) {
@@ -35,14 +38,36 @@ func (h *FeedHandler) getFeed(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Создаем параметры фида
+	// Создаем параметры фида (window учитывается только для trending)
 	params := domain.FeedParams{
-		Type:  domain.FeedType(feedType),
-		Limit: limit,
+		Type:      domain.FeedType(feedType),
+		Limit:     limit,
+		Window:    domain.TrendingWindow(r.URL.Query().Get("window")),
+		SessionID: r.URL.Query().Get("session_id"),
+	}
+
+	// Пользователь из JWT: для фида подписок обязателен, в остальных фидах
+	// по нему убираются скрытые видео и понижаются нелюбимые теги
+	if sub, ok := UserIDFromContext(r); ok {
+		params.UserID, _ = uuid.Parse(sub)
+	}
+
+	// Фид подписок пагинируется курсором
+	if params.Type == domain.FeedTypeSubscriptions {
+		cursor, err := domain.DecodeCursor(r.URL.Query().Get("cursor"))
+		if err != nil {
+			http.Error(w, "неверный cursor", http.StatusBadRequest)
+			return
+		}
+		params.Cursor = cursor
 	}
 
 	// Валидируем параметры (тип будет исправлен на popular если неверный)
 	if err := params.Validate(); err != nil {
+		if errors.Is(err, domain.ErrAuthRequired) {
+			http.Error(w, "требуется авторизация", http.StatusUnauthorized)
+			return
+		}
 		log.Printf("ошибка валидации параметров фида: %v", err)
 		http.Error(w, "неверные параметры", http.StatusBadRequest)
 		return
@@ -63,6 +88,34 @@ func (h *FeedHandler) getFeed(w http.ResponseWriter, r *http.Request) {
 		"total":  len(videos),
 		"videos": videos,
 	}
+	if params.Type == domain.FeedTypeSubscriptions {
+		nextCursor := ""
+		if len(videos) == params.Limit {
+			last := videos[len(videos)-1]
+			nextCursor = domain.Cursor{TS: last.UploadedAt, ID: last.ID}.Encode()
+		}
+		response["next_cursor"] = nextCursor
+	}
+	// Логируем показы; serve_id клиент возвращает в событии view_start
+	serve := domain.Serve{
+		Surface:   domain.SurfaceFeed,
+		UserID:    params.UserID,
+		SessionID: params.SessionID,
+		Items:     make([]domain.Impression, 0, len(videos)),
+	}
+	for i, v := range videos {
+		serve.Items = append(serve.Items, domain.Impression{VideoID: v.ID, Position: i + 1, Source: string(params.Type)})
+	}
+	if serveID := logServe(h.Serves, r, serve); serveID != "" {
+		response["serve_id"] = serveID
+	}
+	if params.Type == domain.FeedTypeTrending {
+		window := string(params.Window)
+		if window == "" {
+			window = "blended"
+		}
+		response["window"] = window
+	}
 
 	// Отправляем JSON ответ
 	w.Header().Set("Content-Type", "application/json")
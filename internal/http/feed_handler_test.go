@@ -66,6 +66,14 @@ func TestFeedHandler_GetFeed(t *testing.T) {
 			expectedType:   "random",
 			expectedLimit:  5,
 		},
+		{
+			name:           "трендовые видео",
+			feedType:       "trending",
+			limit:          "10",
+			expectedStatus: http.StatusOK,
+			expectedType:   "trending",
+			expectedLimit:  10,
+		},
 		{
 			name:           "неверный limit (используется значение по умолчанию)",
 			feedType:       "popular",
@@ -140,6 +148,87 @@ func TestFeedHandler_GetFeed(t *testing.T) {
 	}
 }
 
+func TestFeedHandler_GetFeed_TrendingWindow(t *testing.T) {
+	mockUC := new(MockFeedUC)
+
+	// Окно передаётся в usecase только для trending
+	mockUC.On("GetFeed", mock.Anything, domain.FeedParams{
+		Type:   domain.FeedTypeTrending,
+		Limit:  20,
+		Window: domain.TrendingWindowHour,
+	}).Return([]domain.Video{}, nil)
+	mockUC.On("GetFeed", mock.Anything, domain.FeedParams{
+		Type:  domain.FeedTypePopular,
+		Limit: 20,
+	}).Return([]domain.Video{}, nil)
+
+	handler := &FeedHandler{UC: mockUC}
+
+	req := httptest.NewRequest("GET", "/videos/feed?type=trending&window=hour", nil)
+	w := httptest.NewRecorder()
+	handler.getFeed(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"window":"hour"`)
+
+	req = httptest.NewRequest("GET", "/videos/feed?type=popular&window=hour", nil)
+	w = httptest.NewRecorder()
+	handler.getFeed(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"window"`)
+
+	mockUC.AssertExpectations(t)
+}
+
+func TestFeedHandler_GetFeed_Subscriptions(t *testing.T) {
+	userID := uuid.New()
+	uploaded := time.Now().UTC()
+	lastID := uuid.New()
+
+	mockUC := new(MockFeedUC)
+	mockUC.On("GetFeed", mock.Anything, domain.FeedParams{
+		Type:   domain.FeedTypeSubscriptions,
+		Limit:  1,
+		UserID: userID,
+	}).Return([]domain.Video{{ID: lastID, UploadedAt: uploaded}}, nil)
+
+	handler := &FeedHandler{UC: mockUC}
+
+	// Без авторизации -> 401, usecase не вызывается
+	req := httptest.NewRequest("GET", "/videos/feed?type=subscriptions", nil)
+	w := httptest.NewRecorder()
+	handler.getFeed(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// С пользователем — полная страница, возвращается курсор на последнее видео
+	req = withUser(httptest.NewRequest("GET", "/videos/feed?type=subscriptions&limit=1", nil), userID)
+	w = httptest.NewRecorder()
+	handler.getFeed(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), domain.Cursor{TS: uploaded, ID: lastID}.Encode())
+
+	mockUC.AssertExpectations(t)
+}
+
+func TestFeedHandler_GetFeed_PassesUserForPersonalization(t *testing.T) {
+	// Авторизованный пользователь передаётся и в обычные фиды — для фильтрации скрытых видео
+	userID := uuid.New()
+	mockUC := new(MockFeedUC)
+	mockUC.On("GetFeed", mock.Anything, domain.FeedParams{
+		Type:   domain.FeedTypePopular,
+		Limit:  20,
+		UserID: userID,
+	}).Return([]domain.Video{}, nil)
+
+	handler := &FeedHandler{UC: mockUC}
+	req := withUser(httptest.NewRequest("GET", "/videos/feed?type=popular", nil), userID)
+	w := httptest.NewRecorder()
+	handler.getFeed(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "next_cursor")
+	mockUC.AssertExpectations(t)
+}
+
 func TestFeedHandler_GetFeed_Error(t *testing.T) {
 	// Создаем мок
 	mockUC := new(MockFeedUC)
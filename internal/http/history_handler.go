@@ -0,0 +1,165 @@
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// HistoryHandler история просмотров текущего пользователя
+type HistoryHandler struct {
+	UC usecase.HistoryUCInterface
+}
+
+func (h *HistoryHandler) Register(r chi.Router) {
+	r.Get("/me/history", h.history)
+	r.Delete("/me/history", h.clear)
+	r.Delete("/me/history/{video_id}", h.deleteItem)
+	r.Get("/me/history/settings", h.settings)
+	r.Put("/me/history/settings", h.updateSettings)
+	r.Get("/me/continue-watching", h.continueWatching)
+}
+
+type historySettingsIn struct {
+	Paused bool `json:"paused"`
+}
+
+func (h *HistoryHandler) history(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, err := domain.DecodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		http.Error(w, "invalid cursor", http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.UC.History(r.Context(), domain.HistoryParams{
+		UserID: userID,
+		Cursor: cursor,
+		Limit:  parseLimitParam(r, 20),
+	})
+	if err != nil {
+		log.Printf("history error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"total":       len(page.Items),
+		"items":       page.Items,
+		"next_cursor": page.NextCursor,
+	})
+}
+
+func (h *HistoryHandler) continueWatching(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.UC.ContinueWatching(r.Context(), userID, parseLimitParam(r, 20))
+	if err != nil {
+		log.Printf("continue watching error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"total": len(items),
+		"items": items,
+	})
+}
+
+func (h *HistoryHandler) clear(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.UC.Delete(r.Context(), userID, nil)
+	if err != nil {
+		log.Printf("history clear error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]int64{"deleted": deleted})
+}
+
+func (h *HistoryHandler) deleteItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	videoID, err := uuid.Parse(chi.URLParam(r, "video_id"))
+	if err != nil {
+		http.Error(w, "invalid video_id", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.UC.Delete(r.Context(), userID, &videoID)
+	if err != nil {
+		log.Printf("history delete error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *HistoryHandler) settings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	s, err := h.UC.Settings(r.Context(), userID)
+	if err != nil {
+		log.Printf("history settings error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, s)
+}
+
+func (h *HistoryHandler) updateSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var in historySettingsIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, "bad JSON body", http.StatusBadRequest)
+		return
+	}
+
+	s, err := h.UC.SetPaused(r.Context(), userID, in.Paused)
+	if err != nil {
+		log.Printf("history settings error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, s)
+}
+
+// parseLimitParam разбирает query-параметр limit; невалидное значение заменяется значением по умолчанию
+func parseLimitParam(r *http.Request, def int) int {
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			return l
+		}
+	}
+	return def
+}
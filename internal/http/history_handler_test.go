@@ -0,0 +1,109 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockHistoryUC - мок для тестирования
+type MockHistoryUC struct {
+	mock.Mock
+}
+
+func (m *MockHistoryUC) History(ctx context.Context, params domain.HistoryParams) (domain.HistoryPage, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(domain.HistoryPage), args.Error(1)
+}
+
+func (m *MockHistoryUC) ContinueWatching(ctx context.Context, userID uuid.UUID, limit int) ([]domain.HistoryItem, error) {
+	args := m.Called(ctx, userID, limit)
+	return args.Get(0).([]domain.HistoryItem), args.Error(1)
+}
+
+func (m *MockHistoryUC) Delete(ctx context.Context, userID uuid.UUID, videoID *uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID, videoID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockHistoryUC) Settings(ctx context.Context, userID uuid.UUID) (domain.HistorySettings, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(domain.HistorySettings), args.Error(1)
+}
+
+func (m *MockHistoryUC) SetPaused(ctx context.Context, userID uuid.UUID, paused bool) (domain.HistorySettings, error) {
+	args := m.Called(ctx, userID, paused)
+	return args.Get(0).(domain.HistorySettings), args.Error(1)
+}
+
+func TestHistoryHandler_RequiresAuth(t *testing.T) {
+	mockUC := new(MockHistoryUC)
+	r := chi.NewRouter()
+	(&HistoryHandler{UC: mockUC}).Register(r)
+
+	for _, path := range []string{"/me/history", "/me/continue-watching", "/me/history/settings"} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+	}
+	mockUC.AssertNotCalled(t, "History", mock.Anything, mock.Anything)
+}
+
+func TestHistoryHandler_ContinueWatching(t *testing.T) {
+	userID := uuid.New()
+	item := domain.HistoryItem{
+		Video:     domain.Video{ID: uuid.New(), DurationS: 200},
+		WatchedMs: 50_000,
+	}
+	item.FillResume()
+
+	mockUC := new(MockHistoryUC)
+	mockUC.On("ContinueWatching", mock.Anything, userID, 5).Return([]domain.HistoryItem{item}, nil)
+
+	r := chi.NewRouter()
+	(&HistoryHandler{UC: mockUC}).Register(r)
+
+	req := withUser(httptest.NewRequest("GET", "/me/continue-watching?limit=5", nil), userID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"ResumePosition":50`)
+	assert.Contains(t, w.Body.String(), `"Progress":0.25`)
+	mockUC.AssertExpectations(t)
+}
+
+func TestHistoryHandler_DeleteItem(t *testing.T) {
+	userID := uuid.New()
+	videoID := uuid.New()
+
+	mockUC := new(MockHistoryUC)
+	mockUC.On("Delete", mock.Anything, userID, &videoID).Return(int64(0), nil)
+	mockUC.On("Delete", mock.Anything, userID, (*uuid.UUID)(nil)).Return(int64(3), nil)
+
+	r := chi.NewRouter()
+	(&HistoryHandler{UC: mockUC}).Register(r)
+
+	// Видео нет в истории -> 404
+	req := withUser(httptest.NewRequest("DELETE", "/me/history/"+videoID.String(), nil), userID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Очистка всей истории возвращает число удалённых записей
+	req = withUser(httptest.NewRequest("DELETE", "/me/history", nil), userID)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"deleted":3`)
+
+	mockUC.AssertExpectations(t)
+}
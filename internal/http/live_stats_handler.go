@@ -0,0 +1,62 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/go-chi/chi/v5"
+)
+
+// LiveStatsHandler live-статистика по Server-Sent Events (только для админов)
+type LiveStatsHandler struct {
+	UC usecase.LiveStatsUCInterface
+}
+
+func (h *LiveStatsHandler) Register(r chi.Router) {
+	r.Get("/stats/live", h.stream)
+}
+
+// stream снимок live-статистики раз в секунду событием stats, пока клиент не отключится
+func (h *LiveStatsHandler) stream(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// Поток бессрочный: снимаем WriteTimeout сервера для этого ответа
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	snapshots, cancel := h.UC.Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx не должен буферизовать поток
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Printf("live stats: streaming unsupported: %v", err)
+		return
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case snap, ok := <-snapshots:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(snap)
+			if err != nil {
+				log.Printf("live stats: %v", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: stats\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLiveStatsUC - мок для тестирования
+type MockLiveStatsUC struct {
+	mock.Mock
+}
+
+func (m *MockLiveStatsUC) Subscribe() (<-chan domain.LiveSnapshot, func()) {
+	args := m.Called()
+	return args.Get(0).(<-chan domain.LiveSnapshot), args.Get(1).(func())
+}
+
+func TestLiveStatsHandler_Stream(t *testing.T) {
+	snapshots := make(chan domain.LiveSnapshot, 1)
+	snapshots <- domain.LiveSnapshot{EventsPerSec: 2.5, Viewers: 7}
+	unsubscribed := false
+
+	mockUC := new(MockLiveStatsUC)
+	mockUC.On("Subscribe").Return((<-chan domain.LiveSnapshot)(snapshots), func() { unsubscribed = true })
+
+	r := chi.NewRouter()
+	(&LiveStatsHandler{UC: mockUC}).Register(r)
+
+	// Канал закрыт после снимка: обработчик отдаёт снимок и завершается
+	close(snapshots)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/stats/live", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "event: stats\ndata: {")
+	assert.Contains(t, w.Body.String(), `"EventsPerSec":2.5`)
+	assert.Contains(t, w.Body.String(), `"Viewers":7`)
+	assert.True(t, unsubscribed)
+	mockUC.AssertExpectations(t)
+}
@@ -8,24 +8,74 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/netip"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
 )
 
 type ctxKey string
 
 const userIDCtxKey ctxKey = "user_id"
 
-func SetupMiddleware(r chi.Router, jwtSecret []byte) {
+func SetupMiddleware(r chi.Router, jwtSecret []byte, trustedProxies []netip.Prefix) {
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(RealIPMiddleware(trustedProxies))
 	r.Use(middleware.Recoverer)
 	r.Use(JWTAuthMiddleware(jwtSecret))
 }
 
+// RealIPMiddleware подставляет в RemoteAddr адрес клиента из X-Forwarded-For или X-Real-IP,
+// только если запрос пришёл от доверенного прокси: иначе заголовки выбирает сам клиент
+// и обходит антифрод по IP. В X-Forwarded-For берётся самый правый адрес не из trusted —
+// левее него значения дописаны до наших прокси и тоже могут быть подделаны.
+func RealIPMiddleware(trusted []netip.Prefix) func(next http.Handler) http.Handler {
+	isTrusted := func(addr netip.Addr) bool {
+		for _, p := range trusted {
+			if p.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(trusted) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			peer, err := netip.ParseAddrPort(r.RemoteAddr)
+			if err != nil || !isTrusted(peer.Addr()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var client netip.Addr
+			hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+			for i := len(hops) - 1; i >= 0; i-- {
+				addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+				if err != nil {
+					break
+				}
+				client = addr
+				if !isTrusted(addr) {
+					break
+				}
+			}
+			if !client.IsValid() {
+				client, _ = netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+			}
+			if client.IsValid() {
+				r.RemoteAddr = netip.AddrPortFrom(client.Unmap(), 0).String()
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // JWTAuthMiddleware проверяет заголовок Authorization: Bearer <jwt>
 // Валидирует подпись HS256 и кладёт subject (sub) в контекст как user_id.
 func JWTAuthMiddleware(secret []byte) func(next http.Handler) http.Handler {
@@ -121,3 +171,18 @@ func UserIDFromContext(r *http.Request) (string, bool) {
 	id, ok := v.(string)
 	return id, ok && id != ""
 }
+
+// requireUserID возвращает user_id авторизованного пользователя или отвечает 401
+func requireUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	sub, ok := UserIDFromContext(r)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(sub)
+	if err != nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,321 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// PlaylistsHandler плейлисты и список "смотреть позже"
+type PlaylistsHandler struct {
+	UC usecase.PlaylistsUCInterface
+}
+
+func (h *PlaylistsHandler) Register(r chi.Router) {
+	r.Post("/playlists", h.create)
+	r.Get("/playlists/{id}", h.get)
+	r.Patch("/playlists/{id}", h.update)
+	r.Delete("/playlists/{id}", h.delete)
+	r.Post("/playlists/{id}/items", h.addItem)
+	r.Patch("/playlists/{id}/items/{video_id}", h.moveItem)
+	r.Delete("/playlists/{id}/items/{video_id}", h.removeItem)
+	r.Get("/me/playlists", h.mine)
+	r.Get("/authors/{id}/playlists", h.authorPlaylists)
+	r.Get("/me/watch-later", h.watchLater)
+	r.Post("/me/watch-later", h.addToWatchLater)
+	r.Delete("/me/watch-later/{video_id}", h.removeFromWatchLater)
+}
+
+type playlistIn struct {
+	Title      *string                    `json:"title"`
+	Visibility *domain.PlaylistVisibility `json:"visibility"`
+}
+
+type playlistItemIn struct {
+	VideoID  string `json:"video_id"`
+	Position *int   `json:"position"`
+}
+
+func (h *PlaylistsHandler) create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var in playlistIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, "bad JSON body", http.StatusBadRequest)
+		return
+	}
+	var title string
+	if in.Title != nil {
+		title = *in.Title
+	}
+	var visibility domain.PlaylistVisibility
+	if in.Visibility != nil {
+		visibility = *in.Visibility
+	}
+
+	p, err := h.UC.Create(r.Context(), userID, title, visibility)
+	if err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	writeJSONStatus(w, http.StatusCreated, p)
+}
+
+// get отдаёт плейлист с видео; публичные и unlisted доступны и гостям
+func (h *PlaylistsHandler) get(w http.ResponseWriter, r *http.Request) {
+	playlistID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid playlist id", http.StatusBadRequest)
+		return
+	}
+	var viewerID uuid.UUID
+	if sub, ok := UserIDFromContext(r); ok {
+		viewerID, _ = uuid.Parse(sub)
+	}
+
+	p, err := h.UC.Get(r.Context(), viewerID, playlistID)
+	if err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	writeJSON(w, p)
+}
+
+func (h *PlaylistsHandler) update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	playlistID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid playlist id", http.StatusBadRequest)
+		return
+	}
+
+	var in playlistIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, "bad JSON body", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.UC.Update(r.Context(), userID, playlistID, domain.PlaylistUpdate{
+		Title:      in.Title,
+		Visibility: in.Visibility,
+	})
+	if err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	writeJSON(w, p)
+}
+
+func (h *PlaylistsHandler) delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	playlistID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid playlist id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.UC.Delete(r.Context(), userID, playlistID); err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addItem добавляет видео в плейлист; без position — в конец
+func (h *PlaylistsHandler) addItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	playlistID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid playlist id", http.StatusBadRequest)
+		return
+	}
+
+	var in playlistItemIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, "bad JSON body", http.StatusBadRequest)
+		return
+	}
+	videoID, err := uuid.Parse(in.VideoID)
+	if err != nil {
+		http.Error(w, "invalid video_id", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := h.UC.AddItem(r.Context(), userID, playlistID, videoID, in.Position); err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// moveItem переносит видео плейлиста на новую позицию
+func (h *PlaylistsHandler) moveItem(w http.ResponseWriter, r *http.Request) {
+	h.withItem(w, r, func(ctx context.Context, userID, playlistID, videoID uuid.UUID) error {
+		var in playlistItemIn
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.Position == nil {
+			return errBadPlaylistItem
+		}
+		return h.UC.MoveItem(ctx, userID, playlistID, videoID, *in.Position)
+	})
+}
+
+func (h *PlaylistsHandler) removeItem(w http.ResponseWriter, r *http.Request) {
+	h.withItem(w, r, h.UC.RemoveItem)
+}
+
+func (h *PlaylistsHandler) mine(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	playlists, err := h.UC.Mine(r.Context(), userID)
+	if err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"total":     len(playlists),
+		"playlists": playlists,
+	})
+}
+
+// authorPlaylists отдаёт публичные плейлисты автора
+func (h *PlaylistsHandler) authorPlaylists(w http.ResponseWriter, r *http.Request) {
+	authorID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid author id", http.StatusBadRequest)
+		return
+	}
+
+	playlists, err := h.UC.AuthorPlaylists(r.Context(), authorID)
+	if err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"total":     len(playlists),
+		"playlists": playlists,
+	})
+}
+
+func (h *PlaylistsHandler) watchLater(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := h.UC.WatchLater(r.Context(), userID)
+	if err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	writeJSON(w, p)
+}
+
+func (h *PlaylistsHandler) addToWatchLater(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var in playlistItemIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, "bad JSON body", http.StatusBadRequest)
+		return
+	}
+	videoID, err := uuid.Parse(in.VideoID)
+	if err != nil {
+		http.Error(w, "invalid video_id", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := h.UC.AddToWatchLater(r.Context(), userID, videoID); err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PlaylistsHandler) removeFromWatchLater(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	videoID, err := uuid.Parse(chi.URLParam(r, "video_id"))
+	if err != nil {
+		http.Error(w, "invalid video_id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.UC.RemoveFromWatchLater(r.Context(), userID, videoID); err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var errBadPlaylistItem = errors.New("body must be JSON with integer position")
+
+// withItem общий разбор для действий над видео плейлиста без тела ответа
+func (h *PlaylistsHandler) withItem(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, userID, playlistID, videoID uuid.UUID) error) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	playlistID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid playlist id", http.StatusBadRequest)
+		return
+	}
+	videoID, err := uuid.Parse(chi.URLParam(r, "video_id"))
+	if err != nil {
+		http.Error(w, "invalid video_id", http.StatusBadRequest)
+		return
+	}
+
+	if err := action(r.Context(), userID, playlistID, videoID); err != nil {
+		writePlaylistError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writePlaylistError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errBadPlaylistItem):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrInvalidPlaylist):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, domain.ErrPlaylistNotFound), errors.Is(err, domain.ErrPlaylistItemNotFound),
+		errors.Is(err, domain.ErrVideoNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrPlaylistForbidden), errors.Is(err, domain.ErrWatchLaterReadOnly):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, domain.ErrPlaylistItemExists), errors.Is(err, domain.ErrPlaylistFull):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("playlists error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPlaylistsUC - мок для тестирования
+type MockPlaylistsUC struct {
+	mock.Mock
+}
+
+func (m *MockPlaylistsUC) Create(ctx context.Context, ownerID uuid.UUID, title string, visibility domain.PlaylistVisibility) (domain.Playlist, error) {
+	args := m.Called(ctx, ownerID, title, visibility)
+	return args.Get(0).(domain.Playlist), args.Error(1)
+}
+
+func (m *MockPlaylistsUC) Get(ctx context.Context, viewerID, playlistID uuid.UUID) (domain.PlaylistWithItems, error) {
+	args := m.Called(ctx, viewerID, playlistID)
+	return args.Get(0).(domain.PlaylistWithItems), args.Error(1)
+}
+
+func (m *MockPlaylistsUC) Mine(ctx context.Context, ownerID uuid.UUID) ([]domain.Playlist, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).([]domain.Playlist), args.Error(1)
+}
+
+func (m *MockPlaylistsUC) AuthorPlaylists(ctx context.Context, authorID uuid.UUID) ([]domain.Playlist, error) {
+	args := m.Called(ctx, authorID)
+	return args.Get(0).([]domain.Playlist), args.Error(1)
+}
+
+func (m *MockPlaylistsUC) Update(ctx context.Context, ownerID, playlistID uuid.UUID, upd domain.PlaylistUpdate) (domain.Playlist, error) {
+	args := m.Called(ctx, ownerID, playlistID, upd)
+	return args.Get(0).(domain.Playlist), args.Error(1)
+}
+
+func (m *MockPlaylistsUC) Delete(ctx context.Context, ownerID, playlistID uuid.UUID) error {
+	return m.Called(ctx, ownerID, playlistID).Error(0)
+}
+
+func (m *MockPlaylistsUC) AddItem(ctx context.Context, ownerID, playlistID, videoID uuid.UUID, position *int) error {
+	return m.Called(ctx, ownerID, playlistID, videoID, position).Error(0)
+}
+
+func (m *MockPlaylistsUC) MoveItem(ctx context.Context, ownerID, playlistID, videoID uuid.UUID, position int) error {
+	return m.Called(ctx, ownerID, playlistID, videoID, position).Error(0)
+}
+
+func (m *MockPlaylistsUC) RemoveItem(ctx context.Context, ownerID, playlistID, videoID uuid.UUID) error {
+	return m.Called(ctx, ownerID, playlistID, videoID).Error(0)
+}
+
+func (m *MockPlaylistsUC) WatchLater(ctx context.Context, ownerID uuid.UUID) (domain.PlaylistWithItems, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).(domain.PlaylistWithItems), args.Error(1)
+}
+
+func (m *MockPlaylistsUC) AddToWatchLater(ctx context.Context, ownerID, videoID uuid.UUID) error {
+	return m.Called(ctx, ownerID, videoID).Error(0)
+}
+
+func (m *MockPlaylistsUC) RemoveFromWatchLater(ctx context.Context, ownerID, videoID uuid.UUID) error {
+	return m.Called(ctx, ownerID, videoID).Error(0)
+}
+
+func newPlaylistsRouter(uc *MockPlaylistsUC) chi.Router {
+	r := chi.NewRouter()
+	(&PlaylistsHandler{UC: uc}).Register(r)
+	return r
+}
+
+func TestPlaylistsHandler_GetAsGuest(t *testing.T) {
+	playlistID := uuid.New()
+	mockUC := new(MockPlaylistsUC)
+	mockUC.On("Get", mock.Anything, uuid.Nil, playlistID).Return(domain.PlaylistWithItems{
+		Playlist: domain.Playlist{ID: playlistID, Title: "Go talks", Visibility: domain.PlaylistUnlisted},
+		Items:    []domain.PlaylistItem{},
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/playlists/"+playlistID.String(), nil)
+	w := httptest.NewRecorder()
+	newPlaylistsRouter(mockUC).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Go talks")
+	mockUC.AssertExpectations(t)
+}
+
+func TestPlaylistsHandler_GetPrivateNotFound(t *testing.T) {
+	playlistID := uuid.New()
+	mockUC := new(MockPlaylistsUC)
+	mockUC.On("Get", mock.Anything, uuid.Nil, playlistID).Return(domain.PlaylistWithItems{}, domain.ErrPlaylistNotFound)
+
+	req := httptest.NewRequest("GET", "/playlists/"+playlistID.String(), nil)
+	w := httptest.NewRecorder()
+	newPlaylistsRouter(mockUC).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPlaylistsHandler_AddItem(t *testing.T) {
+	userID, playlistID, videoID := uuid.New(), uuid.New(), uuid.New()
+	position := 2
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "успешная вставка", expectedStatus: http.StatusNoContent},
+		{name: "плейлист заполнен", err: domain.ErrPlaylistFull, expectedStatus: http.StatusConflict},
+		{name: "чужой плейлист", err: domain.ErrPlaylistForbidden, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUC := new(MockPlaylistsUC)
+			mockUC.On("AddItem", mock.Anything, userID, playlistID, videoID, &position).Return(tt.err)
+
+			body := `{"video_id":"` + videoID.String() + `","position":2}`
+			req := httptest.NewRequest("POST", "/playlists/"+playlistID.String()+"/items", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			newPlaylistsRouter(mockUC).ServeHTTP(w, withUser(req, userID))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPlaylistsHandler_MoveItemRequiresPosition(t *testing.T) {
+	mockUC := new(MockPlaylistsUC)
+
+	req := httptest.NewRequest("PATCH", "/playlists/"+uuid.NewString()+"/items/"+uuid.NewString(), strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	newPlaylistsRouter(mockUC).ServeHTTP(w, withUser(req, uuid.New()))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUC.AssertNotCalled(t, "MoveItem", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPlaylistsHandler_WatchLaterRequiresAuth(t *testing.T) {
+	mockUC := new(MockPlaylistsUC)
+
+	req := httptest.NewRequest("GET", "/me/watch-later", nil)
+	w := httptest.NewRecorder()
+	newPlaylistsRouter(mockUC).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -9,14 +10,17 @@ import (
 	"github.com/arasvet/microtube/internal/domain"
 	"github.com/arasvet/microtube/internal/usecase"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type RecommendationsHandler struct {
-	UC usecase.RecommendationsUCInterface
+	UC     usecase.RecommendationsUCInterface
+	Serves usecase.ServeLogger
 }
 
 func (h *RecommendationsHandler) Register(r chi.Router) {
 	r.Get("/recommendations", h.getRecommendations)
+	r.Get("/videos/{id}/related", h.getRelated)
 }
 
 // getRecommendations обрабатывает GET запрос для получения рекомендаций
@@ -45,6 +49,48 @@ func (h *RecommendationsHandler) getRecommendations(w http.ResponseWriter, r *ht
 		params.SessionID = &sessionID
 	}
 
+	// Баланс релевантности и разнообразия (MMR)
+	if d := r.URL.Query().Get("diversity"); d != "" {
+		diversity, err := strconv.ParseFloat(d, 64)
+		if err != nil || diversity < 0 || diversity > 1 {
+			http.Error(w, domain.ErrInvalidDiversity.Error(), http.StatusBadRequest)
+			return
+		}
+		params.Diversity = &diversity
+	}
+
+	// Слагаемые score в объяснениях — только для админов
+	if r.URL.Query().Get("debug") == "true" {
+		if !isAdmin(r) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		params.Debug = true
+	}
+
+	// Автоплей плейлиста: следующие видео плейлиста идут первыми
+	if playlist := r.URL.Query().Get("playlist_id"); playlist != "" {
+		playlistID, err := uuid.Parse(playlist)
+		if err != nil {
+			http.Error(w, "invalid playlist_id", http.StatusBadRequest)
+			return
+		}
+		params.PlaylistID = &playlistID
+
+		if video := r.URL.Query().Get("video_id"); video != "" {
+			videoID, err := uuid.Parse(video)
+			if err != nil {
+				http.Error(w, "invalid video_id", http.StatusBadRequest)
+				return
+			}
+			params.VideoID = &videoID
+		}
+	}
+	if sub, ok := UserIDFromContext(r); ok {
+		params.ViewerID, _ = uuid.Parse(sub)
+		params.Admin = isAdmin(r)
+	}
+
 	// Получаем рекомендации
 	results, err := h.UC.GetRecommendations(r.Context(), params)
 	if err != nil {
@@ -71,6 +117,24 @@ func (h *RecommendationsHandler) getRecommendations(w http.ResponseWriter, r *ht
 		"recommendations": results,
 	}
 
+	// Логируем показы с источником и причиной каждой рекомендации
+	serve := domain.Serve{
+		Surface:   domain.SurfaceRecommendations,
+		SessionID: sessionID,
+		Items:     make([]domain.Impression, 0, len(results)),
+	}
+	for i, res := range results {
+		serve.Items = append(serve.Items, domain.Impression{
+			VideoID:  res.Video.ID,
+			Position: i + 1,
+			Source:   res.Explanation.Source,
+			Reason:   string(res.Reason),
+		})
+	}
+	if serveID := logServe(h.Serves, r, serve); serveID != "" {
+		response["serve_id"] = serveID
+	}
+
 	// Отправляем JSON ответ
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -79,3 +143,29 @@ func (h *RecommendationsHandler) getRecommendations(w http.ResponseWriter, r *ht
 		return
 	}
 }
+
+// getRelated отдаёт видео, которые смотрят вместе с данным ("кто смотрел X, смотрел и Y")
+func (h *RecommendationsHandler) getRelated(w http.ResponseWriter, r *http.Request) {
+	videoID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid video id", http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.UC.Related(r.Context(), videoID, parseLimitParam(r, 20))
+	if err != nil {
+		if errors.Is(err, domain.ErrVideoNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("ошибка получения похожих видео: %v", err)
+		http.Error(w, "внутренняя ошибка сервера", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"video_id": videoID,
+		"total":    len(results),
+		"related":  results,
+	})
+}
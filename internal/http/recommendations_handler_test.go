@@ -10,6 +10,7 @@ import (
 
 	"github.com/arasvet/microtube/internal/domain"
 	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -25,6 +26,11 @@ func (m *MockRecommendationsUC) GetRecommendations(ctx context.Context, params d
 	return args.Get(0).([]domain.RecommendationResult), args.Error(1)
 }
 
+func (m *MockRecommendationsUC) Related(ctx context.Context, videoID uuid.UUID, limit int) ([]domain.RecommendationResult, error) {
+	args := m.Called(ctx, videoID, limit)
+	return args.Get(0).([]domain.RecommendationResult), args.Error(1)
+}
+
 func TestRecommendationsHandler_GetRecommendations(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -193,3 +199,184 @@ func TestRecommendationsHandler_GetRecommendations_Error(t *testing.T) {
 	// Проверяем, что мок был вызван
 	mockUC.AssertExpectations(t)
 }
+
+func TestRecommendationsHandler_PlaylistHints(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	viewerID := uuid.New()
+	playlistID := uuid.New()
+	videoID := uuid.New()
+
+	mockUC := new(MockRecommendationsUC)
+	expectedParams := domain.RecommendationParams{
+		UserID:     &userID,
+		Limit:      20,
+		PlaylistID: &playlistID,
+		VideoID:    &videoID,
+		ViewerID:   viewerID,
+	}
+	mockUC.On("GetRecommendations", mock.Anything, expectedParams).Return([]domain.RecommendationResult{
+		{Video: domain.Video{ID: uuid.New()}, Reason: domain.ReasonPlaylistNext, Score: 1},
+	}, nil)
+
+	handler := &RecommendationsHandler{UC: mockUC}
+	req := httptest.NewRequest("GET", "/recommendations?user_id="+userID+
+		"&playlist_id="+playlistID.String()+"&video_id="+videoID.String(), nil)
+	w := httptest.NewRecorder()
+	handler.getRecommendations(w, withUser(req, viewerID))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "playlist_next")
+	mockUC.AssertExpectations(t)
+}
+
+func TestRecommendationsHandler_InvalidPlaylistID(t *testing.T) {
+	mockUC := new(MockRecommendationsUC)
+	handler := &RecommendationsHandler{UC: mockUC}
+
+	req := httptest.NewRequest("GET", "/recommendations?session_id=s1&playlist_id=bad", nil)
+	w := httptest.NewRecorder()
+	handler.getRecommendations(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUC.AssertNotCalled(t, "GetRecommendations", mock.Anything, mock.Anything)
+}
+
+func TestRecommendationsHandler_Diversity(t *testing.T) {
+	diversity := 0.7
+	sessionID := "s1"
+	mockUC := new(MockRecommendationsUC)
+	mockUC.On("GetRecommendations", mock.Anything, domain.RecommendationParams{
+		SessionID: &sessionID,
+		Limit:     20,
+		Diversity: &diversity,
+	}).Return([]domain.RecommendationResult{}, nil)
+
+	handler := &RecommendationsHandler{UC: mockUC}
+	req := httptest.NewRequest("GET", "/recommendations?session_id=s1&diversity=0.7", nil)
+	w := httptest.NewRecorder()
+	handler.getRecommendations(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestRecommendationsHandler_InvalidDiversity(t *testing.T) {
+	for _, value := range []string{"abc", "-0.1", "1.5"} {
+		mockUC := new(MockRecommendationsUC)
+		handler := &RecommendationsHandler{UC: mockUC}
+
+		req := httptest.NewRequest("GET", "/recommendations?session_id=s1&diversity="+value, nil)
+		w := httptest.NewRecorder()
+		handler.getRecommendations(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, value)
+		mockUC.AssertNotCalled(t, "GetRecommendations", mock.Anything, mock.Anything)
+	}
+}
+
+func TestRecommendationsHandler_DebugAdminOnly(t *testing.T) {
+	admin := uuid.New()
+	t.Setenv("ADMINS", admin.String())
+	sessionID := "s1"
+
+	// Обычный пользователь
+	mockUC := new(MockRecommendationsUC)
+	handler := &RecommendationsHandler{UC: mockUC}
+	req := httptest.NewRequest("GET", "/recommendations?session_id=s1&debug=true", nil)
+	w := httptest.NewRecorder()
+	handler.getRecommendations(w, withUser(req, uuid.New()))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockUC.AssertNotCalled(t, "GetRecommendations", mock.Anything, mock.Anything)
+
+	// Админ получает слагаемые score
+	mockUC.On("GetRecommendations", mock.Anything, domain.RecommendationParams{
+		SessionID: &sessionID,
+		Limit:     20,
+		ViewerID:  admin,
+		Debug:     true,
+		Admin:     true,
+	}).Return([]domain.RecommendationResult{{
+		Video:  domain.Video{ID: uuid.New()},
+		Reason: domain.ReasonUserTags,
+		Score:  0.9,
+		Explanation: domain.Explanation{
+			Source:      "session_tags",
+			MatchedTags: []domain.TagWeight{{Tag: "go", Weight: 3}},
+			Scores:      &domain.ScoreBreakdown{SourceWeight: 0.9, PositionDecay: 1, Final: 0.9},
+		},
+	}}, nil)
+
+	req = httptest.NewRequest("GET", "/recommendations?session_id=s1&debug=true", nil)
+	w = httptest.NewRecorder()
+	handler.getRecommendations(w, withUser(req, admin))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"MatchedTags":[{"Tag":"go","Weight":3}]`)
+	assert.Contains(t, w.Body.String(), `"SourceWeight":0.9`)
+	mockUC.AssertExpectations(t)
+}
+
+func TestRecommendationsHandler_Related(t *testing.T) {
+	videoID := uuid.New()
+	mockUC := new(MockRecommendationsUC)
+	mockUC.On("Related", mock.Anything, videoID, 5).Return([]domain.RecommendationResult{
+		{Video: domain.Video{ID: uuid.New()}, Reason: domain.ReasonSimilar, Score: 0.42},
+	}, nil)
+
+	r := chi.NewRouter()
+	(&RecommendationsHandler{UC: mockUC}).Register(r)
+
+	req := httptest.NewRequest("GET", "/videos/"+videoID.String()+"/related?limit=5", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"Reason":"similar"`)
+	mockUC.AssertExpectations(t)
+}
+
+func TestRecommendationsHandler_RelatedNotFound(t *testing.T) {
+	videoID := uuid.New()
+	mockUC := new(MockRecommendationsUC)
+	mockUC.On("Related", mock.Anything, videoID, 20).Return([]domain.RecommendationResult(nil), domain.ErrVideoNotFound)
+
+	r := chi.NewRouter()
+	(&RecommendationsHandler{UC: mockUC}).Register(r)
+
+	req := httptest.NewRequest("GET", "/videos/"+videoID.String()+"/related", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRecommendationsHandler_ServeUserFromJWT(t *testing.T) {
+	victim, viewer, videoID := uuid.New(), uuid.New(), uuid.New()
+	mockUC := new(MockRecommendationsUC)
+	mockUC.On("GetRecommendations", mock.Anything, mock.Anything).Return([]domain.RecommendationResult{
+		{Video: domain.Video{ID: videoID}, Reason: domain.ReasonPopular, Explanation: domain.Explanation{Source: "popular"}},
+	}, nil)
+	items := []domain.Impression{{VideoID: videoID, Position: 1, Source: "popular", Reason: string(domain.ReasonPopular)}}
+
+	serves := new(MockServeLogger)
+	handler := &RecommendationsHandler{UC: mockUC, Serves: serves}
+	url := "/recommendations?user_id=" + victim.String() + "&session_id=s1"
+
+	// Без JWT показ пишется только по сессии, подставленный user_id не учитывается
+	serves.On("LogServe", mock.Anything, domain.Serve{
+		Surface: domain.SurfaceRecommendations, SessionID: "s1", Items: items,
+	}).Return(uuid.New()).Once()
+	w := httptest.NewRecorder()
+	handler.getRecommendations(w, httptest.NewRequest("GET", url, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// С JWT — пользователь из токена, а не из запроса
+	serves.On("LogServe", mock.Anything, domain.Serve{
+		Surface: domain.SurfaceRecommendations, UserID: viewer, SessionID: "s1", Items: items,
+	}).Return(uuid.New()).Once()
+	w = httptest.NewRecorder()
+	handler.getRecommendations(w, withUser(httptest.NewRequest("GET", url, nil), viewer))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	serves.AssertExpectations(t)
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ReconcileHandler сверка счётчиков с сырыми событиями (только для админов)
+type ReconcileHandler struct {
+	UC usecase.ReconcileUCInterface
+}
+
+func (h *ReconcileHandler) Register(r chi.Router) {
+	r.Post("/stats/reconcile", h.reconcile)
+}
+
+type reconcileIn struct {
+	VideoIDs []string `json:"video_ids"`
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Tables   []string `json:"tables"`
+	Repair   bool     `json:"repair"`
+}
+
+// reconcile пересчитывает счётчики за дни from..to (YYYY-MM-DD) и возвращает расхождения;
+// с repair=true расхождения исправляются
+func (h *ReconcileHandler) reconcile(w http.ResponseWriter, r *http.Request) {
+	var in reconcileIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, "bad JSON body", http.StatusBadRequest)
+		return
+	}
+	var p domain.ReconcileParams
+	var err error
+	if p.From, err = time.Parse(time.DateOnly, in.From); err != nil {
+		http.Error(w, "invalid from, expected YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	if p.To, err = time.Parse(time.DateOnly, in.To); err != nil {
+		http.Error(w, "invalid to, expected YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	for _, s := range in.VideoIDs {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			http.Error(w, "invalid video_id", http.StatusBadRequest)
+			return
+		}
+		p.VideoIDs = append(p.VideoIDs, id)
+	}
+	for _, t := range in.Tables {
+		p.Tables = append(p.Tables, domain.CounterTable(t))
+	}
+	p.Repair = in.Repair
+
+	// Сверка за большой период идёт дольше WriteTimeout сервера
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	rep, err := h.UC.Reconcile(r.Context(), p)
+	if err != nil {
+		writeReconcileError(w, err)
+		return
+	}
+	writeJSON(w, rep)
+}
+
+func writeReconcileError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidReconcile), errors.Is(err, domain.ErrReconcileRetention):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrReconcileRunning):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("reconcile error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+	}
+}
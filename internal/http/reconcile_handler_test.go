@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReconcileUC - мок для тестирования
+type MockReconcileUC struct {
+	mock.Mock
+}
+
+func (m *MockReconcileUC) Reconcile(ctx context.Context, p domain.ReconcileParams) (domain.ReconcileReport, error) {
+	args := m.Called(ctx, p)
+	return args.Get(0).(domain.ReconcileReport), args.Error(1)
+}
+
+func TestReconcileHandler_Reconcile(t *testing.T) {
+	videoID := uuid.New()
+	mockUC := new(MockReconcileUC)
+	mockUC.On("Reconcile", mock.Anything, mock.MatchedBy(func(p domain.ReconcileParams) bool {
+		return p.Repair && len(p.VideoIDs) == 1 && p.VideoIDs[0] == videoID &&
+			p.From.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) &&
+			p.To.Equal(time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC))
+	})).Return(domain.ReconcileReport{
+		Repair:   true,
+		Diffs:    map[domain.CounterTable]int{domain.CounterVideoDaily: 1},
+		Repaired: 1,
+	}, nil)
+
+	r := chi.NewRouter()
+	(&ReconcileHandler{UC: mockUC}).Register(r)
+
+	w := httptest.NewRecorder()
+	body := `{"video_ids":["` + videoID.String() + `"],"from":"2024-03-01","to":"2024-03-07","repair":true}`
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/stats/reconcile", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"video_daily":1`)
+	assert.Contains(t, w.Body.String(), `"Repaired":1`)
+
+	mockUC.AssertExpectations(t)
+}
+
+func TestReconcileHandler_Errors(t *testing.T) {
+	mockUC := new(MockReconcileUC)
+	mockUC.On("Reconcile", mock.Anything, mock.Anything).Return(domain.ReconcileReport{}, domain.ErrReconcileRunning)
+
+	r := chi.NewRouter()
+	(&ReconcileHandler{UC: mockUC}).Register(r)
+
+	for body, code := range map[string]int{
+		`{"from":"2024-03-01","to":"2024-03-07"}`:                      http.StatusConflict,
+		`{"from":"01.03.2024","to":"2024-03-07"}`:                      http.StatusBadRequest,
+		`{"from":"2024-03-01","to":"2024-03-07","video_ids":["nope"]}`: http.StatusBadRequest,
+		`not json`: http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/stats/reconcile", strings.NewReader(body)))
+		assert.Equal(t, code, w.Code, body)
+	}
+}
@@ -13,7 +13,10 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-func SetupRoutes(r chi.Router, repos *repo.Repositories, cfg config.Config) {
+// SetupRoutes регистрирует обработчики. liveStatsUC и impressionsUC запускает вызывающий (Run):
+// подписка на Redis и запись выдач живут до остановки фоновых задач, а не до конца процесса.
+func SetupRoutes(r chi.Router, repos *repo.Repositories, cfg config.Config,
+	liveStatsUC *usecase.LiveStatsUC, impressionsUC *usecase.ImpressionsUC) {
 	// Корневая страница - перенаправление на документацию
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/docs", http.StatusMovedPermanently)
@@ -29,49 +32,80 @@ func SetupRoutes(r chi.Router, repos *repo.Repositories, cfg config.Config) {
 
 	// init
 	authUC := usecase.NewAuthUC(cfg, repos.Postgres, cfg.JWTSecret)
-	eventsUC := usecase.NewEventsUC(repos.Postgres, idem.New(repos.Redis.Client()))
-	searchUC := usecase.NewSearchUC(repos.Postgres)
-	feedUC := usecase.NewFeedUC(repos.Postgres)
-	recommendationsUC := usecase.NewRecommendationsUC(repos.Postgres)
+	eventsUC := usecase.NewEventsUC(repos.Postgres, idem.New(repos.Redis.Client()), repos.Redis, liveStatsUC,
+		usecase.NewFraudFilter(repos.Redis, cfg), cfg)
+	experimentsUC := usecase.NewExperimentsUC(repos.Postgres, cfg)
+	searchUC := usecase.NewSearchUC(repos.Postgres, experimentsUC)
+	synonymsUC := usecase.NewSynonymsUC(repos.Postgres)
+	feedUC := usecase.NewFeedUC(repos.Postgres, repos.Redis, repos.Redis, experimentsUC, cfg)
+	recommendationsUC := usecase.NewRecommendationsUC(repos.Postgres, repos.Redis, experimentsUC, cfg)
 	statsUC := usecase.NewStatsUC(repos.Postgres)
+	creatorStatsUC := usecase.NewCreatorStatsUC(repos.Postgres)
+	exportUC := usecase.NewExportUC(repos.Postgres)
+	reconcileUC := usecase.NewReconcileUC(repos.Postgres)
+	commentsUC := usecase.NewCommentsUC(repos.Postgres)
+	subscriptionsUC := usecase.NewSubscriptionsUC(repos.Postgres)
+	historyUC := usecase.NewHistoryUC(repos.Postgres)
+	playlistsUC := usecase.NewPlaylistsUC(repos.Postgres)
 
 	// register routes
 	(&AuthHandler{UC: authUC}).Register(r)
 	(&EventsHandler{UC: eventsUC}).Register(r)
-	(&SearchHandler{UC: searchUC}).Register(r)
-	(&FeedHandler{UC: feedUC}).Register(r)
-	(&RecommendationsHandler{UC: recommendationsUC}).Register(r)
+	(&SearchHandler{UC: searchUC, Serves: impressionsUC}).Register(r)
+	(&FeedHandler{UC: feedUC, Serves: impressionsUC}).Register(r)
+	(&RecommendationsHandler{UC: recommendationsUC, Serves: impressionsUC}).Register(r)
+	(&CommentsHandler{UC: commentsUC}).Register(r)
+	(&SubscriptionsHandler{UC: subscriptionsUC}).Register(r)
+	(&HistoryHandler{UC: historyUC}).Register(r)
+	(&PlaylistsHandler{UC: playlistsUC}).Register(r)
+	(&CreatorStatsHandler{UC: creatorStatsUC}).Register(r)
 
 	// auth
 	r.Group(func(ar chi.Router) {
 		ar.Use(adminOnlyMiddleware())
 		(&StatsHandler{UC: statsUC}).Register(ar)
+		(&LiveStatsHandler{UC: liveStatsUC}).Register(ar)
+		(&SynonymsHandler{UC: synonymsUC}).Register(ar)
+		(&ExperimentsHandler{UC: experimentsUC}).Register(ar)
+		(&ExportHandler{UC: exportUC, Slots: make(chan struct{}, max(cfg.ExportMaxConcurrent, 1))}).Register(ar)
+		(&ReconcileHandler{UC: reconcileUC}).Register(ar)
 	})
 }
 
 // todo
 func adminOnlyMiddleware() func(next http.Handler) http.Handler {
-	admins := strings.Split(os.Getenv("ADMINS"), ",")
-
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			userID, ok := UserIDFromContext(r)
-			if !ok {
+			if !isAdmin(r) {
 				http.Error(w, "forbidden", http.StatusForbidden)
 				return
 			}
-			for _, a := range admins {
-				if strings.TrimSpace(a) != "" && strings.TrimSpace(a) == userID {
-					next.ServeHTTP(w, r)
-					return
-				}
-			}
-			http.Error(w, "forbidden", http.StatusForbidden)
+			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// isAdmin true, если пользователь из JWT указан в ADMINS
+func isAdmin(r *http.Request) bool {
+	userID, ok := UserIDFromContext(r)
+	if !ok {
+		return false
+	}
+	for _, a := range strings.Split(os.Getenv("ADMINS"), ",") {
+		if strings.TrimSpace(a) != "" && strings.TrimSpace(a) == userID {
+			return true
+		}
+	}
+	return false
+}
+
 func writeJSON(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(v)
 }
+
+func writeJSONStatus(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
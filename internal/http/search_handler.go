@@ -9,10 +9,12 @@ import (
 	"github.com/arasvet/microtube/internal/domain"
 	"github.com/arasvet/microtube/internal/usecase"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type SearchHandler struct {
-	UC usecase.SearchUCInterface
+	UC     usecase.SearchUCInterface
+	Serves usecase.ServeLogger
 }
 
 func (h *SearchHandler) Register(r chi.Router) {
@@ -45,9 +47,13 @@ func (h *SearchHandler) searchVideos(w http.ResponseWriter, r *http.Request) {
 
 	// Создаем параметры поиска
 	params := domain.SearchParams{
-		Query:  query,
-		Limit:  limit,
-		Offset: offset,
+		Query:     query,
+		Limit:     limit,
+		Offset:    offset,
+		SessionID: r.URL.Query().Get("session_id"),
+	}
+	if sub, ok := UserIDFromContext(r); ok {
+		params.UserID, _ = uuid.Parse(sub)
 	}
 
 	// Выполняем поиск
@@ -67,6 +73,31 @@ func (h *SearchHandler) searchVideos(w http.ResponseWriter, r *http.Request) {
 		"results": results,
 	}
 
+	// Логируем показы; serve_id клиент возвращает в событиях click_result и view_start
+	serve := domain.Serve{
+		Surface:   domain.SurfaceSearch,
+		UserID:    params.UserID,
+		SessionID: params.SessionID,
+		Query:     query,
+		Items:     make([]domain.Impression, 0, len(results)),
+	}
+	for i, res := range results {
+		serve.Items = append(serve.Items, domain.Impression{VideoID: res.Video.ID, Position: offset + i + 1, Source: "search"})
+	}
+	if serveID := logServe(h.Serves, r, serve); serveID != "" {
+		response["serve_id"] = serveID
+	}
+
+	// Пустая выдача — предлагаем исправленный запрос
+	if len(results) == 0 {
+		suggestion, err := h.UC.Suggest(r.Context(), query)
+		if err != nil {
+			log.Printf("ошибка подсказки запроса: %v", err)
+		} else if suggestion != "" {
+			response["suggestion"] = suggestion
+		}
+	}
+
 	// Отправляем JSON ответ
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -25,6 +26,11 @@ func (m *MockSearchUC) SearchVideos(ctx context.Context, params domain.SearchPar
 	return args.Get(0).([]domain.SearchResult), args.Error(1)
 }
 
+func (m *MockSearchUC) Suggest(ctx context.Context, query string) (string, error) {
+	args := m.Called(ctx, query)
+	return args.String(0), args.Error(1)
+}
+
 func TestSearchHandler_SearchVideos(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -147,6 +153,87 @@ func TestSearchHandler_SearchVideos(t *testing.T) {
 	}
 }
 
+func TestSearchHandler_SearchVideos_Suggestion(t *testing.T) {
+	// Создаем мок: пустая выдача и подсказка
+	mockUC := new(MockSearchUC)
+	expectedParams := domain.SearchParams{Query: "kubernets", Limit: 20}
+	mockUC.On("SearchVideos", mock.Anything, expectedParams).Return([]domain.SearchResult{}, nil)
+	mockUC.On("Suggest", mock.Anything, "kubernets").Return("kubernetes", nil)
+
+	handler := &SearchHandler{UC: mockUC}
+
+	req := httptest.NewRequest("GET", "/search?q=kubernets", nil)
+	w := httptest.NewRecorder()
+	handler.searchVideos(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "kubernetes", body["suggestion"])
+	mockUC.AssertExpectations(t)
+}
+
+func TestSearchHandler_SearchVideos_NoSuggestionWithResults(t *testing.T) {
+	// При непустой выдаче подсказка не запрашивается
+	mockUC := new(MockSearchUC)
+	expectedParams := domain.SearchParams{Query: "golang", Limit: 20}
+	mockUC.On("SearchVideos", mock.Anything, expectedParams).Return([]domain.SearchResult{
+		{Video: domain.Video{ID: uuid.New(), Title: "Golang basics"}, Score: 0.5},
+	}, nil)
+
+	handler := &SearchHandler{UC: mockUC}
+
+	req := httptest.NewRequest("GET", "/search?q=golang", nil)
+	w := httptest.NewRecorder()
+	handler.searchVideos(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "suggestion")
+	mockUC.AssertNotCalled(t, "Suggest", mock.Anything, mock.Anything)
+}
+
+// MockServeLogger - мок для тестирования
+type MockServeLogger struct {
+	mock.Mock
+}
+
+func (m *MockServeLogger) LogServe(ctx context.Context, s domain.Serve) uuid.UUID {
+	args := m.Called(ctx, s)
+	return args.Get(0).(uuid.UUID)
+}
+
+func TestSearchHandler_SearchVideos_ServeID(t *testing.T) {
+	videoID := uuid.New()
+	serveID := uuid.New()
+
+	mockUC := new(MockSearchUC)
+	expectedParams := domain.SearchParams{Query: "golang", Limit: 10, Offset: 20}
+	mockUC.On("SearchVideos", mock.Anything, expectedParams).Return([]domain.SearchResult{
+		{Video: domain.Video{ID: videoID}, Score: 0.5},
+	}, nil)
+
+	// Позиция показа учитывает offset
+	serves := new(MockServeLogger)
+	serves.On("LogServe", mock.Anything, domain.Serve{
+		Surface: domain.SurfaceSearch,
+		Query:   "golang",
+		Items:   []domain.Impression{{VideoID: videoID, Position: 21, Source: "search"}},
+	}).Return(serveID)
+
+	handler := &SearchHandler{UC: mockUC, Serves: serves}
+
+	req := httptest.NewRequest("GET", "/search?q=golang&limit=10&offset=20", nil)
+	w := httptest.NewRecorder()
+	handler.searchVideos(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, serveID.String(), body["serve_id"])
+	serves.AssertExpectations(t)
+}
+
 // Вспомогательные функции для парсинга (копируем логику из handler)
 func parseLimit(limitStr string) (int, error) {
 	if limitStr == "" {
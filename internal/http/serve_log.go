@@ -0,0 +1,24 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/google/uuid"
+)
+
+// logServe логирует показы выдачи и возвращает serve_id для ответа.
+// Без логгера (тесты, фоновые задачи) возвращает пустую строку.
+func logServe(l usecase.ServeLogger, r *http.Request, s domain.Serve) string {
+	if l == nil {
+		return ""
+	}
+	// Пользователь показа — только из JWT: user_id из запроса может подставить кто угодно,
+	// а показы идут в атрибуцию кликов и награды бандита. Без JWT показ пишется по сессии.
+	s.UserID = uuid.Nil
+	if sub, ok := UserIDFromContext(r); ok {
+		s.UserID, _ = uuid.Parse(sub)
+	}
+	return l.LogServe(r.Context(), s).String()
+}
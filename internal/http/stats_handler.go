@@ -2,11 +2,13 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/arasvet/microtube/internal/domain"
 	"github.com/arasvet/microtube/internal/usecase"
 	"github.com/go-chi/chi/v5"
 )
@@ -17,6 +19,11 @@ type StatsHandler struct {
 
 func (h *StatsHandler) Register(r chi.Router) {
 	r.Get("/stats/overview", h.overview)
+	r.Get("/stats/positions", h.positions)
+	r.Get("/stats/timeseries", h.timeseries)
+	r.Get("/stats/actives", h.actives)
+	r.Get("/stats/cohorts", h.cohorts)
+	r.Get("/stats/funnel", h.funnel)
 }
 
 func (h *StatsHandler) overview(w http.ResponseWriter, r *http.Request) {
@@ -61,3 +68,143 @@ func (h *StatsHandler) overview(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(res)
 }
+
+// positions CTR по позициям выдачи (surface: feed, recommendations, search; пусто — все)
+func (h *StatsHandler) positions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	surface := domain.ExperimentSurface(q.Get("surface"))
+	if surface != "" && !surface.Valid() {
+		http.Error(w, "invalid surface", http.StatusBadRequest)
+		return
+	}
+
+	// По умолчанию последние 7 дней
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -7)
+	var err error
+	if s := q.Get("from"); s != "" {
+		if from, err = time.Parse(time.RFC3339, s); err != nil {
+			http.Error(w, "invalid from", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := q.Get("to"); s != "" {
+		if to, err = time.Parse(time.RFC3339, s); err != nil {
+			http.Error(w, "invalid to", http.StatusBadRequest)
+			return
+		}
+	}
+
+	res, err := h.UC.Positions(r.Context(), surface, from, to)
+	if err != nil {
+		log.Printf("stats positions error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"surface":   string(surface),
+		"from":      from,
+		"to":        to,
+		"positions": res,
+	})
+}
+
+// timeseries ряды метрик по корзинам hour/day/week/month с разбивкой по tag, lang или author
+func (h *StatsHandler) timeseries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	metrics, err := domain.ParseStatsMetrics(q.Get("metrics"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p := domain.TimeseriesParams{
+		Granularity: domain.StatsGranularity(q.Get("granularity")),
+		Metrics:     metrics,
+		Breakdown:   domain.StatsBreakdown(q.Get("breakdown")),
+		Compare:     q.Get("compare") == "true",
+	}
+	if p.Granularity == "" {
+		p.Granularity = domain.GranularityDay
+	}
+	// top <= 0 или невалидный — значение по умолчанию (см. TimeseriesParams.Validate)
+	p.Top, _ = strconv.Atoi(q.Get("top"))
+
+	// По умолчанию последние 30 дней
+	p.To = time.Now().UTC()
+	p.From = p.To.AddDate(0, 0, -30)
+	if s := q.Get("from"); s != "" {
+		if p.From, err = time.Parse(time.RFC3339, s); err != nil {
+			http.Error(w, "invalid from", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := q.Get("to"); s != "" {
+		if p.To, err = time.Parse(time.RFC3339, s); err != nil {
+			http.Error(w, "invalid to", http.StatusBadRequest)
+			return
+		}
+	}
+
+	res, err := h.UC.Timeseries(r.Context(), p)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidStatsQuery) || errors.Is(err, domain.ErrStatsRangeTooLarge) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("stats timeseries error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, res)
+}
+
+// actives DAU/WAU/MAU по дням from..to (YYYY-MM-DD), по умолчанию последние 30 дней
+func (h *StatsHandler) actives(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseStatsDays(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.UC.ActiveUsers(r.Context(), from, to)
+	if err != nil {
+		writeProductStatsError(w, err)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"from": from, "to": to, "days": res})
+}
+
+// cohorts удержание недельных когорт регистрации (weeks — сколько последних недель)
+func (h *StatsHandler) cohorts(w http.ResponseWriter, r *http.Request) {
+	// weeks <= 0 или невалидный — значение по умолчанию
+	weeks, _ := strconv.Atoi(r.URL.Query().Get("weeks"))
+	res, err := h.UC.Cohorts(r.Context(), weeks)
+	if err != nil {
+		writeProductStatsError(w, err)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"cohorts": res})
+}
+
+// funnel воронка поиска по сессиям за дни from..to (YYYY-MM-DD)
+func (h *StatsHandler) funnel(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseStatsDays(w, r)
+	if !ok {
+		return
+	}
+	res, err := h.UC.Funnel(r.Context(), from, to)
+	if err != nil {
+		writeProductStatsError(w, err)
+		return
+	}
+	writeJSON(w, res)
+}
+
+func writeProductStatsError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrInvalidStatsPeriod) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("product stats error: %v", err)
+	http.Error(w, "internal", http.StatusInternalServerError)
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type SubscriptionsHandler struct {
+	UC usecase.SubscriptionsUCInterface
+}
+
+func (h *SubscriptionsHandler) Register(r chi.Router) {
+	r.Get("/authors/{id}", h.author)
+	r.Post("/authors/{id}/follow", h.follow)
+	r.Delete("/authors/{id}/follow", h.unfollow)
+}
+
+// author профиль автора: число подписчиков, видео и подписан ли текущий пользователь
+func (h *SubscriptionsHandler) author(w http.ResponseWriter, r *http.Request) {
+	authorID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid author id", http.StatusBadRequest)
+		return
+	}
+
+	viewerID := uuid.Nil
+	if sub, ok := UserIDFromContext(r); ok {
+		viewerID, _ = uuid.Parse(sub)
+	}
+
+	profile, err := h.UC.Author(r.Context(), authorID, viewerID)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	writeJSON(w, profile)
+}
+
+func (h *SubscriptionsHandler) follow(w http.ResponseWriter, r *http.Request) {
+	h.withAuthor(w, r, h.UC.Follow)
+}
+
+func (h *SubscriptionsHandler) unfollow(w http.ResponseWriter, r *http.Request) {
+	h.withAuthor(w, r, h.UC.Unfollow)
+}
+
+func (h *SubscriptionsHandler) withAuthor(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, followerID, authorID uuid.UUID) (domain.AuthorProfile, error)) {
+	userID, ok := requireUserID(w, r)
+	if !ok {
+		return
+	}
+	authorID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid author id", http.StatusBadRequest)
+		return
+	}
+
+	profile, err := action(r.Context(), userID, authorID)
+	if err != nil {
+		writeSubscriptionError(w, err)
+		return
+	}
+	writeJSON(w, profile)
+}
+
+func writeSubscriptionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrAuthorNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrCannotFollowSelf):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		log.Printf("subscriptions error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/usecase"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SynonymsHandler CRUD словаря синонимов поиска (только для админов)
+type SynonymsHandler struct {
+	UC usecase.SynonymsUCInterface
+}
+
+func (h *SynonymsHandler) Register(r chi.Router) {
+	r.Get("/search/synonyms", h.list)
+	r.Post("/search/synonyms", h.create)
+	r.Put("/search/synonyms/{id}", h.update)
+	r.Delete("/search/synonyms/{id}", h.delete)
+}
+
+type synonymIn struct {
+	Term     string   `json:"term"`
+	Synonyms []string `json:"synonyms"`
+}
+
+func (h *SynonymsHandler) list(w http.ResponseWriter, r *http.Request) {
+	synonyms, err := h.UC.List(r.Context())
+	if err != nil {
+		log.Printf("synonyms list error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"total":    len(synonyms),
+		"synonyms": synonyms,
+	})
+}
+
+func (h *SynonymsHandler) create(w http.ResponseWriter, r *http.Request) {
+	var in synonymIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, "bad JSON body", http.StatusBadRequest)
+		return
+	}
+
+	s, err := h.UC.Create(r.Context(), domain.Synonym{Term: in.Term, Synonyms: in.Synonyms})
+	if err != nil {
+		writeSynonymError(w, err)
+		return
+	}
+
+	writeJSONStatus(w, http.StatusCreated, s)
+}
+
+func (h *SynonymsHandler) update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var in synonymIn
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, "bad JSON body", http.StatusBadRequest)
+		return
+	}
+
+	s, err := h.UC.Update(r.Context(), domain.Synonym{ID: id, Term: in.Term, Synonyms: in.Synonyms})
+	if err != nil {
+		writeSynonymError(w, err)
+		return
+	}
+	writeJSON(w, s)
+}
+
+func (h *SynonymsHandler) delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.UC.Delete(r.Context(), id); err != nil {
+		writeSynonymError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeSynonymError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidSynonym):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, domain.ErrSynonymNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrSynonymExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("synonyms error: %v", err)
+		http.Error(w, "internal", http.StatusInternalServerError)
+	}
+}
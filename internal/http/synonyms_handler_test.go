@@ -0,0 +1,123 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSynonymsUC - мок для тестирования
+type MockSynonymsUC struct {
+	mock.Mock
+}
+
+func (m *MockSynonymsUC) List(ctx context.Context) ([]domain.Synonym, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Synonym), args.Error(1)
+}
+
+func (m *MockSynonymsUC) Create(ctx context.Context, s domain.Synonym) (domain.Synonym, error) {
+	args := m.Called(ctx, s)
+	return args.Get(0).(domain.Synonym), args.Error(1)
+}
+
+func (m *MockSynonymsUC) Update(ctx context.Context, s domain.Synonym) (domain.Synonym, error) {
+	args := m.Called(ctx, s)
+	return args.Get(0).(domain.Synonym), args.Error(1)
+}
+
+func (m *MockSynonymsUC) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestSynonymsHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		ucErr          error
+		expectedStatus int
+	}{
+		{
+			name:           "успешное создание",
+			body:           `{"term":"k8s","synonyms":["kubernetes"]}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "невалидная группа",
+			body:           `{"term":"k8s","synonyms":[]}`,
+			ucErr:          domain.ErrInvalidSynonym,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "термин уже существует",
+			body:           `{"term":"k8s","synonyms":["kubernetes"]}`,
+			ucErr:          domain.ErrSynonymExists,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "невалидный JSON",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUC := new(MockSynonymsUC)
+			if tt.expectedStatus != http.StatusBadRequest {
+				var synonyms []string
+				if tt.ucErr != domain.ErrInvalidSynonym {
+					synonyms = []string{"kubernetes"}
+				} else {
+					synonyms = []string{}
+				}
+				in := domain.Synonym{Term: "k8s", Synonyms: synonyms}
+				out := in
+				out.ID = uuid.New()
+				mockUC.On("Create", mock.Anything, in).Return(out, tt.ucErr)
+			}
+
+			handler := &SynonymsHandler{UC: mockUC}
+
+			req := httptest.NewRequest("POST", "/search/synonyms", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.create(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSynonymsHandler_Delete(t *testing.T) {
+	id := uuid.New()
+
+	mockUC := new(MockSynonymsUC)
+	mockUC.On("Delete", mock.Anything, id).Return(domain.ErrSynonymNotFound)
+
+	r := chi.NewRouter()
+	(&SynonymsHandler{UC: mockUC}).Register(r)
+
+	// Несуществующий id -> 404
+	req := httptest.NewRequest("DELETE", "/search/synonyms/"+id.String(), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Невалидный id -> 400, UC не вызывается
+	req = httptest.NewRequest("DELETE", "/search/synonyms/not-a-uuid", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockUC.AssertExpectations(t)
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/arasvet/microtube/internal/usecase"
+)
+
+// Setup запускает периодические фоновые задачи API. Задачи останавливаются по отмене ctx.
+func Setup(ctx context.Context, repos *repo.Repositories, cfg config.Config) {
+	searchUC := usecase.NewSearchUC(repos.Postgres, nil)
+	feedUC := usecase.NewFeedUC(repos.Postgres, repos.Redis, repos.Redis, nil, cfg)
+	recommendationsUC := usecase.NewRecommendationsUC(repos.Postgres, repos.Redis, nil, cfg)
+	statsUC := usecase.NewStatsUC(repos.Postgres)
+	eventPartitionsUC := usecase.NewEventPartitionsUC(repos.Postgres, cfg)
+	impressionsUC := usecase.NewImpressionsUC(repos.Postgres, cfg)
+
+	go runEvery(ctx, "search_vocabulary", cfg.SearchVocabularyRefresh, searchUC.RefreshVocabulary)
+	go runEvery(ctx, "trending", cfg.TrendingRefresh, feedUC.RefreshTrending)
+	go runEvery(ctx, "covisitation", cfg.CovisitationRefresh, recommendationsUC.RefreshCovisitation)
+	go runEvery(ctx, "product_metrics", cfg.ProductMetricsRefresh, func(ctx context.Context) error {
+		return statsUC.RefreshProductMetrics(ctx, cfg.ProductMetricsLookback, cfg.ProductMetricsRefresh)
+	})
+	go runEvery(ctx, "event_partitions", cfg.EventPartitionsRefresh, eventPartitionsUC.Maintain)
+	go runEvery(ctx, "serves_retention", cfg.ServesRetentionRefresh, impressionsUC.Cleanup)
+}
+
+// runEvery выполняет fn сразу и затем с интервалом every; ошибки только логируются
+func runEvery(ctx context.Context, name string, every time.Duration, fn func(ctx context.Context) error) {
+	if every <= 0 {
+		slog.Info("job disabled", "job", name)
+		return
+	}
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		started := time.Now()
+		if err := fn(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("job failed", "job", name, "err", err)
+		} else {
+			slog.Debug("job done", "job", name, "took", time.Since(started))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -2,8 +2,10 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -25,27 +27,167 @@ type Store interface {
 
 	// Events
 	InsertEvent(ctx context.Context, tx Tx, e domain.Event) (bool, error)
+	FlagEvent(ctx context.Context, tx Tx, e domain.Event) error
 	ExistsEvent(ctx context.Context, event domain.Event) (bool, error)
 	UpsertVideoCounters(ctx context.Context, tx Tx, e domain.Event) error
 	UpsertVideoDaily(ctx context.Context, tx Tx, e domain.Event) error
+	UpsertVideoHourly(ctx context.Context, tx Tx, e domain.Event) error
 	UpdateUserSignalsBestEffort(ctx context.Context, tx Tx, e domain.Event) error
+	UpsertWatchHistory(ctx context.Context, tx Tx, e domain.Event) error
+	UpsertNegativeFeedback(ctx context.Context, tx Tx, e domain.Event) error
+	AttributeClick(ctx context.Context, tx Tx, e domain.Event) (bool, error)
+	UpsertRetention(ctx context.Context, tx Tx, e domain.Event) error
+	GetNegativeFeedback(ctx context.Context, userID uuid.UUID) (domain.NegativeFeedback, error)
+
+	// История просмотров
+	ListWatchHistory(ctx context.Context, params domain.HistoryParams) ([]domain.HistoryItem, error)
+	ListUnfinished(ctx context.Context, userID uuid.UUID, limit int) ([]domain.HistoryItem, error)
+	DeleteWatchHistory(ctx context.Context, userID uuid.UUID, videoID *uuid.UUID) (int64, error)
+	GetHistorySettings(ctx context.Context, userID uuid.UUID) (domain.HistorySettings, error)
+	SetHistoryPaused(ctx context.Context, userID uuid.UUID, paused bool) error
 
 	// Search
 	SearchVideos(ctx context.Context, params domain.SearchParams) ([]domain.SearchResult, error)
+	FindSynonyms(ctx context.Context, tokens []string) ([]domain.Synonym, error)
+	SuggestQueryTokens(ctx context.Context, tokens []string) ([]string, error)
+	RefreshTitleVocabulary(ctx context.Context) error
+
+	// Словарь синонимов
+	ListSynonyms(ctx context.Context) ([]domain.Synonym, error)
+	CreateSynonym(ctx context.Context, s domain.Synonym) (domain.Synonym, error)
+	UpdateSynonym(ctx context.Context, s domain.Synonym) (domain.Synonym, error)
+	DeleteSynonym(ctx context.Context, id uuid.UUID) error
 
 	// Feeds
 	GetPopularVideos(ctx context.Context, limit int) ([]domain.Video, error)
 	GetCommentedVideos(ctx context.Context, limit int) ([]domain.Video, error)
 	GetRandomVideos(ctx context.Context, limit int) ([]domain.Video, error)
+	GetVideosByIDs(ctx context.Context, ids []uuid.UUID) ([]domain.Video, error)
+	TrendingCounters(ctx context.Context, halfLifeDays float64) ([]domain.TrendingCounters, error)
+
+	// Комментарии
+	VideoExists(ctx context.Context, videoID uuid.UUID) (bool, error)
+	GetComment(ctx context.Context, id uuid.UUID) (domain.Comment, error)
+	InsertComment(ctx context.Context, tx Tx, c domain.Comment) (domain.Comment, error)
+	UpdateCommentBody(ctx context.Context, id uuid.UUID, body string) (domain.Comment, error)
+	SoftDeleteComment(ctx context.Context, tx Tx, id uuid.UUID) (bool, error)
+	IncCommentReplies(ctx context.Context, tx Tx, id uuid.UUID, delta int) error
+	IncVideoComments(ctx context.Context, tx Tx, videoID uuid.UUID, delta int) error
+	ListComments(ctx context.Context, params domain.CommentListParams) ([]domain.Comment, error)
+	LikeComment(ctx context.Context, tx Tx, commentID, userID uuid.UUID) (bool, error)
+	UnlikeComment(ctx context.Context, tx Tx, commentID, userID uuid.UUID) (bool, error)
+
+	// Подписки
+	UserExists(ctx context.Context, userID uuid.UUID) (bool, error)
+	Follow(ctx context.Context, followerID, authorID uuid.UUID) (bool, error)
+	Unfollow(ctx context.Context, followerID, authorID uuid.UUID) (bool, error)
+	GetAuthorProfile(ctx context.Context, authorID, viewerID uuid.UUID) (domain.AuthorProfile, error)
+	GetFollowedAuthorIDs(ctx context.Context, userID string) ([]uuid.UUID, error)
+	GetSubscriptionVideos(ctx context.Context, userID uuid.UUID, cursor *domain.Cursor, limit int) ([]domain.Video, error)
+
+	// Плейлисты
+	CreatePlaylist(ctx context.Context, p domain.Playlist) (domain.Playlist, error)
+	GetPlaylist(ctx context.Context, id uuid.UUID) (domain.Playlist, error)
+	GetOrCreateWatchLater(ctx context.Context, ownerID uuid.UUID) (domain.Playlist, error)
+	ListUserPlaylists(ctx context.Context, ownerID uuid.UUID, onlyPublic bool) ([]domain.Playlist, error)
+	UpdatePlaylist(ctx context.Context, id uuid.UUID, upd domain.PlaylistUpdate) (domain.Playlist, error)
+	DeletePlaylist(ctx context.Context, id uuid.UUID) error
+	ListPlaylistItems(ctx context.Context, playlistID uuid.UUID) ([]domain.PlaylistItem, error)
+	LockPlaylist(ctx context.Context, tx Tx, playlistID uuid.UUID) (int, error)
+	InsertPlaylistItem(ctx context.Context, tx Tx, playlistID, videoID uuid.UUID, position int) error
+	MovePlaylistItem(ctx context.Context, tx Tx, playlistID, videoID uuid.UUID, position int) error
+	DeletePlaylistItem(ctx context.Context, tx Tx, playlistID, videoID uuid.UUID) error
 
 	// Рекомендации
-	GetUserTopTags(ctx context.Context, userID string) ([]string, error)
-	GetSessionTopTags(ctx context.Context, sessionID string) ([]string, error)
+	GetUserTopTags(ctx context.Context, userID string) ([]domain.TagWeight, error)
+	GetSessionTopTags(ctx context.Context, sessionID string) ([]domain.TagWeight, error)
 	GetVideosByTags(ctx context.Context, tags []string, limit int) ([]domain.Video, error)
 	GetSimilarVideos(ctx context.Context, videoID string, limit int) ([]domain.Video, error)
 	GetDiversifiedVideos(ctx context.Context, excludeIDs []string, limit int) ([]domain.Video, error)
+	GetCovisitedVideos(ctx context.Context, seeds []uuid.UUID, limit int) ([]domain.RecommendationResult, error)
+	GetRecentlyWatchedIDs(ctx context.Context, userID uuid.UUID, limit int) ([]uuid.UUID, error)
+	RebuildCovisitation(ctx context.Context, since time.Time, maxGap, minSessions, topN int) (int64, error)
+
+	// Бандит исследования свежих видео
+	GetBanditArms(ctx context.Context, since time.Time, maxViews, limit int) ([]domain.BanditArm, error)
+	RecordBanditImpressions(ctx context.Context, videoIDs []uuid.UUID) error
+
+	// A/B-эксперименты
+	CreateExperiment(ctx context.Context, e domain.Experiment) (domain.Experiment, error)
+	GetExperiment(ctx context.Context, id uuid.UUID) (domain.Experiment, error)
+	ListExperiments(ctx context.Context) ([]domain.Experiment, error)
+	ListRunningExperiments(ctx context.Context) ([]domain.Experiment, error)
+	SetExperimentStatus(ctx context.Context, id uuid.UUID, from, to domain.ExperimentStatus) error
+	LogExposure(ctx context.Context, e domain.Exposure) error
+	ExperimentCounts(ctx context.Context, id uuid.UUID, until *time.Time) ([]domain.VariantCounts, error)
+
+	// Коллаборативная фильтрация (ALS)
+	Interactions(ctx context.Context, since time.Time, userID *uuid.UUID) ([]domain.Interaction, error)
+	SaveFactorModel(ctx context.Context, model domain.FactorModel, users map[uuid.UUID][]float64, videos map[uuid.UUID][]float64) error
+	LoadVideoFactors(ctx context.Context) (domain.FactorModel, []uuid.UUID, [][]float64, error)
+	GetUserFactors(ctx context.Context, userID uuid.UUID) ([]float64, error)
 
 	// Статистика
 	StatsTotals(ctx context.Context, from, to string) (domain.StatsTotals, error)
 	StatsTopVideos(ctx context.Context, from, to string, top int) ([]domain.VideoWithStats, error)
+	StatsPositionCTR(ctx context.Context, surface domain.ExperimentSurface, from, to time.Time, maxPosition int) ([]domain.PositionCTR, error)
+	StatsTimeseries(ctx context.Context, p domain.TimeseriesParams, keys []string) ([]domain.TimeseriesRow, error)
+
+	// Продуктовые метрики: агрегаты пересчитываются фоновой задачей
+	RefreshProductMetrics(ctx context.Context, lookback, minAge time.Duration) (time.Time, bool, error)
+	StatsActiveUsers(ctx context.Context, from, to time.Time) ([]domain.ActiveUsers, error)
+	StatsCohorts(ctx context.Context, from time.Time) ([]domain.CohortCell, []domain.CohortCell, error)
+	StatsFunnel(ctx context.Context, from, to time.Time) (domain.FunnelCounts, error)
+
+	// Выгрузки аналитики
+	ExportRows(ctx context.Context, dataset domain.ExportDataset, from, to time.Time, columns []string, fn func(values []any) error) error
+
+	// Сверка счётчиков с сырыми событиями
+	LockReconcile(ctx context.Context) (func(), error)
+	ReconcileVideos(ctx context.Context, from, to time.Time) ([]uuid.UUID, error)
+	ReconcileRows(ctx context.Context, table domain.CounterTable, day time.Time, videoIDs []uuid.UUID) ([]domain.CounterRow, []domain.CounterRow, error)
+	ApplyCounterDeltas(ctx context.Context, table domain.CounterTable, diffs []domain.CounterDiff) error
+
+	// Партиции events и срок хранения
+	EnsureEventPartitions(ctx context.Context, ahead int) (int, error)
+	EventPartitions(ctx context.Context) ([]domain.EventPartition, error)
+	EventsHorizon(ctx context.Context) (time.Time, error)
+	DropEventPartition(ctx context.Context, p domain.EventPartition) (int64, error)
+
+	// Статистика автора по своим видео
+	CreatorDaily(ctx context.Context, f domain.CreatorStatsFilter) ([]domain.CreatorDayRaw, error)
+	CreatorTraffic(ctx context.Context, f domain.CreatorStatsFilter) ([]domain.TrafficSource, error)
+	CreatorTopQueries(ctx context.Context, f domain.CreatorStatsFilter, limit int) ([]domain.QueryCount, error)
+	CreatorVideoTotals(ctx context.Context, f domain.CreatorStatsFilter, limit int) ([]domain.VideoStatsSummary, error)
+	ChannelRetention(ctx context.Context, authorID uuid.UUID) ([]domain.RetentionCounts, error)
+
+	// Показы выдачи и их срок хранения
+	LogServes(ctx context.Context, serves []domain.Serve) error
+	ServesHorizon(ctx context.Context) (time.Time, error)
+	OldestServe(ctx context.Context) (time.Time, error)
+	DeleteServes(ctx context.Context, before time.Time) (int64, error)
+}
+
+// TrendingStore предрасчитанные trending-рейтинги (Redis sorted sets)
+type TrendingStore interface {
+	SaveTrending(ctx context.Context, window domain.TrendingWindow, scores map[uuid.UUID]float64, ttl time.Duration) error
+	GetTrending(ctx context.Context, window domain.TrendingWindow, limit int) ([]uuid.UUID, error)
+}
+
+// LiveStatsBus рассылка дельт live-статистики между репликами API (Redis pub/sub)
+type LiveStatsBus interface {
+	PublishLiveStats(ctx context.Context, payload []byte) error
+	SubscribeLiveStats(ctx context.Context) <-chan []byte
+}
+
+// RateCounter счётчики попаданий в фиксированном окне (Redis), для лимитов антифрода
+type RateCounter interface {
+	HitRate(ctx context.Context, key string, window time.Duration) (int64, error)
+	UnhitRate(ctx context.Context, key string) error
+}
+
+// SeenStore недавно досмотренные и показанные пользователю видео (Redis)
+type SeenStore interface {
+	MarkSeen(ctx context.Context, userID uuid.UUID, videoIDs []uuid.UUID, until time.Time, maxItems int) error
+	GetSeen(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]bool, error)
 }
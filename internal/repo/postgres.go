@@ -8,6 +8,7 @@ import (
 	"github.com/arasvet/microtube/internal/domain"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -33,38 +34,69 @@ func (r *PostgresRepo) Begin(ctx context.Context) (Tx, error) {
 	return &PostgresTx{tx: tx}, nil
 }
 
+// IsSerializationFailure конфликт параллельных транзакций (serialization failure или deadlock):
+// транзакцию можно повторить целиком
+func IsSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
 func (r *PostgresRepo) InsertEvent(ctx context.Context, tx Tx, e domain.Event) (bool, error) {
 	// Обрабатываем uuid.Nil как NULL
-	var userID, videoID interface{}
+	var userID, videoID, serveID interface{}
 	if e.UserID != uuid.Nil {
 		userID = e.UserID
 	}
 	if e.VideoID != uuid.Nil {
 		videoID = e.VideoID
 	}
+	if e.ServeID != uuid.Nil {
+		serveID = e.ServeID
+	}
+	var positionMs interface{}
+	if e.Type == domain.EventProgress {
+		positionMs = e.PositionMs
+	}
 
+	// Уникальность event_id держит event_ids: ключ events включает ts,
+	// и повтор с другим ts иначе записался бы вторым событием
 	cmd, err := tx.(*PostgresTx).tx.Exec(ctx, `
-		INSERT INTO app.events(event_id, ts, type, session_id, user_id, video_id, query, dwell_ms)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
-		ON CONFLICT (event_id) DO NOTHING
-	`, e.EventID, e.TS.UTC(), e.Type, e.SessionID, userID, videoID, e.Query, e.DwellMs)
+		WITH id AS (
+			INSERT INTO app.event_ids(event_id, ts) VALUES ($1, $2)
+			ON CONFLICT (event_id) DO NOTHING
+			RETURNING event_id
+		)
+		INSERT INTO app.events(event_id, ts, type, session_id, user_id, video_id, query, dwell_ms, tag, serve_id, position_ms, flag)
+		SELECT $1, $2, $3::app.event_type, $4::text, $5::uuid, $6::uuid, $7::text, $8::int,
+			NULLIF($9::text, ''), $10::uuid, $11::int, NULLIF($12::text, '')
+		FROM id
+		ON CONFLICT (event_id, ts) DO NOTHING
+	`, e.EventID, e.TS.UTC(), e.Type, e.SessionID, userID, videoID, e.Query, e.DwellMs, e.Tag, serveID, positionMs, string(e.Flag))
 	if err != nil {
 		return false, err
 	}
 	return cmd.RowsAffected() == 1, nil
 }
 
+// FlagEvent записывает пометку антифрода уже вставленному в этой транзакции событию
+func (r *PostgresRepo) FlagEvent(ctx context.Context, tx Tx, e domain.Event) error {
+	_, err := tx.(*PostgresTx).tx.Exec(ctx, `
+		UPDATE app.events SET flag = $3 WHERE event_id = $1 AND ts = $2
+	`, e.EventID, e.TS.UTC(), string(e.Flag))
+	return err
+}
+
 func (r *PostgresRepo) ExistsEvent(ctx context.Context, event domain.Event) (bool, error) {
 	var exists bool
-	err := r.DB.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM events WHERE event_id = $1)`, event.EventID.String()).Scan(&exists)
+	err := r.DB.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM app.event_ids WHERE event_id = $1)`, event.EventID).Scan(&exists)
 	return exists, err
 }
 
 func (r *PostgresRepo) UpsertVideoCounters(ctx context.Context, tx Tx, e domain.Event) error {
-	if e.VideoID.String() == "" {
+	if e.VideoID == uuid.Nil {
 		return nil
 	}
-	viewsInc, completesInc, likesInc := 0, 0, 0
+	viewsInc, completesInc, likesInc, dislikesInc, hidesInc := 0, 0, 0, 0, 0
 	switch e.Type {
 	case domain.EventViewStart:
 		viewsInc = 1
@@ -72,27 +104,39 @@ func (r *PostgresRepo) UpsertVideoCounters(ctx context.Context, tx Tx, e domain.
 		completesInc = 1
 	case domain.EventLike:
 		likesInc = 1
+	case domain.EventUnlike:
+		likesInc = -1
+	case domain.EventDislike:
+		dislikesInc = 1
+	case domain.EventHideVideo:
+		hidesInc = 1
 	default:
 		// no-op
 	}
+	// likes не уходит в минус, если unlike пришёл без парного like
 	_, err := tx.(*PostgresTx).tx.Exec(ctx, `
-		INSERT INTO app.video_counters(video_id, views, completes, likes, last_event_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO app.video_counters(video_id, views, completes, likes, dislikes, hides, last_event_at)
+		VALUES ($1, $2, $3, GREATEST($4, 0), $5, $6, $7)
 		ON CONFLICT (video_id) DO UPDATE
 		SET views = app.video_counters.views + EXCLUDED.views,
 		    completes = app.video_counters.completes + EXCLUDED.completes,
-		    likes = app.video_counters.likes + EXCLUDED.likes,
+		    likes = GREATEST(app.video_counters.likes + $4, 0),
+		    dislikes = app.video_counters.dislikes + EXCLUDED.dislikes,
+		    hides = app.video_counters.hides + EXCLUDED.hides,
 		    last_event_at = GREATEST(app.video_counters.last_event_at, EXCLUDED.last_event_at)
-	`, e.VideoID, viewsInc, completesInc, likesInc, e.TS.UTC())
+	`, e.VideoID, viewsInc, completesInc, likesInc, dislikesInc, hidesInc, e.TS.UTC())
 	return err
 }
 
 func (r *PostgresRepo) UpsertVideoDaily(ctx context.Context, tx Tx, e domain.Event) error {
-	if e.VideoID.String() == "" {
+	if e.VideoID == uuid.Nil {
 		return nil
 	}
 	day := e.TS.UTC().Truncate(24 * time.Hour)
-	viewsInc, completesInc, likesInc, clicksInc, imprInc, dwellInc := 0, 0, 0, 0, 0, 0
+	// Показы пишутся при выдаче (LogServes), клики по записанному показу — через него (AttributeClick):
+	// вызывающий передаёт такие события с ServeID, остальные клики — без него
+	viewsInc, completesInc, likesInc, clicksInc, dwellInc := 0, 0, 0, 0, 0
+	dislikesInc, unlikesInc, hidesInc := 0, 0, 0
 	switch e.Type {
 	case domain.EventViewStart:
 		viewsInc = 1
@@ -101,24 +145,68 @@ func (r *PostgresRepo) UpsertVideoDaily(ctx context.Context, tx Tx, e domain.Eve
 	case domain.EventLike:
 		likesInc = 1
 	case domain.EventClickResult:
-		clicksInc = 1
-	case domain.EventSearchQuery:
-		imprInc = 1 // считаем показ выдачи как impression
+		if e.ServeID == uuid.Nil {
+			clicksInc = 1
+		}
+	case domain.EventDislike:
+		dislikesInc = 1
+	case domain.EventUnlike:
+		unlikesInc = 1
+	case domain.EventHideVideo:
+		hidesInc = 1
 	}
 	if e.DwellMs != 0 {
 		dwellInc = e.DwellMs
 	}
 	_, err := tx.(*PostgresTx).tx.Exec(ctx, `
-		INSERT INTO app.video_daily(video_id, day, views, completes, likes, clicks, impressions, dwell_ms_sum)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		INSERT INTO app.video_daily(video_id, day, views, completes, likes, clicks, dwell_ms_sum,
+		                            dislikes, unlikes, hides)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
 		ON CONFLICT (video_id, day) DO UPDATE
 		SET views = app.video_daily.views + EXCLUDED.views,
 		    completes = app.video_daily.completes + EXCLUDED.completes,
 		    likes = app.video_daily.likes + EXCLUDED.likes,
 		    clicks = app.video_daily.clicks + EXCLUDED.clicks,
-		    impressions = app.video_daily.impressions + EXCLUDED.impressions,
-		    dwell_ms_sum = app.video_daily.dwell_ms_sum + EXCLUDED.dwell_ms_sum
-	`, e.VideoID, day, viewsInc, completesInc, likesInc, clicksInc, imprInc, dwellInc)
+		    dwell_ms_sum = app.video_daily.dwell_ms_sum + EXCLUDED.dwell_ms_sum,
+		    dislikes = app.video_daily.dislikes + EXCLUDED.dislikes,
+		    unlikes = app.video_daily.unlikes + EXCLUDED.unlikes,
+		    hides = app.video_daily.hides + EXCLUDED.hides
+	`, e.VideoID, day, viewsInc, completesInc, likesInc, clicksInc, dwellInc,
+		dislikesInc, unlikesInc, hidesInc)
+	return err
+}
+
+// UpsertVideoHourly почасовой аналог UpsertVideoDaily для временных рядов статистики
+func (r *PostgresRepo) UpsertVideoHourly(ctx context.Context, tx Tx, e domain.Event) error {
+	if e.VideoID == uuid.Nil {
+		return nil
+	}
+	viewsInc, completesInc, likesInc, clicksInc := 0, 0, 0, 0
+	switch e.Type {
+	case domain.EventViewStart:
+		viewsInc = 1
+	case domain.EventViewComplete:
+		completesInc = 1
+	case domain.EventLike:
+		likesInc = 1
+	case domain.EventClickResult:
+		if e.ServeID == uuid.Nil {
+			clicksInc = 1
+		}
+	}
+	if viewsInc+completesInc+likesInc+clicksInc == 0 && e.DwellMs == 0 {
+		return nil
+	}
+	_, err := tx.(*PostgresTx).tx.Exec(ctx, `
+		INSERT INTO app.video_hourly(video_id, hour, views, completes, likes, clicks, dwell_ms_sum)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+		ON CONFLICT (video_id, hour) DO UPDATE
+		SET views = app.video_hourly.views + EXCLUDED.views,
+		    completes = app.video_hourly.completes + EXCLUDED.completes,
+		    likes = app.video_hourly.likes + EXCLUDED.likes,
+		    clicks = app.video_hourly.clicks + EXCLUDED.clicks,
+		    dwell_ms_sum = app.video_hourly.dwell_ms_sum + EXCLUDED.dwell_ms_sum
+	`, e.VideoID, e.TS.UTC().Truncate(time.Hour), viewsInc, completesInc, likesInc, clicksInc, e.DwellMs)
 	return err
 }
 
@@ -131,12 +219,19 @@ func (r *PostgresRepo) UpdateUserSignalsBestEffort(ctx context.Context, tx Tx, e
 	if e.UserID.String() != "" {
 		key = e.UserID.String()
 	}
-	_, err := tx.(*PostgresTx).tx.Exec(ctx, `
+	const q = `
 		INSERT INTO app.user_signals(user_or_session, last_seen_at)
 		VALUES ($1, $2)
 		ON CONFLICT (user_or_session) DO UPDATE
 		SET last_seen_at = GREATEST(app.user_signals.last_seen_at, EXCLUDED.last_seen_at)
-	`, key, e.TS.UTC())
+	`
+	var err error
+	// без транзакции (nil) — вызов после коммита события, пишем через пул
+	if tx == nil {
+		_, err = r.DB.Exec(ctx, q, key, e.TS.UTC())
+	} else {
+		_, err = tx.(*PostgresTx).tx.Exec(ctx, q, key, e.TS.UTC())
+	}
 	// best-effort: проглатываем serialization ошибки с ретраем наверху (позже)
 	if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 		return nil
@@ -146,9 +241,17 @@ func (r *PostgresRepo) UpdateUserSignalsBestEffort(ctx context.Context, tx Tx, e
 
 // SearchVideos выполняет полнотекстовый поиск по видео с поддержкой FTS и trigram
 func (r *PostgresRepo) SearchVideos(ctx context.Context, params domain.SearchParams) ([]domain.SearchResult, error) {
-	// Комбинированный поиск: FTS + trigram для лучших результатов
+	// Комбинированный поиск: FTS + trigram для лучших результатов.
+	// Если для слов запроса есть синонимы, FTS идёт по расширенному tsquery ($4),
+	// trigram по-прежнему сравнивает исходный текст запроса.
 	query := `
-		WITH search_results AS (
+		WITH q AS (
+			SELECT CASE
+				WHEN $4 = '' THEN plainto_tsquery('simple', $1)
+				ELSE to_tsquery('simple', $4)
+			END AS tsq
+		),
+		search_results AS (
 			SELECT 
 				v.id,
 				v.title,
@@ -159,16 +262,16 @@ func (r *PostgresRepo) SearchVideos(ctx context.Context, params domain.SearchPar
 				v.uploaded_at,
 				v.author_id,
 				-- FTS релевантность
-				COALESCE(ts_rank_cd(v.fts_tsv, plainto_tsquery('simple', $1)), 0) as fts_score,
+				COALESCE(ts_rank_cd(v.fts_tsv, q.tsq), 0) as fts_score,
 				-- Trigram релевантность для опечаток
 				COALESCE(GREATEST(
 					similarity(immutable_unaccent(v.title), immutable_unaccent($1)),
 					similarity(immutable_unaccent(v.description), immutable_unaccent($1))
 				), 0) as trigram_score
-			FROM app.videos v
+			FROM app.videos v, q
 			WHERE 
 				-- FTS поиск
-				v.fts_tsv @@ plainto_tsquery('simple', $1)
+				v.fts_tsv @@ q.tsq
 				OR 
 				-- Trigram поиск для опечаток (если FTS не дал результатов)
 				(immutable_unaccent(v.title) % immutable_unaccent($1) 
@@ -183,7 +286,8 @@ func (r *PostgresRepo) SearchVideos(ctx context.Context, params domain.SearchPar
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.DB.Query(ctx, query, params.Query, params.Limit, params.Offset)
+	expanded := buildExpandedTSQuery(params.Query, params.Expansions)
+	rows, err := r.DB.Query(ctx, query, params.Query, params.Limit, params.Offset, expanded)
 	if err != nil {
 		return nil, err
 	}
@@ -229,9 +333,10 @@ func (r *PostgresRepo) GetPopularVideos(ctx context.Context, limit int) ([]domai
 		FROM app.videos v
 		LEFT JOIN app.video_counters vc ON v.id = vc.video_id
 		ORDER BY 
-			-- Популярность с затуханием по времени (более новые видео получают бонус)
+			-- Популярность с затуханием по времени (более новые видео получают бонус):
+			-- штраф растёт с возрастом видео
 			COALESCE(vc.views, 0) * 0.4 + 
-			COALESCE(vc.likes, 0) * 0.6 + 
+			COALESCE(vc.likes, 0) * 0.6 - 
 			EXTRACT(EPOCH FROM (now() - v.uploaded_at)) / 86400 * 0.01 DESC
 		LIMIT $1
 	`
@@ -268,16 +373,24 @@ func (r *PostgresRepo) GetPopularVideos(ctx context.Context, limit int) ([]domai
 	return videos, nil
 }
 
-// GetCommentedVideos возвращает видео с прокси по лайкам и завершениям
+// GetCommentedVideos возвращает самые обсуждаемые видео: по числу комментариев за последнюю неделю,
+// затем по общему числу комментариев
 func (r *PostgresRepo) GetCommentedVideos(ctx context.Context, limit int) ([]domain.Video, error) {
 	query := `
 		SELECT v.id, v.title, v.description, v.lang, v.tags, v.duration_s, v.uploaded_at, v.author_id
 		FROM app.videos v
+		LEFT JOIN (
+			SELECT c.video_id, COUNT(*) AS recent
+			FROM app.comments c
+			WHERE c.deleted_at IS NULL
+			  AND c.created_at >= now() - interval '7 days'
+			GROUP BY c.video_id
+		) rc ON rc.video_id = v.id
 		LEFT JOIN app.video_counters vc ON v.id = vc.video_id
 		ORDER BY 
-			-- Комбинация лайков и завершений просмотров
-			COALESCE(vc.likes, 0) * 0.7 + 
-			COALESCE(vc.completes, 0) * 0.3 DESC
+			COALESCE(rc.recent, 0) DESC,
+			COALESCE(vc.comments, 0) DESC,
+			v.uploaded_at DESC
 		LIMIT $1
 	`
 
@@ -285,32 +398,7 @@ func (r *PostgresRepo) GetCommentedVideos(ctx context.Context, limit int) ([]dom
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var videos []domain.Video
-	for rows.Next() {
-		var video domain.Video
-		err := rows.Scan(
-			&video.ID,
-			&video.Title,
-			&video.Description,
-			&video.Lang,
-			&video.Tags,
-			&video.DurationS,
-			&video.UploadedAt,
-			&video.AuthorID,
-		)
-		if err != nil {
-			return nil, err
-		}
-		videos = append(videos, video)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return videos, nil
+	return scanVideos(rows)
 }
 
 // GetRandomVideos возвращает случайную выборку видео
@@ -354,17 +442,29 @@ func (r *PostgresRepo) GetRandomVideos(ctx context.Context, limit int) ([]domain
 	return videos, nil
 }
 
-// GetUserTopTags возвращает топ теги пользователя на основе его активности
-func (r *PostgresRepo) GetUserTopTags(ctx context.Context, userID string) ([]string, error) {
+// GetUserTopTags возвращает топ теги пользователя на основе его активности.
+// Просмотры берутся из истории: удалённые из истории и сделанные на паузе не учитываются.
+// Лайк учитывается, только если его не сняли последующим unlike.
+// Неприязнь к тегу (дизлайки, "не интересно") вычитается из активности с весом 2,
+// теги без положительного остатка в топ не попадают.
+func (r *PostgresRepo) GetUserTopTags(ctx context.Context, userID string) ([]domain.TagWeight, error) {
 	query := `
-		WITH user_activity AS (
+		WITH likes AS (
+			SELECT DISTINCT ON (e.video_id) e.video_id, e.type
+			FROM app.events e
+			WHERE e.user_id = $1::uuid AND e.type IN ('like', 'unlike')
+			ORDER BY e.video_id, e.ts DESC
+		),
+		user_activity AS (
 			SELECT 
 				v.tags,
 				COUNT(*) as activity_count
-			FROM app.events e
-			JOIN app.videos v ON e.video_id = v.id
-			WHERE e.user_id = $1::uuid
-			  AND e.type IN ('view_start', 'like')
+			FROM (
+				SELECT h.video_id FROM app.watch_history h WHERE h.user_id = $1::uuid
+				UNION ALL
+				SELECT l.video_id FROM likes l WHERE l.type = 'like'
+			) a
+			JOIN app.videos v ON a.video_id = v.id
 			GROUP BY v.tags
 		),
 		tag_counts AS (
@@ -373,10 +473,12 @@ func (r *PostgresRepo) GetUserTopTags(ctx context.Context, userID string) ([]str
 				SUM(activity_count) as total_count
 			FROM user_activity
 			GROUP BY tag
-			ORDER BY total_count DESC
 		)
-		SELECT tag
-		FROM tag_counts
+		SELECT tc.tag, (tc.total_count - COALESCE(f.weight, 0) * 2)::float8
+		FROM tag_counts tc
+		LEFT JOIN app.user_tag_feedback f ON f.user_id = $1::uuid AND f.tag = lower(tc.tag)
+		WHERE tc.total_count - COALESCE(f.weight, 0) * 2 > 0
+		ORDER BY tc.total_count - COALESCE(f.weight, 0) * 2 DESC
 		LIMIT 10
 	`
 
@@ -386,10 +488,10 @@ func (r *PostgresRepo) GetUserTopTags(ctx context.Context, userID string) ([]str
 	}
 	defer rows.Close()
 
-	var tags []string
+	var tags []domain.TagWeight
 	for rows.Next() {
-		var tag string
-		if err := rows.Scan(&tag); err != nil {
+		var tag domain.TagWeight
+		if err := rows.Scan(&tag.Tag, &tag.Weight); err != nil {
 			return nil, err
 		}
 		tags = append(tags, tag)
@@ -403,7 +505,7 @@ func (r *PostgresRepo) GetUserTopTags(ctx context.Context, userID string) ([]str
 }
 
 // GetSessionTopTags возвращает топ теги сессии на основе активности
-func (r *PostgresRepo) GetSessionTopTags(ctx context.Context, sessionID string) ([]string, error) {
+func (r *PostgresRepo) GetSessionTopTags(ctx context.Context, sessionID string) ([]domain.TagWeight, error) {
 	query := `
 		WITH session_activity AS (
 			SELECT 
@@ -423,7 +525,7 @@ func (r *PostgresRepo) GetSessionTopTags(ctx context.Context, sessionID string)
 			GROUP BY tag
 			ORDER BY total_count DESC
 		)
-		SELECT tag
+		SELECT tag, total_count::float8
 		FROM tag_counts
 		LIMIT 10
 	`
@@ -434,10 +536,10 @@ func (r *PostgresRepo) GetSessionTopTags(ctx context.Context, sessionID string)
 	}
 	defer rows.Close()
 
-	var tags []string
+	var tags []domain.TagWeight
 	for rows.Next() {
-		var tag string
-		if err := rows.Scan(&tag); err != nil {
+		var tag domain.TagWeight
+		if err := rows.Scan(&tag.Tag, &tag.Weight); err != nil {
 			return nil, err
 		}
 		tags = append(tags, tag)
@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+)
+
+// GetBanditArms возвращает пул исследования: видео, загруженные после since
+// и ещё не набравшие maxViews просмотров, вместе со статистикой показов
+func (r *PostgresRepo) GetBanditArms(ctx context.Context, since time.Time, maxViews, limit int) ([]domain.BanditArm, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+videoColumns+`, COALESCE(b.impressions, 0), COALESCE(b.clicks, 0)
+		FROM app.videos v
+		LEFT JOIN app.video_counters vc ON vc.video_id = v.id
+		LEFT JOIN app.video_bandit b ON b.video_id = v.id
+		WHERE v.uploaded_at >= $1
+		  AND COALESCE(vc.views, 0) < $2
+		ORDER BY v.uploaded_at DESC
+		LIMIT $3
+	`, since, maxViews, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var arms []domain.BanditArm
+	for rows.Next() {
+		var a domain.BanditArm
+		if err := scanVideo(rows, &a.Video, &a.Impressions, &a.Clicks); err != nil {
+			return nil, err
+		}
+		arms = append(arms, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return arms, nil
+}
+
+// RecordBanditImpressions засчитывает показ видео в слоте exploration
+func (r *PostgresRepo) RecordBanditImpressions(ctx context.Context, videoIDs []uuid.UUID) error {
+	if len(videoIDs) == 0 {
+		return nil
+	}
+	_, err := r.DB.Exec(ctx, `
+		INSERT INTO app.video_bandit(video_id, impressions)
+		SELECT id, 1 FROM unnest($1::uuid[]) AS id
+		ON CONFLICT (video_id) DO UPDATE
+		SET impressions = app.video_bandit.impressions + 1,
+		    last_shown_at = now()
+	`, videoIDs)
+	return err
+}
@@ -0,0 +1,151 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const commentColumns = `c.id, c.video_id, c.user_id, c.parent_id,
+	CASE WHEN c.deleted_at IS NULL THEN c.body ELSE '' END,
+	c.likes, c.replies, c.deleted_at IS NOT NULL, c.created_at, c.updated_at`
+
+func scanComment(row pgx.Row, c *domain.Comment) error {
+	return row.Scan(&c.ID, &c.VideoID, &c.UserID, &c.ParentID, &c.Body,
+		&c.Likes, &c.Replies, &c.Deleted, &c.CreatedAt, &c.UpdatedAt)
+}
+
+func (r *PostgresRepo) VideoExists(ctx context.Context, videoID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.DB.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM app.videos WHERE id = $1)`, videoID).Scan(&exists)
+	return exists, err
+}
+
+func (r *PostgresRepo) GetComment(ctx context.Context, id uuid.UUID) (domain.Comment, error) {
+	var c domain.Comment
+	err := scanComment(r.DB.QueryRow(ctx, `SELECT `+commentColumns+` FROM app.comments c WHERE c.id = $1`, id), &c)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Comment{}, domain.ErrCommentNotFound
+	}
+	return c, err
+}
+
+func (r *PostgresRepo) InsertComment(ctx context.Context, tx Tx, c domain.Comment) (domain.Comment, error) {
+	err := tx.(*PostgresTx).tx.QueryRow(ctx, `
+		INSERT INTO app.comments(id, video_id, user_id, parent_id, body)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`, c.ID, c.VideoID, c.UserID, c.ParentID, c.Body).Scan(&c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
+
+func (r *PostgresRepo) UpdateCommentBody(ctx context.Context, id uuid.UUID, body string) (domain.Comment, error) {
+	var c domain.Comment
+	err := scanComment(r.DB.QueryRow(ctx, `
+		UPDATE app.comments c
+		SET body = $2, updated_at = now()
+		WHERE c.id = $1 AND c.deleted_at IS NULL
+		RETURNING `+commentColumns,
+		id, body), &c)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Comment{}, domain.ErrCommentNotFound
+	}
+	return c, err
+}
+
+// SoftDeleteComment помечает комментарий удалённым; false — если он уже был удалён
+func (r *PostgresRepo) SoftDeleteComment(ctx context.Context, tx Tx, id uuid.UUID) (bool, error) {
+	cmd, err := tx.(*PostgresTx).tx.Exec(ctx, `
+		UPDATE app.comments
+		SET deleted_at = now(), updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+func (r *PostgresRepo) IncCommentReplies(ctx context.Context, tx Tx, id uuid.UUID, delta int) error {
+	_, err := tx.(*PostgresTx).tx.Exec(ctx, `
+		UPDATE app.comments SET replies = GREATEST(replies + $2, 0) WHERE id = $1
+	`, id, delta)
+	return err
+}
+
+// IncVideoComments изменяет денормализованный счётчик комментариев видео.
+// last_event_at не трогаем: это время последнего принятого события, на него опираются сверка и свежесть.
+func (r *PostgresRepo) IncVideoComments(ctx context.Context, tx Tx, videoID uuid.UUID, delta int) error {
+	_, err := tx.(*PostgresTx).tx.Exec(ctx, `
+		INSERT INTO app.video_counters(video_id, comments)
+		VALUES ($1, GREATEST($2, 0))
+		ON CONFLICT (video_id) DO UPDATE
+		SET comments = GREATEST(app.video_counters.comments + $2, 0)
+	`, videoID, delta)
+	return err
+}
+
+// ListComments возвращает комментарии по убыванию (created_at, id), начиная после курсора.
+// Удалённые комментарии без ответов не возвращаются.
+func (r *PostgresRepo) ListComments(ctx context.Context, params domain.CommentListParams) ([]domain.Comment, error) {
+	var cursorTS, cursorID any
+	if params.Cursor != nil {
+		cursorTS, cursorID = params.Cursor.TS, params.Cursor.ID
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+commentColumns+`
+		FROM app.comments c
+		WHERE c.video_id = $1
+		  AND c.parent_id IS NOT DISTINCT FROM $2
+		  AND (c.deleted_at IS NULL OR c.replies > 0)
+		  AND ($3::timestamptz IS NULL OR (c.created_at, c.id) < ($3::timestamptz, $4::uuid))
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT $5
+	`, params.VideoID, params.ParentID, cursorTS, cursorID, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.Comment
+	for rows.Next() {
+		var c domain.Comment
+		if err := scanComment(rows, &c); err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// LikeComment ставит лайк комментарию; false — если лайк уже стоял
+func (r *PostgresRepo) LikeComment(ctx context.Context, tx Tx, commentID, userID uuid.UUID) (bool, error) {
+	cmd, err := tx.(*PostgresTx).tx.Exec(ctx, `
+		INSERT INTO app.comment_likes(comment_id, user_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, commentID, userID)
+	if err != nil || cmd.RowsAffected() == 0 {
+		return false, err
+	}
+	_, err = tx.(*PostgresTx).tx.Exec(ctx, `UPDATE app.comments SET likes = likes + 1 WHERE id = $1`, commentID)
+	return err == nil, err
+}
+
+// UnlikeComment снимает лайк; false — если лайка не было
+func (r *PostgresRepo) UnlikeComment(ctx context.Context, tx Tx, commentID, userID uuid.UUID) (bool, error) {
+	cmd, err := tx.(*PostgresTx).tx.Exec(ctx, `
+		DELETE FROM app.comment_likes WHERE comment_id = $1 AND user_id = $2
+	`, commentID, userID)
+	if err != nil || cmd.RowsAffected() == 0 {
+		return false, err
+	}
+	_, err = tx.(*PostgresTx).tx.Exec(ctx, `UPDATE app.comments SET likes = GREATEST(likes - 1, 0) WHERE id = $1`, commentID)
+	return err == nil, err
+}
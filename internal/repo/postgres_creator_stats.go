@@ -0,0 +1,149 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/arasvet/microtube/internal/domain"
+)
+
+// Условия запросов статистики автора: $1 — автор, $2 — конкретное видео или NULL,
+// $3 и $4 — первый и последний день периода (полночь UTC)
+const (
+	creatorVideos = `v.author_id = $1 AND ($2::uuid IS NULL OR v.id = $2)`
+	creatorDays   = `d.day >= ($3::timestamptz AT TIME ZONE 'UTC')::date AND d.day <= ($4::timestamptz AT TIME ZONE 'UTC')::date`
+	creatorEvents = `e.ts >= $3::timestamptz AND e.ts < $4::timestamptz + interval '1 day' AND e.flag IS NULL`
+)
+
+// CreatorDaily суммы метрик видео автора по дням периода
+func (r *PostgresRepo) CreatorDaily(ctx context.Context, f domain.CreatorStatsFilter) ([]domain.CreatorDayRaw, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT d.day::timestamp AT TIME ZONE 'UTC',
+			SUM(d.views), SUM(d.completes), SUM(d.likes), SUM(d.dwell_ms_sum),
+			SUM(d.views * COALESCE(v.duration_s, 0))
+		FROM app.video_daily d
+		JOIN app.videos v ON v.id = d.video_id
+		WHERE `+creatorVideos+`
+		  AND `+creatorDays+`
+		GROUP BY d.day
+		ORDER BY d.day
+	`, f.AuthorID, f.VideoID, f.From, f.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.CreatorDayRaw
+	for rows.Next() {
+		var d domain.CreatorDayRaw
+		if err := rows.Scan(&d.Day, &d.Views, &d.Completes, &d.Likes, &d.DwellMsSum, &d.ViewSecs); err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreatorTraffic начатые просмотры видео автора по выдаче, из которой они пришли
+func (r *PostgresRepo) CreatorTraffic(ctx context.Context, f domain.CreatorStatsFilter) ([]domain.TrafficSource, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT COALESCE(s.surface, 'other'), COUNT(*)
+		FROM app.events e
+		JOIN app.videos v ON v.id = e.video_id
+		LEFT JOIN app.serves s ON s.serve_id = e.serve_id
+		WHERE `+creatorVideos+`
+		  AND e.type = 'view_start'
+		  AND `+creatorEvents+`
+		GROUP BY 1
+		ORDER BY 2 DESC
+	`, f.AuthorID, f.VideoID, f.From, f.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.TrafficSource
+	for rows.Next() {
+		var t domain.TrafficSource
+		if err := rows.Scan(&t.Source, &t.Views); err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreatorTopQueries поисковые запросы, после которых открывали видео автора.
+// Клик по результату и просмотр из той же поисковой выдачи в одной сессии считаются один раз.
+func (r *PostgresRepo) CreatorTopQueries(ctx context.Context, f domain.CreatorStatsFilter, limit int) ([]domain.QueryCount, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT lower(COALESCE(NULLIF(e.query, ''), s.query)) AS q, COUNT(DISTINCT e.session_id)
+		FROM app.events e
+		JOIN app.videos v ON v.id = e.video_id
+		LEFT JOIN app.serves s ON s.serve_id = e.serve_id AND s.surface = 'search'
+		WHERE `+creatorVideos+`
+		  AND (e.type = 'click_result' OR (e.type = 'view_start' AND s.query IS NOT NULL))
+		  AND COALESCE(NULLIF(e.query, ''), s.query, '') <> ''
+		  AND `+creatorEvents+`
+		GROUP BY 1
+		ORDER BY 2 DESC, 1
+		LIMIT $5
+	`, f.AuthorID, f.VideoID, f.From, f.To, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.QueryCount
+	for rows.Next() {
+		var q domain.QueryCount
+		if err := rows.Scan(&q.Query, &q.Sessions); err != nil {
+			return nil, err
+		}
+		res = append(res, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreatorVideoTotals итоги периода по каждому видео автора, самые просматриваемые первыми
+func (r *PostgresRepo) CreatorVideoTotals(ctx context.Context, f domain.CreatorStatsFilter, limit int) ([]domain.VideoStatsSummary, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT v.id, v.title, v.description, v.lang, v.tags, v.duration_s, v.uploaded_at, v.author_id,
+			COALESCE(SUM(d.views), 0), COALESCE(SUM(d.completes), 0), COALESCE(SUM(d.likes), 0),
+			COALESCE(SUM(d.dwell_ms_sum), 0), COALESCE(SUM(d.views * COALESCE(v.duration_s, 0)), 0)
+		FROM app.videos v
+		LEFT JOIN app.video_daily d
+		  ON d.video_id = v.id AND `+creatorDays+`
+		WHERE `+creatorVideos+`
+		GROUP BY v.id
+		ORDER BY 9 DESC, v.uploaded_at DESC
+		LIMIT $5
+	`, f.AuthorID, f.VideoID, f.From, f.To, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.VideoStatsSummary
+	for rows.Next() {
+		var v domain.Video
+		var t domain.CreatorDayRaw
+		if err := rows.Scan(&v.ID, &v.Title, &v.Description, &v.Lang, &v.Tags, &v.DurationS, &v.UploadedAt, &v.AuthorID,
+			&t.Views, &t.Completes, &t.Likes, &t.DwellMsSum, &t.ViewSecs); err != nil {
+			return nil, err
+		}
+		res = append(res, domain.VideoStatsSummary{Video: v, Stats: domain.NewCreatorDayStats(t)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,253 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const experimentColumns = `e.id, e.key, e.description, e.surface, e.status,
+	e.created_at, e.updated_at, e.started_at, e.stopped_at`
+
+// CreateExperiment сохраняет эксперимент вместе с вариантами
+func (r *PostgresRepo) CreateExperiment(ctx context.Context, e domain.Experiment) (domain.Experiment, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return domain.Experiment{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO app.experiments(id, key, description, surface, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`, e.ID, e.Key, e.Description, e.Surface, e.Status).Scan(&e.CreatedAt, &e.UpdatedAt)
+	if isUniqueViolation(err) {
+		return domain.Experiment{}, domain.ErrExperimentExists
+	}
+	if err != nil {
+		return domain.Experiment{}, err
+	}
+
+	for i, v := range e.Variants {
+		params := v.Params
+		if params == nil {
+			params = map[string]string{}
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO app.experiment_variants(experiment_id, name, position, weight, params)
+			VALUES ($1, $2, $3, $4, $5)
+		`, e.ID, v.Name, i, v.Weight, params); err != nil {
+			return domain.Experiment{}, err
+		}
+	}
+
+	return e, tx.Commit(ctx)
+}
+
+// GetExperiment возвращает эксперимент с вариантами
+func (r *PostgresRepo) GetExperiment(ctx context.Context, id uuid.UUID) (domain.Experiment, error) {
+	rows, err := r.DB.Query(ctx, `SELECT `+experimentColumns+` FROM app.experiments e WHERE e.id = $1`, id)
+	if err != nil {
+		return domain.Experiment{}, err
+	}
+	list, err := r.scanExperiments(ctx, rows)
+	if err != nil {
+		return domain.Experiment{}, err
+	}
+	if len(list) == 0 {
+		return domain.Experiment{}, domain.ErrExperimentNotFound
+	}
+	return list[0], nil
+}
+
+// ListExperiments возвращает все эксперименты, новые первыми
+func (r *PostgresRepo) ListExperiments(ctx context.Context) ([]domain.Experiment, error) {
+	rows, err := r.DB.Query(ctx, `SELECT `+experimentColumns+` FROM app.experiments e ORDER BY e.created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	return r.scanExperiments(ctx, rows)
+}
+
+// ListRunningExperiments возвращает идущие эксперименты для назначения вариантов
+func (r *PostgresRepo) ListRunningExperiments(ctx context.Context) ([]domain.Experiment, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+experimentColumns+` FROM app.experiments e WHERE e.status = 'running'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return r.scanExperiments(ctx, rows)
+}
+
+// SetExperimentStatus переводит эксперимент из from в to.
+// Запуск второго эксперимента на той же поверхности — ErrExperimentConflict.
+func (r *PostgresRepo) SetExperimentStatus(ctx context.Context, id uuid.UUID, from, to domain.ExperimentStatus) error {
+	cmd, err := r.DB.Exec(ctx, `
+		UPDATE app.experiments
+		SET status = $3,
+		    updated_at = now(),
+		    started_at = CASE WHEN $3 = 'running' THEN now() ELSE started_at END,
+		    stopped_at = CASE WHEN $3 = 'stopped' THEN now() ELSE stopped_at END
+		WHERE id = $1 AND status = $2
+	`, id, from, to)
+	if isUniqueViolation(err) {
+		return domain.ErrExperimentConflict
+	}
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		if _, err := r.GetExperiment(ctx, id); err != nil {
+			return err
+		}
+		return domain.ErrExperimentState
+	}
+	return nil
+}
+
+// LogExposure запоминает первую выдачу варианта единице трафика; повторные выдачи игнорируются
+func (r *PostgresRepo) LogExposure(ctx context.Context, e domain.Exposure) error {
+	_, err := r.DB.Exec(ctx, `
+		INSERT INTO app.experiment_exposures(experiment_id, unit_id, variant, user_id, session_id)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (experiment_id, unit_id) DO NOTHING
+	`, e.ExperimentID, e.UnitID, e.Variant, e.UserID, e.SessionID)
+	return err
+}
+
+// ExperimentCounts считает события единиц каждого варианта после их первой выдачи
+// и до остановки эксперимента (until == nil — по текущий момент)
+func (r *PostgresRepo) ExperimentCounts(ctx context.Context, id uuid.UUID, until *time.Time) ([]domain.VariantCounts, error) {
+	rows, err := r.DB.Query(ctx, `
+		WITH x AS (
+			SELECT variant, unit_id, user_id, session_id, first_exposed_at
+			FROM app.experiment_exposures
+			WHERE experiment_id = $1
+		),
+		-- два соединения вместо OR, чтобы каждое шло по своему индексу events
+		ev AS (
+			SELECT x.unit_id, e.type, e.dwell_ms
+			FROM x
+			JOIN app.events e ON e.user_id = x.user_id
+			WHERE x.user_id IS NOT NULL
+			  AND e.ts >= x.first_exposed_at
+			  AND e.flag IS NULL
+			  AND ($2::timestamptz IS NULL OR e.ts <= $2)
+			UNION ALL
+			SELECT x.unit_id, e.type, e.dwell_ms
+			FROM x
+			JOIN app.events e ON e.session_id = x.session_id
+			WHERE x.user_id IS NULL
+			  AND e.ts >= x.first_exposed_at
+			  AND e.flag IS NULL
+			  AND ($2::timestamptz IS NULL OR e.ts <= $2)
+		),
+		per_unit AS (
+			SELECT unit_id,
+				bool_or(type IN ('view_start', 'click_result')) AS clicked,
+				COUNT(*) FILTER (WHERE type = 'view_start') AS starts,
+				COUNT(*) FILTER (WHERE type = 'view_complete') AS completes,
+				COUNT(dwell_ms) FILTER (WHERE dwell_ms > 0) AS dwell_n,
+				COALESCE(SUM(dwell_ms) FILTER (WHERE dwell_ms > 0), 0)::float8 AS dwell_sum,
+				COALESCE(SUM(dwell_ms::float8 * dwell_ms) FILTER (WHERE dwell_ms > 0), 0) AS dwell_sqr
+			FROM ev
+			GROUP BY unit_id
+		),
+		agg AS (
+			SELECT x.variant,
+				COUNT(*) AS units,
+				COUNT(*) FILTER (WHERE p.clicked) AS click_units,
+				COALESCE(SUM(p.starts), 0)::bigint AS starts,
+				COALESCE(SUM(p.completes), 0)::bigint AS completes,
+				COALESCE(SUM(p.dwell_n), 0)::bigint AS dwell_n,
+				COALESCE(SUM(p.dwell_sum), 0) AS dwell_sum,
+				COALESCE(SUM(p.dwell_sqr), 0) AS dwell_sqr
+			FROM x
+			LEFT JOIN per_unit p ON p.unit_id = x.unit_id
+			GROUP BY x.variant
+		)
+		SELECT v.name,
+			COALESCE(a.units, 0), COALESCE(a.click_units, 0),
+			COALESCE(a.starts, 0), COALESCE(a.completes, 0),
+			COALESCE(a.dwell_n, 0), COALESCE(a.dwell_sum, 0), COALESCE(a.dwell_sqr, 0)
+		FROM app.experiment_variants v
+		LEFT JOIN agg a ON a.variant = v.name
+		WHERE v.experiment_id = $1
+		ORDER BY v.position
+	`, id, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.VariantCounts
+	for rows.Next() {
+		var c domain.VariantCounts
+		if err := rows.Scan(&c.Variant, &c.Units, &c.ClickUnits, &c.Starts, &c.Completes,
+			&c.DwellN, &c.DwellSum, &c.DwellSqrSum); err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// scanExperiments читает эксперименты и подгружает их варианты одним запросом
+func (r *PostgresRepo) scanExperiments(ctx context.Context, rows pgx.Rows) ([]domain.Experiment, error) {
+	defer rows.Close()
+
+	var list []domain.Experiment
+	index := map[uuid.UUID]int{}
+	for rows.Next() {
+		var e domain.Experiment
+		if err := rows.Scan(&e.ID, &e.Key, &e.Description, &e.Surface, &e.Status,
+			&e.CreatedAt, &e.UpdatedAt, &e.StartedAt, &e.StoppedAt); err != nil {
+			return nil, err
+		}
+		index[e.ID] = len(list)
+		list = append(list, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return list, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(list))
+	for _, e := range list {
+		ids = append(ids, e.ID)
+	}
+	vrows, err := r.DB.Query(ctx, `
+		SELECT experiment_id, name, weight, params
+		FROM app.experiment_variants
+		WHERE experiment_id = ANY($1::uuid[])
+		ORDER BY experiment_id, position
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer vrows.Close()
+
+	for vrows.Next() {
+		var id uuid.UUID
+		var v domain.Variant
+		if err := vrows.Scan(&id, &v.Name, &v.Weight, &v.Params); err != nil {
+			return nil, err
+		}
+		i := index[id]
+		list[i].Variants = append(list[i].Variants, v)
+	}
+	if err := vrows.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
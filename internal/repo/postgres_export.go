@@ -0,0 +1,96 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+)
+
+// exportSource таблица набора выгрузки: выражения колонок, условие периода и порядок строк.
+// Выражения приводят значения к типам domain.ExportColumnType: text, bigint, timestamptz.
+type exportSource struct {
+	from    string
+	columns map[string]string
+	period  string // $1 и $2 — первый и последний день (полночь UTC)
+	order   string
+}
+
+var exportSources = map[domain.ExportDataset]exportSource{
+	domain.ExportVideoDaily: {
+		from: `app.video_daily d`,
+		columns: map[string]string{
+			"day":          `d.day::timestamp AT TIME ZONE 'UTC'`,
+			"video_id":     `d.video_id::text`,
+			"views":        `d.views`,
+			"completes":    `d.completes`,
+			"likes":        `d.likes`,
+			"clicks":       `d.clicks`,
+			"impressions":  `d.impressions`,
+			"dwell_ms_sum": `d.dwell_ms_sum`,
+			"dislikes":     `d.dislikes`,
+			"unlikes":      `d.unlikes`,
+			"hides":        `d.hides`,
+		},
+		period: `d.day >= ($1::timestamptz AT TIME ZONE 'UTC')::date AND d.day <= ($2::timestamptz AT TIME ZONE 'UTC')::date`,
+		order:  `d.day, d.video_id`,
+	},
+	domain.ExportEvents: {
+		from: `app.events e`,
+		columns: map[string]string{
+			"event_id":    `e.event_id::text`,
+			"ts":          `e.ts`,
+			"type":        `e.type::text`,
+			"session_id":  `e.session_id`,
+			"user_id":     `e.user_id::text`,
+			"video_id":    `e.video_id::text`,
+			"query":       `e.query`,
+			"dwell_ms":    `e.dwell_ms::bigint`,
+			"tag":         `e.tag`,
+			"serve_id":    `e.serve_id::text`,
+			"position_ms": `e.position_ms::bigint`,
+			"flag":        `e.flag`,
+		},
+		period: `e.ts >= $1 AND e.ts < $2::timestamptz + interval '1 day'`,
+		order:  `e.ts, e.event_id`,
+	},
+}
+
+// ExportRows построчно передаёт в fn выбранные колонки набора за дни [from, to].
+// Строки читаются из курсора по мере записи, весь результат в памяти не держится.
+func (r *PostgresRepo) ExportRows(ctx context.Context, dataset domain.ExportDataset, from, to time.Time,
+	columns []string, fn func(values []any) error) error {
+	src, ok := exportSources[dataset]
+	if !ok {
+		return domain.ErrUnknownExportDataset
+	}
+	exprs := make([]string, 0, len(columns))
+	for _, c := range columns {
+		expr, ok := src.columns[c]
+		if !ok {
+			return domain.ErrInvalidExport
+		}
+		exprs = append(exprs, expr)
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY %s`,
+		strings.Join(exprs, ", "), src.from, src.period, src.order)
+	rows, err := r.DB.Query(ctx, query, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return err
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
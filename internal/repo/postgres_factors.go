@@ -0,0 +1,165 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Interactions возвращает агрегированные сигналы пользователей по видео с момента since.
+// userID != nil — только сигналы одного пользователя (для fold-in).
+func (r *PostgresRepo) Interactions(ctx context.Context, since time.Time, userID *uuid.UUID) ([]domain.Interaction, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT e.user_id, e.video_id,
+			SUM(CASE e.type
+				WHEN 'view_start' THEN 1
+				WHEN 'view_complete' THEN 2
+				WHEN 'like' THEN 3
+				WHEN 'unlike' THEN -3
+				ELSE 0
+			END)::float8 AS weight
+		FROM app.events e
+		WHERE e.ts >= $1
+		  AND e.user_id IS NOT NULL
+		  AND e.video_id IS NOT NULL
+		  AND e.flag IS NULL
+		  AND ($2::uuid IS NULL OR e.user_id = $2)
+		  -- пауза истории выключает и персонализацию по просмотрам
+		  AND NOT EXISTS (
+			SELECT 1 FROM app.user_settings s WHERE s.user_id = e.user_id AND s.history_paused
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM app.user_hidden_videos hv
+			WHERE hv.user_id = e.user_id AND hv.video_id = e.video_id
+		  )
+		GROUP BY e.user_id, e.video_id
+		HAVING NOT bool_or(e.type = 'dislike')
+		   AND SUM(CASE e.type
+				WHEN 'view_start' THEN 1
+				WHEN 'view_complete' THEN 2
+				WHEN 'like' THEN 3
+				WHEN 'unlike' THEN -3
+				ELSE 0
+			END) > 0
+	`, since.UTC(), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.Interaction
+	for rows.Next() {
+		var in domain.Interaction
+		if err := rows.Scan(&in.UserID, &in.VideoID, &in.Weight); err != nil {
+			return nil, err
+		}
+		res = append(res, in)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SaveFactorModel атомарно заменяет факторы пользователей и видео новой моделью
+func (r *PostgresRepo) SaveFactorModel(ctx context.Context, model domain.FactorModel,
+	users map[uuid.UUID][]float64, videos map[uuid.UUID][]float64) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM app.user_factors`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM app.video_factors`); err != nil {
+		return err
+	}
+
+	userRows := make([][]any, 0, len(users))
+	for id, f := range users {
+		userRows = append(userRows, []any{id, f})
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"app", "user_factors"},
+		[]string{"user_id", "factors"}, pgx.CopyFromRows(userRows)); err != nil {
+		return err
+	}
+
+	videoRows := make([][]any, 0, len(videos))
+	for id, f := range videos {
+		videoRows = append(videoRows, []any{id, f})
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"app", "video_factors"},
+		[]string{"video_id", "factors"}, pgx.CopyFromRows(videoRows)); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO app.als_model(id, version, factors, lambda, alpha, users, videos, trained_at)
+		VALUES (1, $1, $2, $3, $4, $5, $6, now())
+		ON CONFLICT (id) DO UPDATE
+		SET version = EXCLUDED.version, factors = EXCLUDED.factors,
+		    lambda = EXCLUDED.lambda, alpha = EXCLUDED.alpha,
+		    users = EXCLUDED.users, videos = EXCLUDED.videos,
+		    trained_at = EXCLUDED.trained_at
+	`, model.Version, model.Factors, model.Lambda, model.Alpha, len(users), len(videos)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// LoadVideoFactors возвращает текущую модель и факторы всех видео.
+// Пока модель не обучена — пустая модель без ошибки.
+func (r *PostgresRepo) LoadVideoFactors(ctx context.Context) (domain.FactorModel, []uuid.UUID, [][]float64, error) {
+	var model domain.FactorModel
+	err := r.DB.QueryRow(ctx, `
+		SELECT version, factors, lambda, alpha, users, videos, trained_at FROM app.als_model WHERE id = 1
+	`).Scan(&model.Version, &model.Factors, &model.Lambda, &model.Alpha, &model.Users, &model.Videos, &model.TrainedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.FactorModel{}, nil, nil, nil
+	}
+	if err != nil {
+		return domain.FactorModel{}, nil, nil, err
+	}
+
+	rows, err := r.DB.Query(ctx, `SELECT video_id, factors FROM app.video_factors`)
+	if err != nil {
+		return domain.FactorModel{}, nil, nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]uuid.UUID, 0, model.Videos)
+	vecs := make([][]float64, 0, model.Videos)
+	for rows.Next() {
+		var id uuid.UUID
+		var f []float64
+		if err := rows.Scan(&id, &f); err != nil {
+			return domain.FactorModel{}, nil, nil, err
+		}
+		if len(f) != model.Factors {
+			continue // остаток старой модели другой размерности
+		}
+		ids = append(ids, id)
+		vecs = append(vecs, f)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.FactorModel{}, nil, nil, err
+	}
+	return model, ids, vecs, nil
+}
+
+// GetUserFactors возвращает факторы пользователя; nil — пользователя не было в обучении
+func (r *PostgresRepo) GetUserFactors(ctx context.Context, userID uuid.UUID) ([]float64, error) {
+	var f []float64
+	err := r.DB.QueryRow(ctx, `SELECT factors FROM app.user_factors WHERE user_id = $1`, userID).Scan(&f)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return f, err
+}
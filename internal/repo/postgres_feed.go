@@ -0,0 +1,132 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// videoColumns колонки app.videos (алиас v) в порядке, ожидаемом scanVideo
+const videoColumns = `v.id, v.title, v.description, v.lang, v.tags, v.duration_s, v.uploaded_at, v.author_id`
+
+func scanVideo(row pgx.Row, video *domain.Video, extra ...any) error {
+	dest := []any{
+		&video.ID,
+		&video.Title,
+		&video.Description,
+		&video.Lang,
+		&video.Tags,
+		&video.DurationS,
+		&video.UploadedAt,
+		&video.AuthorID,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
+
+func scanVideos(rows pgx.Rows) ([]domain.Video, error) {
+	defer rows.Close()
+
+	var videos []domain.Video
+	for rows.Next() {
+		var video domain.Video
+		if err := scanVideo(rows, &video); err != nil {
+			return nil, err
+		}
+		videos = append(videos, video)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return videos, nil
+}
+
+// GetVideosByIDs возвращает видео в порядке переданных идентификаторов (отсутствующие пропускаются)
+func (r *PostgresRepo) GetVideosByIDs(ctx context.Context, ids []uuid.UUID) ([]domain.Video, error) {
+	if len(ids) == 0 {
+		return []domain.Video{}, nil
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+videoColumns+`
+		FROM app.videos v
+		WHERE v.id = ANY($1::uuid[])
+		ORDER BY array_position($1::uuid[], v.id)
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	return scanVideos(rows)
+}
+
+// TrendingCounters возвращает взвешенную активность видео по окнам час/сутки/неделя.
+// Час считается по сырым событиям, сутки и неделя — по video_daily: сутки как сегодняшний
+// день плюс доля вчерашнего, попадающая в скользящие 24 часа, неделя — с затуханием
+// по дням с периодом полураспада halfLifeDays.
+func (r *PostgresRepo) TrendingCounters(ctx context.Context, halfLifeDays float64) ([]domain.TrendingCounters, error) {
+	rows, err := r.DB.Query(ctx, `
+		WITH clock AS (
+			SELECT
+				(now() AT TIME ZONE 'UTC')::date AS today,
+				1 - EXTRACT(EPOCH FROM (now() AT TIME ZONE 'UTC') - date_trunc('day', now() AT TIME ZONE 'UTC')) / 86400 AS yesterday_share
+		),
+		hourly AS (
+			SELECT e.video_id,
+				SUM(CASE e.type
+					WHEN 'view_start' THEN 1
+					WHEN 'view_complete' THEN 1.5
+					WHEN 'like' THEN 2
+					ELSE 0
+				END) AS eng
+			FROM app.events e
+			WHERE e.ts >= now() - interval '1 hour'
+			  AND e.video_id IS NOT NULL
+			  AND e.flag IS NULL
+			GROUP BY e.video_id
+		),
+		daily AS (
+			SELECT vd.video_id,
+				SUM(
+					(vd.views + vd.completes * 1.5 + vd.likes * 2) *
+					CASE
+						WHEN vd.day = c.today THEN 1
+						WHEN vd.day = c.today - 1 THEN c.yesterday_share
+						ELSE 0
+					END
+				) AS day_eng,
+				SUM(
+					(vd.views + vd.completes * 1.5 + vd.likes * 2) *
+					power(0.5, (c.today - vd.day)::float8 / $1)
+				) AS week_eng,
+				SUM(vd.views + vd.completes + vd.likes) AS week_raw
+			FROM app.video_daily vd, clock c
+			WHERE vd.day >= c.today - 6
+			GROUP BY vd.video_id
+		)
+		SELECT v.id, v.uploaded_at,
+			COALESCE(h.eng, 0)::float8,
+			COALESCE(d.day_eng, 0)::float8,
+			COALESCE(d.week_eng, 0)::float8,
+			COALESCE(d.week_raw, 0)::bigint
+		FROM daily d
+		FULL JOIN hourly h ON h.video_id = d.video_id
+		JOIN app.videos v ON v.id = COALESCE(d.video_id, h.video_id)
+	`, halfLifeDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.TrendingCounters
+	for rows.Next() {
+		var c domain.TrendingCounters
+		if err := rows.Scan(&c.VideoID, &c.UploadedAt, &c.Hour, &c.Day, &c.Week, &c.WeekRaw); err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
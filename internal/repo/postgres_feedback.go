@@ -0,0 +1,88 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+)
+
+// UpsertNegativeFeedback сохраняет негативный сигнал пользователя:
+// hide_video скрывает видео, dislike и not_interested_tag копят неприязнь к тегам.
+// Гостевые события влияют только на счётчики.
+func (r *PostgresRepo) UpsertNegativeFeedback(ctx context.Context, tx Tx, e domain.Event) error {
+	if e.UserID == uuid.Nil {
+		return nil
+	}
+
+	t := tx.(*PostgresTx).tx
+	var err error
+	switch e.Type {
+	case domain.EventHideVideo:
+		_, err = t.Exec(ctx, `
+			INSERT INTO app.user_hidden_videos(user_id, video_id, hidden_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, video_id) DO NOTHING
+		`, e.UserID, e.VideoID, e.TS.UTC())
+	case domain.EventDislike:
+		_, err = t.Exec(ctx, `
+			INSERT INTO app.user_tag_feedback(user_id, tag, weight)
+			SELECT DISTINCT $1::uuid, lower(tag), $3::float8
+			FROM app.videos v, unnest(v.tags) AS tag
+			WHERE v.id = $2
+			ON CONFLICT (user_id, tag) DO UPDATE
+			SET weight = app.user_tag_feedback.weight + EXCLUDED.weight, updated_at = now()
+		`, e.UserID, e.VideoID, domain.DislikeTagWeight)
+	case domain.EventNotInterestedTag:
+		_, err = t.Exec(ctx, `
+			INSERT INTO app.user_tag_feedback(user_id, tag, weight)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, tag) DO UPDATE
+			SET weight = app.user_tag_feedback.weight + EXCLUDED.weight, updated_at = now()
+		`, e.UserID, e.Tag, domain.NotInterestedTagWeight)
+	}
+	return err
+}
+
+// GetNegativeFeedback возвращает скрытые видео и неприязнь к тегам пользователя
+func (r *PostgresRepo) GetNegativeFeedback(ctx context.Context, userID uuid.UUID) (domain.NegativeFeedback, error) {
+	fb := domain.NegativeFeedback{
+		Hidden:     map[uuid.UUID]bool{},
+		TagWeights: map[string]float64{},
+	}
+
+	rows, err := r.DB.Query(ctx, `SELECT video_id FROM app.user_hidden_videos WHERE user_id = $1`, userID)
+	if err != nil {
+		return domain.NegativeFeedback{}, err
+	}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return domain.NegativeFeedback{}, err
+		}
+		fb.Hidden[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return domain.NegativeFeedback{}, err
+	}
+
+	rows, err = r.DB.Query(ctx, `SELECT tag, weight FROM app.user_tag_feedback WHERE user_id = $1 AND weight > 0`, userID)
+	if err != nil {
+		return domain.NegativeFeedback{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tag string
+		var weight float64
+		if err := rows.Scan(&tag, &weight); err != nil {
+			return domain.NegativeFeedback{}, err
+		}
+		fb.TagWeights[tag] = weight
+	}
+	if err := rows.Err(); err != nil {
+		return domain.NegativeFeedback{}, err
+	}
+	return fb, nil
+}
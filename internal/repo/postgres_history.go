@@ -0,0 +1,139 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// UpsertWatchHistory обновляет историю просмотров по событию view_start/view_complete.
+// Гостевые события и события пользователей с паузой истории игнорируются.
+// Новый view_start после досмотра начинает просмотр заново.
+func (r *PostgresRepo) UpsertWatchHistory(ctx context.Context, tx Tx, e domain.Event) error {
+	if e.UserID == uuid.Nil || e.VideoID == uuid.Nil {
+		return nil
+	}
+	if e.Type != domain.EventViewStart && e.Type != domain.EventViewComplete {
+		return nil
+	}
+
+	completed := e.Type == domain.EventViewComplete
+	_, err := tx.(*PostgresTx).tx.Exec(ctx, `
+		INSERT INTO app.watch_history(user_id, video_id, first_watched_at, last_watched_at, watched_ms, completed)
+		SELECT $1, $2, $3, $3, $4, $5
+		WHERE NOT EXISTS (
+			SELECT 1 FROM app.user_settings s WHERE s.user_id = $1 AND s.history_paused
+		)
+		ON CONFLICT (user_id, video_id) DO UPDATE
+		SET watched_ms = CASE
+		        WHEN NOT EXCLUDED.completed AND app.watch_history.completed
+		             AND EXCLUDED.last_watched_at > app.watch_history.last_watched_at
+		            THEN EXCLUDED.watched_ms
+		        ELSE GREATEST(app.watch_history.watched_ms, EXCLUDED.watched_ms)
+		    END,
+		    completed = CASE
+		        WHEN EXCLUDED.completed THEN true
+		        WHEN EXCLUDED.last_watched_at > app.watch_history.last_watched_at THEN false
+		        ELSE app.watch_history.completed
+		    END,
+		    last_watched_at = GREATEST(app.watch_history.last_watched_at, EXCLUDED.last_watched_at)
+	`, e.UserID, e.VideoID, e.TS.UTC(), e.DwellMs, completed)
+	return err
+}
+
+const historyColumns = videoColumns + `, h.last_watched_at, h.watched_ms, h.completed`
+
+func scanHistory(rows pgx.Rows) ([]domain.HistoryItem, error) {
+	defer rows.Close()
+
+	var items []domain.HistoryItem
+	for rows.Next() {
+		var item domain.HistoryItem
+		if err := scanVideo(rows, &item.Video, &item.LastWatchedAt, &item.WatchedMs, &item.Completed); err != nil {
+			return nil, err
+		}
+		item.FillResume()
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListWatchHistory возвращает историю пользователя по убыванию времени последнего просмотра
+func (r *PostgresRepo) ListWatchHistory(ctx context.Context, params domain.HistoryParams) ([]domain.HistoryItem, error) {
+	var cursorTS, cursorID any
+	if params.Cursor != nil {
+		cursorTS, cursorID = params.Cursor.TS, params.Cursor.ID
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+historyColumns+`
+		FROM app.watch_history h
+		JOIN app.videos v ON v.id = h.video_id
+		WHERE h.user_id = $1
+		  AND ($2::timestamptz IS NULL OR (h.last_watched_at, h.video_id) < ($2::timestamptz, $3::uuid))
+		ORDER BY h.last_watched_at DESC, h.video_id DESC
+		LIMIT $4
+	`, params.UserID, cursorTS, cursorID, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanHistory(rows)
+}
+
+// ListUnfinished возвращает начатые и не досмотренные видео по убыванию времени просмотра.
+// Видео, просмотренные почти до конца (domain.ResumeDoneShare), считаются досмотренными.
+func (r *PostgresRepo) ListUnfinished(ctx context.Context, userID uuid.UUID, limit int) ([]domain.HistoryItem, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+historyColumns+`
+		FROM app.watch_history h
+		JOIN app.videos v ON v.id = h.video_id
+		WHERE h.user_id = $1
+		  AND NOT h.completed
+		  AND h.watched_ms < v.duration_s * 1000 * $3::float8
+		ORDER BY h.last_watched_at DESC, h.video_id DESC
+		LIMIT $2
+	`, userID, limit, domain.ResumeDoneShare)
+	if err != nil {
+		return nil, err
+	}
+	return scanHistory(rows)
+}
+
+// DeleteWatchHistory удаляет из истории одно видео (videoID != nil) или всю историю пользователя
+func (r *PostgresRepo) DeleteWatchHistory(ctx context.Context, userID uuid.UUID, videoID *uuid.UUID) (int64, error) {
+	cmd, err := r.DB.Exec(ctx, `
+		DELETE FROM app.watch_history
+		WHERE user_id = $1 AND ($2::uuid IS NULL OR video_id = $2)
+	`, userID, videoID)
+	if err != nil {
+		return 0, err
+	}
+	return cmd.RowsAffected(), nil
+}
+
+func (r *PostgresRepo) GetHistorySettings(ctx context.Context, userID uuid.UUID) (domain.HistorySettings, error) {
+	var s domain.HistorySettings
+	err := r.DB.QueryRow(ctx, `
+		SELECT history_paused FROM app.user_settings WHERE user_id = $1
+	`, userID).Scan(&s.Paused)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.HistorySettings{}, nil
+	}
+	return s, err
+}
+
+func (r *PostgresRepo) SetHistoryPaused(ctx context.Context, userID uuid.UUID, paused bool) error {
+	_, err := r.DB.Exec(ctx, `
+		INSERT INTO app.user_settings(user_id, history_paused, updated_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (user_id) DO UPDATE
+		SET history_paused = EXCLUDED.history_paused, updated_at = now()
+	`, userID, paused)
+	return err
+}
@@ -0,0 +1,219 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// LogServes сохраняет пачку выдач и их показы одной транзакцией и прибавляет показы
+// к video_daily и video_hourly: одна строка видео за день (час) обновляется раз на пачку,
+// а не на каждый запрос к выдаче
+func (r *PostgresRepo) LogServes(ctx context.Context, serves []domain.Serve) error {
+	if len(serves) == 0 {
+		return nil
+	}
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	serveRows := make([][]interface{}, 0, len(serves))
+	var rows [][]interface{}
+	var ids []uuid.UUID
+	var hours []time.Time
+	for _, s := range serves {
+		var userID, sessionID, query interface{}
+		if s.UserID != uuid.Nil {
+			userID = s.UserID
+		}
+		if s.SessionID != "" {
+			sessionID = s.SessionID
+		}
+		if s.Query != "" {
+			query = s.Query
+		}
+		serveRows = append(serveRows, []interface{}{s.ID, s.TS.UTC(), string(s.Surface), userID, sessionID, query})
+		for _, it := range s.Items {
+			rows = append(rows, []interface{}{s.ID, it.Position, it.VideoID, string(s.Surface), it.Source, it.Reason, s.TS.UTC()})
+			ids = append(ids, it.VideoID)
+			hours = append(hours, s.TS.UTC().Truncate(time.Hour))
+		}
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"app", "serves"},
+		[]string{"serve_id", "ts", "surface", "user_id", "session_id", "query"},
+		pgx.CopyFromRows(serveRows)); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return tx.Commit(ctx)
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"app", "impressions"},
+		[]string{"serve_id", "position", "video_id", "surface", "source", "reason", "ts"},
+		pgx.CopyFromRows(rows)); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		WITH imp AS (
+			SELECT id, h FROM unnest($1::uuid[], $2::timestamptz[]) AS u(id, h)
+		),
+		daily AS (
+			INSERT INTO app.video_daily(video_id, day, impressions)
+			SELECT id, (h AT TIME ZONE 'UTC')::date, COUNT(*) FROM imp GROUP BY 1, 2
+			ON CONFLICT (video_id, day) DO UPDATE
+			SET impressions = app.video_daily.impressions + EXCLUDED.impressions
+		)
+		INSERT INTO app.video_hourly(video_id, hour, impressions)
+		SELECT id, h, COUNT(*) FROM imp GROUP BY 1, 2
+		ON CONFLICT (video_id, hour) DO UPDATE
+		SET impressions = app.video_hourly.impressions + EXCLUDED.impressions
+	`, ids, hours); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// AttributeClick отмечает показ, из которого пришёл клик или начатый просмотр, и засчитывает клик
+// в суточную и почасовую статистику видео за время показа. Повторные клики по тому же показу не считаются.
+// Клик по показу из слота exploration — награда бандита: органические просмотры, поиск и подписки
+// на апостериор CTR свежих видео не влияют.
+// Возвращает false, если показа нет: выдача ещё не записана (LogServes пишет пачками), не записалась
+// или serve_id чужой — такой клик вызывающий считает по времени события.
+func (r *PostgresRepo) AttributeClick(ctx context.Context, tx Tx, e domain.Event) (bool, error) {
+	if e.ServeID == uuid.Nil || e.VideoID == uuid.Nil {
+		return false, nil
+	}
+	if e.Type != domain.EventClickResult && e.Type != domain.EventViewStart {
+		return false, nil
+	}
+	var shown bool
+	err := tx.(*PostgresTx).tx.QueryRow(ctx, `
+		WITH hit AS (
+			UPDATE app.impressions
+			SET clicked_at = $3
+			WHERE serve_id = $1 AND video_id = $2 AND clicked_at IS NULL
+			RETURNING video_id, ts, reason
+		),
+		bandit AS (
+			INSERT INTO app.video_bandit(video_id, clicks)
+			SELECT video_id, 1 FROM hit WHERE reason = $4
+			ON CONFLICT (video_id) DO UPDATE
+			SET clicks = app.video_bandit.clicks + 1
+		),
+		daily AS (
+			INSERT INTO app.video_daily(video_id, day, clicks)
+			SELECT video_id, (ts AT TIME ZONE 'UTC')::date, COUNT(*) FROM hit GROUP BY 1, 2
+			ON CONFLICT (video_id, day) DO UPDATE
+			SET clicks = app.video_daily.clicks + EXCLUDED.clicks
+		),
+		hourly AS (
+			INSERT INTO app.video_hourly(video_id, hour, clicks)
+			SELECT video_id, date_trunc('hour', ts AT TIME ZONE 'UTC') AT TIME ZONE 'UTC', COUNT(*) FROM hit GROUP BY 1, 2
+			ON CONFLICT (video_id, hour) DO UPDATE
+			SET clicks = app.video_hourly.clicks + EXCLUDED.clicks
+		)
+		SELECT EXISTS(SELECT 1 FROM app.impressions WHERE serve_id = $1 AND video_id = $2)
+	`, e.ServeID, e.VideoID, e.TS.UTC(), string(domain.ReasonExploration)).Scan(&shown)
+	return shown, err
+}
+
+// StatsPositionCTR кликабельность первых maxPosition позиций выдачи за период.
+// surface == "" — по всем выдачам.
+func (r *PostgresRepo) StatsPositionCTR(ctx context.Context, surface domain.ExperimentSurface, from, to time.Time, maxPosition int) ([]domain.PositionCTR, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT position, COUNT(*), COUNT(clicked_at)
+		FROM app.impressions
+		WHERE ts >= $1 AND ts < $2
+		  AND ($3 = '' OR surface = $3)
+		  AND position <= $4
+		GROUP BY position
+		ORDER BY position
+	`, from.UTC(), to.UTC(), string(surface), maxPosition)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.PositionCTR
+	for rows.Next() {
+		var p domain.PositionCTR
+		if err := rows.Scan(&p.Position, &p.Impressions, &p.Clicks); err != nil {
+			return nil, err
+		}
+		if p.Impressions > 0 {
+			p.CTR = float64(p.Clicks) / float64(p.Impressions)
+		}
+		res = append(res, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// serveDeleteBatch сколько выдач удаляется одной транзакцией: короткие удаления не держат
+// блокировки impressions, в которые пишет AttributeClick
+const serveDeleteBatch = 5000
+
+// ServesHorizon граница удалённых по сроку хранения выдач: раньше неё показов нет.
+// Нулевое время — ничего не удалялось.
+func (r *PostgresRepo) ServesHorizon(ctx context.Context) (time.Time, error) {
+	var horizon *time.Time
+	if err := r.DB.QueryRow(ctx, `SELECT MAX(range_to) FROM app.serves_retention`).Scan(&horizon); err != nil {
+		return time.Time{}, err
+	}
+	if horizon == nil {
+		return time.Time{}, nil
+	}
+	return horizon.UTC(), nil
+}
+
+// OldestServe время самой ранней сохранённой выдачи; нулевое — выдач нет
+func (r *PostgresRepo) OldestServe(ctx context.Context) (time.Time, error) {
+	var oldest *time.Time
+	if err := r.DB.QueryRow(ctx, `SELECT MIN(ts) FROM app.serves`).Scan(&oldest); err != nil {
+		return time.Time{}, err
+	}
+	if oldest == nil {
+		return time.Time{}, nil
+	}
+	return oldest.UTC(), nil
+}
+
+// DeleteServes удаляет выдачи раньше before вместе с показами (ON DELETE CASCADE) и возвращает
+// число удалённых выдач. Граница пишется в serves_retention до удаления: прерванное удаление
+// не оставляет дней, где сверка приняла бы недоудалённые показы за полные.
+func (r *PostgresRepo) DeleteServes(ctx context.Context, before time.Time) (int64, error) {
+	if _, err := r.DB.Exec(ctx, `
+		INSERT INTO app.serves_retention(range_to, serves) VALUES ($1, 0)
+		ON CONFLICT (range_to) DO NOTHING
+	`, before.UTC()); err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for {
+		cmd, err := r.DB.Exec(ctx, `
+			DELETE FROM app.serves
+			WHERE serve_id IN (SELECT serve_id FROM app.serves WHERE ts < $1 LIMIT $2)
+		`, before.UTC(), serveDeleteBatch)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += cmd.RowsAffected()
+		if cmd.RowsAffected() < serveDeleteBatch {
+			break
+		}
+	}
+
+	_, err := r.DB.Exec(ctx, `
+		UPDATE app.serves_retention SET serves = serves + $2, deleted_at = now() WHERE range_to = $1
+	`, before.UTC(), deleted)
+	return deleted, err
+}
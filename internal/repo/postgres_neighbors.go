@@ -0,0 +1,152 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+)
+
+// RebuildCovisitation пересчитывает соседей всех видео по событиям с момента since.
+// Видео сессии упорядочиваются по первому просмотру; пара засчитывается, если между
+// просмотрами не больше maxGap позиций, с весом 1/расстояние. Итоговый score нормирован
+// на популярность обоих видео (как косинус), чтобы хиты не становились соседями всего подряд.
+// Таблица заменяется в одной транзакции, читатели видят либо старый, либо новый набор.
+func (r *PostgresRepo) RebuildCovisitation(ctx context.Context, since time.Time, maxGap, minSessions, topN int) (int64, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM app.video_neighbors`); err != nil {
+		return 0, err
+	}
+	cmd, err := tx.Exec(ctx, `
+		WITH views AS (
+			SELECT e.session_id, e.video_id, MIN(e.ts) AS first_ts
+			FROM app.events e
+			WHERE e.ts >= $1
+			  AND e.type IN ('view_start', 'view_complete')
+			  AND e.video_id IS NOT NULL
+			  AND e.flag IS NULL
+			  -- пауза истории выключает и персонализацию по просмотрам
+			  AND NOT EXISTS (
+				SELECT 1 FROM app.user_settings s WHERE s.user_id = e.user_id AND s.history_paused
+			  )
+			GROUP BY e.session_id, e.video_id
+		),
+		seq AS (
+			SELECT session_id, video_id,
+				row_number() OVER (PARTITION BY session_id ORDER BY first_ts, video_id) AS pos
+			FROM views
+		),
+		pairs AS (
+			SELECT a.video_id, b.video_id AS neighbor_id,
+				SUM(1.0 / abs(a.pos - b.pos)) AS weight,
+				COUNT(*) AS sessions
+			FROM seq a
+			JOIN seq b ON b.session_id = a.session_id
+			          AND b.video_id <> a.video_id
+			          AND abs(a.pos - b.pos) <= $2
+			GROUP BY a.video_id, b.video_id
+		),
+		support AS (
+			SELECT video_id, COUNT(*) AS n FROM views GROUP BY video_id
+		),
+		scored AS (
+			SELECT p.video_id, p.neighbor_id, p.sessions,
+				p.weight / sqrt(sa.n * sb.n) AS score
+			FROM pairs p
+			JOIN support sa ON sa.video_id = p.video_id
+			JOIN support sb ON sb.video_id = p.neighbor_id
+			WHERE p.sessions >= $3
+		),
+		ranked AS (
+			SELECT *, row_number() OVER (PARTITION BY video_id ORDER BY score DESC, neighbor_id) AS rn
+			FROM scored
+		)
+		INSERT INTO app.video_neighbors(video_id, neighbor_id, score, sessions)
+		SELECT video_id, neighbor_id, score, sessions
+		FROM ranked
+		WHERE rn <= $4
+	`, since.UTC(), maxGap, minSessions, topN)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return cmd.RowsAffected(), nil
+}
+
+// GetCovisitedVideos возвращает соседей видео seeds по убыванию суммарного score.
+// Сами seeds в выдачу не попадают.
+func (r *PostgresRepo) GetCovisitedVideos(ctx context.Context, seeds []uuid.UUID, limit int) ([]domain.RecommendationResult, error) {
+	if len(seeds) == 0 {
+		return nil, nil
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+videoColumns+`, n.score, n.seed
+		FROM (
+			SELECT neighbor_id, SUM(score) AS score,
+				(array_agg(video_id ORDER BY score DESC))[1] AS seed
+			FROM app.video_neighbors
+			WHERE video_id = ANY($1::uuid[])
+			  AND neighbor_id <> ALL($1::uuid[])
+			GROUP BY neighbor_id
+		) n
+		JOIN app.videos v ON v.id = n.neighbor_id
+		ORDER BY n.score DESC, v.id
+		LIMIT $2
+	`, seeds, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.RecommendationResult
+	for rows.Next() {
+		item := domain.RecommendationResult{Reason: domain.ReasonSimilar}
+		var seed uuid.UUID
+		if err := scanVideo(rows, &item.Video, &item.Score, &seed); err != nil {
+			return nil, err
+		}
+		item.Explanation.SimilarTo = &seed
+		res = append(res, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetRecentlyWatchedIDs возвращает последние просмотренные пользователем видео
+func (r *PostgresRepo) GetRecentlyWatchedIDs(ctx context.Context, userID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT video_id
+		FROM app.watch_history
+		WHERE user_id = $1
+		ORDER BY last_watched_at DESC, video_id DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// EnsureEventPartitions создаёт партиции events с текущего месяца на ahead месяцев вперёд
+// (миграция 0020, ensure_event_partitions) и возвращает число созданных.
+// События за месяцы без партиции (раньше первой или дальше ahead) попадают в events_default;
+// для их месяцев тоже создаются партиции — функция переносит туда строки, и дальше на них
+// действует срок хранения, а вместе с партицией удаляются и их строки event_ids.
+func (r *PostgresRepo) EnsureEventPartitions(ctx context.Context, ahead int) (int, error) {
+	var created int
+	err := r.DB.QueryRow(ctx, `
+		SELECT app.ensure_event_partitions(now(), now() + make_interval(months => $1))
+	`, ahead).Scan(&created)
+	if err != nil {
+		return 0, err
+	}
+
+	var drained int
+	err = r.DB.QueryRow(ctx, `
+		SELECT COALESCE(SUM(app.ensure_event_partitions(m, m)), 0)::int
+		FROM (
+			SELECT DISTINCT date_trunc('month', ts AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS m
+			FROM app.events_default
+		) d
+	`).Scan(&drained)
+	return created + drained, err
+}
+
+// EventPartitions помесячные партиции events по возрастанию месяца
+func (r *PostgresRepo) EventPartitions(ctx context.Context) ([]domain.EventPartition, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'app.events'::regclass
+	`)
+	if err != nil {
+		return nil, err
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	var out []domain.EventPartition
+	for _, name := range names {
+		if p, ok := domain.ParseEventPartition(name); ok {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].From.Before(out[j].From) })
+	return out, nil
+}
+
+// EventsHorizon граница удалённых по сроку хранения событий: раньше неё events пуст.
+// Нулевое время — ничего не удалялось.
+func (r *PostgresRepo) EventsHorizon(ctx context.Context) (time.Time, error) {
+	var horizon *time.Time
+	if err := r.DB.QueryRow(ctx, `SELECT MAX(range_to) FROM app.events_retention`).Scan(&horizon); err != nil {
+		return time.Time{}, err
+	}
+	if horizon == nil {
+		return time.Time{}, nil
+	}
+	return horizon.UTC(), nil
+}
+
+// DropEventPartition удаляет партицию одной транзакцией: вклад её событий в video_counters
+// переносится в video_counters_base, партиция отсоединяется и удаляется вместе с её строками
+// event_ids, удаление пишется в events_retention. Возвращает число удалённых событий.
+func (r *PostgresRepo) DropEventPartition(ctx context.Context, p domain.EventPartition) (int64, error) {
+	table := pgx.Identifier{"app", p.Name}.Sanitize()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('event_partitions'))`); err != nil {
+		return 0, err
+	}
+	// DETACH ждёт долгие чтения events (выгрузки), а приём событий встаёт в очередь за ним:
+	// лучше не дождаться и повторить в следующий запуск, чем остановить приём
+	if _, err := tx.Exec(ctx, `SET LOCAL lock_timeout = '5s'`); err != nil {
+		return 0, err
+	}
+	// Приём отклоняет события старше срока хранения, но часы реплик могут расходиться:
+	// запоздавшее событие за этот месяц ждёт коммита, иначе его не будет ни в base, ни в events
+	if _, err := tx.Exec(ctx, `LOCK TABLE `+table+` IN SHARE MODE`); err != nil {
+		return 0, err
+	}
+
+	var events int64
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM `+table).Scan(&events); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO app.video_counters_base AS b (video_id, views, completes, likes, unlikes, dislikes, hides)
+		SELECT video_id,
+			COUNT(*) FILTER (WHERE type = 'view_start'),
+			COUNT(*) FILTER (WHERE type = 'view_complete'),
+			COUNT(*) FILTER (WHERE type = 'like'),
+			COUNT(*) FILTER (WHERE type = 'unlike'),
+			COUNT(*) FILTER (WHERE type = 'dislike'),
+			COUNT(*) FILTER (WHERE type = 'hide_video')
+		FROM `+table+`
+		WHERE video_id IS NOT NULL AND flag IS NULL
+		GROUP BY video_id
+		ON CONFLICT (video_id) DO UPDATE
+		SET views = b.views + EXCLUDED.views,
+		    completes = b.completes + EXCLUDED.completes,
+		    likes = b.likes + EXCLUDED.likes,
+		    unlikes = b.unlikes + EXCLUDED.unlikes,
+		    dislikes = b.dislikes + EXCLUDED.dislikes,
+		    hides = b.hides + EXCLUDED.hides
+	`); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `ALTER TABLE app.events DETACH PARTITION `+table); err != nil {
+		return 0, fmt.Errorf("detach %s: %w", p.Name, err)
+	}
+	if _, err := tx.Exec(ctx, `DROP TABLE `+table); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM app.event_ids WHERE ts >= $1 AND ts < $2
+	`, p.From, p.To); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO app.events_retention(partition, range_from, range_to, events)
+		VALUES ($1, $2, $3, $4)
+	`, p.Name, p.From, p.To, events); err != nil {
+		return 0, err
+	}
+	return events, tx.Commit(ctx)
+}
@@ -0,0 +1,246 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const playlistColumns = `p.id, p.owner_id, p.title, p.visibility, p.kind,
+	(SELECT count(*) FROM app.playlist_items i WHERE i.playlist_id = p.id),
+	p.created_at, p.updated_at`
+
+func scanPlaylist(row pgx.Row, p *domain.Playlist) error {
+	return row.Scan(&p.ID, &p.OwnerID, &p.Title, &p.Visibility, &p.Kind,
+		&p.ItemsCount, &p.CreatedAt, &p.UpdatedAt)
+}
+
+func (r *PostgresRepo) CreatePlaylist(ctx context.Context, p domain.Playlist) (domain.Playlist, error) {
+	err := r.DB.QueryRow(ctx, `
+		INSERT INTO app.playlists(id, owner_id, title, visibility, kind)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`, p.ID, p.OwnerID, p.Title, p.Visibility, p.Kind).Scan(&p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
+
+func (r *PostgresRepo) GetPlaylist(ctx context.Context, id uuid.UUID) (domain.Playlist, error) {
+	var p domain.Playlist
+	err := scanPlaylist(r.DB.QueryRow(ctx, `SELECT `+playlistColumns+` FROM app.playlists p WHERE p.id = $1`, id), &p)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Playlist{}, domain.ErrPlaylistNotFound
+	}
+	return p, err
+}
+
+// GetOrCreateWatchLater возвращает список "смотреть позже" пользователя, создавая его при первом обращении
+func (r *PostgresRepo) GetOrCreateWatchLater(ctx context.Context, ownerID uuid.UUID) (domain.Playlist, error) {
+	_, err := r.DB.Exec(ctx, `
+		INSERT INTO app.playlists(id, owner_id, title, visibility, kind)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (owner_id) WHERE kind = 'watch_later' DO NOTHING
+	`, uuid.New(), ownerID, domain.WatchLaterTitle, domain.PlaylistPrivate, domain.PlaylistKindWatchLater)
+	if err != nil {
+		return domain.Playlist{}, err
+	}
+
+	var p domain.Playlist
+	err = scanPlaylist(r.DB.QueryRow(ctx, `
+		SELECT `+playlistColumns+`
+		FROM app.playlists p
+		WHERE p.owner_id = $1 AND p.kind = 'watch_later'
+	`, ownerID), &p)
+	return p, err
+}
+
+// ListUserPlaylists возвращает плейлисты пользователя; onlyPublic — только публичные (для чужого профиля)
+func (r *PostgresRepo) ListUserPlaylists(ctx context.Context, ownerID uuid.UUID, onlyPublic bool) ([]domain.Playlist, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+playlistColumns+`
+		FROM app.playlists p
+		WHERE p.owner_id = $1 AND (NOT $2 OR p.visibility = 'public')
+		ORDER BY p.kind = 'watch_later' DESC, p.created_at DESC, p.id
+	`, ownerID, onlyPublic)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.Playlist
+	for rows.Next() {
+		var p domain.Playlist
+		if err := scanPlaylist(rows, &p); err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *PostgresRepo) UpdatePlaylist(ctx context.Context, id uuid.UUID, upd domain.PlaylistUpdate) (domain.Playlist, error) {
+	var p domain.Playlist
+	err := scanPlaylist(r.DB.QueryRow(ctx, `
+		UPDATE app.playlists p
+		SET title = COALESCE($2, p.title),
+		    visibility = COALESCE($3, p.visibility),
+		    updated_at = now()
+		WHERE p.id = $1
+		RETURNING `+playlistColumns,
+		id, upd.Title, upd.Visibility), &p)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Playlist{}, domain.ErrPlaylistNotFound
+	}
+	return p, err
+}
+
+func (r *PostgresRepo) DeletePlaylist(ctx context.Context, id uuid.UUID) error {
+	cmd, err := r.DB.Exec(ctx, `DELETE FROM app.playlists WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrPlaylistNotFound
+	}
+	return nil
+}
+
+// ListPlaylistItems возвращает видео плейлиста по порядку позиций
+func (r *PostgresRepo) ListPlaylistItems(ctx context.Context, playlistID uuid.UUID) ([]domain.PlaylistItem, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+videoColumns+`, i.position, i.added_at
+		FROM app.playlist_items i
+		JOIN app.videos v ON v.id = i.video_id
+		WHERE i.playlist_id = $1
+		ORDER BY i.position
+	`, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []domain.PlaylistItem
+	for rows.Next() {
+		var item domain.PlaylistItem
+		if err := scanVideo(rows, &item.Video, &item.Position, &item.AddedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// LockPlaylist блокирует плейлист до конца транзакции и возвращает число видео в нём.
+// Все изменения порядка идут через эту блокировку, поэтому позиции остаются непрерывными.
+func (r *PostgresRepo) LockPlaylist(ctx context.Context, tx Tx, playlistID uuid.UUID) (int, error) {
+	var count int
+	err := tx.(*PostgresTx).tx.QueryRow(ctx, `
+		SELECT (SELECT count(*) FROM app.playlist_items i WHERE i.playlist_id = p.id)
+		FROM app.playlists p
+		WHERE p.id = $1
+		FOR UPDATE
+	`, playlistID).Scan(&count)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, domain.ErrPlaylistNotFound
+	}
+	return count, err
+}
+
+// InsertPlaylistItem вставляет видео на позицию position, сдвигая последующие
+func (r *PostgresRepo) InsertPlaylistItem(ctx context.Context, tx Tx, playlistID, videoID uuid.UUID, position int) error {
+	t := tx.(*PostgresTx).tx
+
+	var exists bool
+	if err := t.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM app.playlist_items WHERE playlist_id = $1 AND video_id = $2)
+	`, playlistID, videoID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrPlaylistItemExists
+	}
+
+	if _, err := t.Exec(ctx, `
+		UPDATE app.playlist_items SET position = position + 1
+		WHERE playlist_id = $1 AND position >= $2
+	`, playlistID, position); err != nil {
+		return err
+	}
+	if _, err := t.Exec(ctx, `
+		INSERT INTO app.playlist_items(playlist_id, video_id, position) VALUES ($1, $2, $3)
+	`, playlistID, videoID, position); err != nil {
+		return err
+	}
+	return touchPlaylist(ctx, tx, playlistID)
+}
+
+// MovePlaylistItem переносит видео на позицию position, сдвигая видео между старой и новой позицией
+func (r *PostgresRepo) MovePlaylistItem(ctx context.Context, tx Tx, playlistID, videoID uuid.UUID, position int) error {
+	t := tx.(*PostgresTx).tx
+
+	var from int
+	err := t.QueryRow(ctx, `
+		SELECT position FROM app.playlist_items WHERE playlist_id = $1 AND video_id = $2
+	`, playlistID, videoID).Scan(&from)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrPlaylistItemNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if from == position {
+		return nil
+	}
+
+	if _, err := t.Exec(ctx, `
+		UPDATE app.playlist_items
+		SET position = CASE
+		        WHEN video_id = $2 THEN $4
+		        WHEN $3 < $4 THEN position - 1
+		        ELSE position + 1
+		    END
+		WHERE playlist_id = $1
+		  AND position BETWEEN LEAST($3::int, $4::int) AND GREATEST($3::int, $4::int)
+	`, playlistID, videoID, from, position); err != nil {
+		return err
+	}
+	return touchPlaylist(ctx, tx, playlistID)
+}
+
+// DeletePlaylistItem убирает видео из плейлиста и закрывает образовавшуюся дыру в позициях
+func (r *PostgresRepo) DeletePlaylistItem(ctx context.Context, tx Tx, playlistID, videoID uuid.UUID) error {
+	t := tx.(*PostgresTx).tx
+
+	var position int
+	err := t.QueryRow(ctx, `
+		DELETE FROM app.playlist_items WHERE playlist_id = $1 AND video_id = $2
+		RETURNING position
+	`, playlistID, videoID).Scan(&position)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrPlaylistItemNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := t.Exec(ctx, `
+		UPDATE app.playlist_items SET position = position - 1
+		WHERE playlist_id = $1 AND position > $2
+	`, playlistID, position); err != nil {
+		return err
+	}
+	return touchPlaylist(ctx, tx, playlistID)
+}
+
+func touchPlaylist(ctx context.Context, tx Tx, playlistID uuid.UUID) error {
+	_, err := tx.(*PostgresTx).tx.Exec(ctx, `UPDATE app.playlists SET updated_at = now() WHERE id = $1`, playlistID)
+	return err
+}
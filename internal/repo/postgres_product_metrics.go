@@ -0,0 +1,231 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// productMetricsJob имя пересчёта в metrics_refresh и ключ advisory-lock
+const productMetricsJob = "product_metrics"
+
+// RefreshProductMetrics пересчитывает activity_daily, funnel_daily и cohort_weekly.
+// Пересчитываются дни, начиная с прошлого пересчёта минус lookback (события приходят с опозданием);
+// при первом запуске — вся история events. Задача идёт на каждой реплике API, а пересчёт нужен один:
+// если его держит другой инстанс (advisory-lock) или прошлый был меньше minAge назад,
+// пересчёт пропускается (ok == false). Возвращает первый пересчитанный день.
+func (r *PostgresRepo) RefreshProductMetrics(ctx context.Context, lookback, minAge time.Duration) (time.Time, bool, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext($1))`, productMetricsJob).Scan(&locked); err != nil {
+		return time.Time{}, false, err
+	}
+	if !locked {
+		return time.Time{}, false, nil
+	}
+
+	var since *time.Time
+	err = tx.QueryRow(ctx, `SELECT refreshed_at FROM app.metrics_refresh WHERE name = $1`, productMetricsJob).Scan(&since)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		if err := tx.QueryRow(ctx, `SELECT MIN(ts) FROM app.events`).Scan(&since); err != nil {
+			return time.Time{}, false, err
+		}
+	case err != nil:
+		return time.Time{}, false, err
+	case time.Since(*since) < minAge:
+		return time.Time{}, false, nil
+	default:
+		s := since.Add(-lookback)
+		since = &s
+	}
+	now := time.Now().UTC()
+	if since == nil {
+		// событий ещё нет
+		since = &now
+	}
+	day := since.UTC().Truncate(24 * time.Hour)
+	week := domain.StartOfWeek(day)
+
+	// $1 — первый пересчитываемый день; события берутся по индексу events_ts_idx
+	if _, err := tx.Exec(ctx, `DELETE FROM app.activity_daily WHERE day >= $1::date`, day); err != nil {
+		return time.Time{}, false, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO app.activity_daily(day, actor, user_id)
+		SELECT DISTINCT (e.ts AT TIME ZONE 'UTC')::date,
+			CASE WHEN e.user_id IS NOT NULL THEN 'u:' || e.user_id::text ELSE 's:' || e.session_id END,
+			e.user_id
+		FROM app.events e
+		WHERE e.ts >= $1 AND e.flag IS NULL
+	`, day); err != nil {
+		return time.Time{}, false, err
+	}
+
+	// Воронка по сессиям за день: просмотр засчитывается, только если видео кликнули в поиске этой сессии
+	if _, err := tx.Exec(ctx, `DELETE FROM app.funnel_daily WHERE day >= $1::date`, day); err != nil {
+		return time.Time{}, false, err
+	}
+	if _, err := tx.Exec(ctx, `
+		WITH ev AS (
+			SELECT (ts AT TIME ZONE 'UTC')::date AS day, session_id, type, video_id
+			FROM app.events
+			WHERE ts >= $1
+			  AND flag IS NULL
+			  AND type IN ('search_query', 'click_result', 'view_start', 'view_complete')
+		),
+		clicks AS (
+			SELECT DISTINCT day, session_id, video_id FROM ev WHERE type = 'click_result'
+		),
+		sessions AS (
+			SELECT ev.day, ev.session_id,
+				bool_or(ev.type = 'search_query') AS searched,
+				bool_or(ev.type = 'click_result') AS clicked,
+				bool_or(ev.type = 'view_start' AND c.video_id IS NOT NULL) AS viewed,
+				bool_or(ev.type = 'view_complete' AND c.video_id IS NOT NULL) AS completed
+			FROM ev
+			LEFT JOIN clicks c
+			  ON c.day = ev.day AND c.session_id = ev.session_id AND c.video_id = ev.video_id
+			GROUP BY ev.day, ev.session_id
+		)
+		INSERT INTO app.funnel_daily(day, searched, clicked, viewed, completed)
+		SELECT day,
+			COUNT(*) FILTER (WHERE searched),
+			COUNT(*) FILTER (WHERE searched AND clicked),
+			COUNT(*) FILTER (WHERE searched AND clicked AND viewed),
+			COUNT(*) FILTER (WHERE searched AND clicked AND viewed AND completed)
+		FROM sessions
+		WHERE searched
+		GROUP BY day
+	`, day); err != nil {
+		return time.Time{}, false, err
+	}
+
+	// Когорты пересчитываются с начала недели: ранние дни недели уже лежат в activity_daily
+	if _, err := tx.Exec(ctx, `DELETE FROM app.cohort_weekly WHERE activity_week >= $1::date`, week); err != nil {
+		return time.Time{}, false, err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO app.cohort_weekly(cohort_week, activity_week, users)
+		SELECT date_trunc('week', u.created_at AT TIME ZONE 'UTC')::date,
+			date_trunc('week', a.day)::date,
+			COUNT(DISTINCT a.user_id)
+		FROM app.activity_daily a
+		JOIN app.users u ON u.id = a.user_id
+		WHERE a.day >= $1::date
+		  AND a.user_id IS NOT NULL
+		GROUP BY 1, 2
+	`, week); err != nil {
+		return time.Time{}, false, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO app.metrics_refresh(name, refreshed_at) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET refreshed_at = EXCLUDED.refreshed_at
+	`, productMetricsJob, now); err != nil {
+		return time.Time{}, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return time.Time{}, false, err
+	}
+	return day, true, nil
+}
+
+// StatsActiveUsers DAU/WAU/MAU на каждый день [from, to] по activity_daily
+func (r *PostgresRepo) StatsActiveUsers(ctx context.Context, from, to time.Time) ([]domain.ActiveUsers, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT d::timestamp AT TIME ZONE 'UTC',
+			(SELECT COUNT(*) FROM app.activity_daily a WHERE a.day = d::date),
+			(SELECT COUNT(DISTINCT actor) FROM app.activity_daily a WHERE a.day > d::date - 7 AND a.day <= d::date),
+			(SELECT COUNT(DISTINCT actor) FROM app.activity_daily a WHERE a.day > d::date - 30 AND a.day <= d::date)
+		FROM generate_series(($1::timestamptz AT TIME ZONE 'UTC')::date,
+		                     ($2::timestamptz AT TIME ZONE 'UTC')::date, interval '1 day') AS d
+		ORDER BY d
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.ActiveUsers
+	for rows.Next() {
+		var a domain.ActiveUsers
+		if err := rows.Scan(&a.Day, &a.DAU, &a.WAU, &a.MAU); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// StatsCohorts размеры недельных когорт, зарегистрированных с недели from, и их активность по неделям
+func (r *PostgresRepo) StatsCohorts(ctx context.Context, from time.Time) ([]domain.CohortCell, []domain.CohortCell, error) {
+	sizes, err := r.cohortCells(ctx, `
+		SELECT date_trunc('week', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+			date_trunc('week', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+			COUNT(*)
+		FROM app.users
+		WHERE created_at >= $1
+		GROUP BY 1, 2
+		ORDER BY 1
+	`, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	active, err := r.cohortCells(ctx, `
+		SELECT cohort_week::timestamp AT TIME ZONE 'UTC', activity_week::timestamp AT TIME ZONE 'UTC', users
+		FROM app.cohort_weekly
+		WHERE cohort_week >= ($1::timestamptz AT TIME ZONE 'UTC')::date
+		  AND activity_week >= cohort_week
+		ORDER BY 1, 2
+	`, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sizes, active, nil
+}
+
+func (r *PostgresRepo) cohortCells(ctx context.Context, query string, from time.Time) ([]domain.CohortCell, error) {
+	rows, err := r.DB.Query(ctx, query, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.CohortCell
+	for rows.Next() {
+		var c domain.CohortCell
+		if err := rows.Scan(&c.Cohort, &c.Week, &c.Users); err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// StatsFunnel суммы воронки поиска за дни [from, to]
+func (r *PostgresRepo) StatsFunnel(ctx context.Context, from, to time.Time) (domain.FunnelCounts, error) {
+	var c domain.FunnelCounts
+	err := r.DB.QueryRow(ctx, `
+		SELECT COALESCE(SUM(searched), 0), COALESCE(SUM(clicked), 0),
+			COALESCE(SUM(viewed), 0), COALESCE(SUM(completed), 0)
+		FROM app.funnel_daily
+		WHERE day >= ($1::timestamptz AT TIME ZONE 'UTC')::date
+		  AND day <= ($2::timestamptz AT TIME ZONE 'UTC')::date
+	`, from, to).Scan(&c.Searched, &c.Clicked, &c.Viewed, &c.Completed)
+	return c, err
+}
@@ -0,0 +1,295 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// reconcileJob ключ session advisory-lock сверки счётчиков
+const reconcileJob = "reconcile_counters"
+
+// LockReconcile не даёт двум сверкам (API и cmd/reconcile) исправлять одни и те же строки:
+// дельты, посчитанные по одному снимку, нельзя применить дважды.
+// Блокировка держится на отдельном соединении до вызова unlock.
+func (r *PostgresRepo) LockReconcile(ctx context.Context) (func(), error) {
+	conn, err := r.DB.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, reconcileJob).Scan(&ok); err != nil {
+		conn.Release()
+		return nil, err
+	}
+	if !ok {
+		conn.Release()
+		return nil, domain.ErrReconcileRunning
+	}
+	return func() {
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, reconcileJob)
+		conn.Release()
+	}, nil
+}
+
+// ReconcileVideos видео с событиями за дни from..to
+func (r *PostgresRepo) ReconcileVideos(ctx context.Context, from, to time.Time) ([]uuid.UUID, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT DISTINCT video_id FROM app.events
+		WHERE video_id IS NOT NULL AND flag IS NULL AND ts >= $1 AND ts < $2
+	`, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// Ожидаемые значения повторяют логику приёма событий, помеченные антифродом события не учитываются:
+// показы и клики по записанным показам — из impressions по времени показа (LogServes, AttributeClick),
+// остальное — из events по времени события (UpsertVideoDaily, UpsertVideoHourly).
+// Клик считается по времени события, если к его приходу показ не был отмечен: выдача ещё
+// не записана или serve_id неизвестен (AttributeClick вернул false).
+// $1 — день (UTC), $2 — видео или NULL.
+const reconcileRollupSQL = `
+	WITH parts AS (
+		SELECT video_id, %[1]s AS bucket,
+			COUNT(*) FILTER (WHERE type = 'view_start') AS views,
+			COUNT(*) FILTER (WHERE type = 'view_complete') AS completes,
+			COUNT(*) FILTER (WHERE type = 'like') AS likes,
+			COUNT(*) FILTER (WHERE type = 'click_result' AND (serve_id IS NULL OR NOT EXISTS (
+				SELECT 1 FROM app.impressions i
+				WHERE i.serve_id = e.serve_id AND i.video_id = e.video_id AND i.clicked_at <= e.ts
+			))) AS clicks,
+			0 AS impressions,
+			COALESCE(SUM(dwell_ms), 0) AS dwell_ms_sum,
+			COUNT(*) FILTER (WHERE type = 'dislike') AS dislikes,
+			COUNT(*) FILTER (WHERE type = 'unlike') AS unlikes,
+			COUNT(*) FILTER (WHERE type = 'hide_video') AS hides
+		FROM app.events e
+		WHERE video_id IS NOT NULL AND flag IS NULL AND ts >= $1 AND ts < $1 + interval '1 day'
+		  AND ($2::uuid[] IS NULL OR video_id = ANY($2))
+		GROUP BY 1, 2
+		UNION ALL
+		SELECT video_id, %[1]s, 0, 0, 0, COUNT(clicked_at), COUNT(*), 0, 0, 0, 0
+		FROM app.impressions
+		WHERE ts >= $1 AND ts < $1 + interval '1 day'
+		  AND ($2::uuid[] IS NULL OR video_id = ANY($2))
+		GROUP BY 1, 2
+	)
+	SELECT video_id, bucket, SUM(views)::bigint, SUM(completes)::bigint, SUM(likes)::bigint,
+		SUM(clicks)::bigint, SUM(impressions)::bigint, SUM(dwell_ms_sum)::bigint, %[2]s
+	FROM parts
+	GROUP BY 1, 2
+`
+
+var reconcileExpectedSQL = map[domain.CounterTable]string{
+	domain.CounterVideoDaily: fmt.Sprintf(reconcileRollupSQL,
+		`date_trunc('day', ts AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'`,
+		`SUM(dislikes)::bigint, SUM(unlikes)::bigint, SUM(hides)::bigint`),
+	domain.CounterVideoHourly: fmt.Sprintf(reconcileRollupSQL,
+		`date_trunc('hour', ts AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'`,
+		`0, 0, 0`),
+	// $1 — видео. Вклад событий из удалённых по сроку хранения партиций лежит в video_counters_base.
+	// likes за всё время: like минус unlike. Приём событий не даёт счётчику уйти в минус
+	// на каждом шаге, поэтому при unlike раньше like значения могут законно разойтись.
+	domain.CounterVideoCounters: `
+		SELECT video_id, NULL::timestamptz, SUM(views)::bigint, SUM(completes)::bigint,
+			GREATEST(SUM(likes) - SUM(unlikes), 0)::bigint, 0, 0, 0,
+			SUM(dislikes)::bigint, 0, SUM(hides)::bigint
+		FROM (
+			SELECT video_id,
+				COUNT(*) FILTER (WHERE type = 'view_start') AS views,
+				COUNT(*) FILTER (WHERE type = 'view_complete') AS completes,
+				COUNT(*) FILTER (WHERE type = 'like') AS likes,
+				COUNT(*) FILTER (WHERE type = 'unlike') AS unlikes,
+				COUNT(*) FILTER (WHERE type = 'dislike') AS dislikes,
+				COUNT(*) FILTER (WHERE type = 'hide_video') AS hides
+			FROM app.events
+			WHERE video_id = ANY($1) AND flag IS NULL
+			GROUP BY video_id
+			UNION ALL
+			SELECT video_id, views, completes, likes, unlikes, dislikes, hides
+			FROM app.video_counters_base
+			WHERE video_id = ANY($1)
+		) c
+		GROUP BY video_id
+	`,
+}
+
+var reconcileActualSQL = map[domain.CounterTable]string{
+	domain.CounterVideoDaily: `
+		SELECT video_id, day::timestamp AT TIME ZONE 'UTC', views, completes, likes, clicks, impressions,
+			dwell_ms_sum, dislikes, unlikes, hides
+		FROM app.video_daily
+		WHERE day = ($1::timestamptz AT TIME ZONE 'UTC')::date AND ($2::uuid[] IS NULL OR video_id = ANY($2))
+	`,
+	domain.CounterVideoHourly: `
+		SELECT video_id, hour, views, completes, likes, clicks, impressions, dwell_ms_sum, 0, 0, 0
+		FROM app.video_hourly
+		WHERE hour >= $1 AND hour < $1 + interval '1 day' AND ($2::uuid[] IS NULL OR video_id = ANY($2))
+	`,
+	domain.CounterVideoCounters: `
+		SELECT video_id, NULL::timestamptz, views, completes, likes, 0, 0, 0, dislikes, 0, hides
+		FROM app.video_counters
+		WHERE video_id = ANY($1)
+	`,
+}
+
+// ReconcileRows пересчитывает строки table из событий и читает фактические строки.
+// Для video_daily и video_hourly берётся день day (UTC) и видео videoIDs (nil — все),
+// для video_counters — всё время по videoIDs. Обе выборки делаются в одном
+// REPEATABLE READ снимке: приём события меняет events и счётчики в одной транзакции,
+// поэтому в снимке они согласованы, даже пока идёт приём.
+func (r *PostgresRepo) ReconcileRows(ctx context.Context, table domain.CounterTable, day time.Time, videoIDs []uuid.UUID) ([]domain.CounterRow, []domain.CounterRow, error) {
+	expectedSQL, ok := reconcileExpectedSQL[table]
+	if !ok {
+		return nil, nil, fmt.Errorf("reconcile: unknown table %q", table)
+	}
+	tx, err := r.DB.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	args := []any{day, videoIDs}
+	if table == domain.CounterVideoCounters {
+		args = []any{videoIDs}
+	}
+	expected, err := queryCounterRows(ctx, tx, expectedSQL, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	actual, err := queryCounterRows(ctx, tx, reconcileActualSQL[table], args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return expected, actual, tx.Commit(ctx)
+}
+
+func queryCounterRows(ctx context.Context, tx pgx.Tx, sql string, args []any) ([]domain.CounterRow, error) {
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.CounterRow
+	for rows.Next() {
+		var row domain.CounterRow
+		var bucket *time.Time
+		v := &row.Values
+		if err := rows.Scan(&row.VideoID, &bucket, &v.Views, &v.Completes, &v.Likes, &v.Clicks,
+			&v.Impressions, &v.DwellMsSum, &v.Dislikes, &v.Unlikes, &v.Hides); err != nil {
+			return nil, err
+		}
+		if bucket != nil {
+			row.Bucket = bucket.UTC()
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+// Исправление прибавляет дельты, а не записывает значения: события, принятые после снимка
+// ReconcileRows, уже учтены в таблице и не попали в дельту, а приращения коммутируют,
+// так что приём не блокируется и не откатывается. $1 — видео, $2 — дни/часы, дальше дельты
+// в порядке колонок CounterValues; у video_counters корзины нет и колонки только свои.
+var reconcileApplySQL = map[domain.CounterTable]string{
+	domain.CounterVideoDaily: `
+		INSERT INTO app.video_daily AS t (video_id, day, views, completes, likes, clicks, impressions,
+		                                  dwell_ms_sum, dislikes, unlikes, hides)
+		SELECT v, (b AT TIME ZONE 'UTC')::date, views, completes, likes, clicks, impressions,
+			dwell, dislikes, unlikes, hides
+		FROM unnest($1::uuid[], $2::timestamptz[], $3::bigint[], $4::bigint[], $5::bigint[], $6::bigint[],
+		            $7::bigint[], $8::bigint[], $9::bigint[], $10::bigint[], $11::bigint[])
+		     AS d(v, b, views, completes, likes, clicks, impressions, dwell, dislikes, unlikes, hides)
+		ON CONFLICT (video_id, day) DO UPDATE
+		SET views = t.views + EXCLUDED.views,
+		    completes = t.completes + EXCLUDED.completes,
+		    likes = t.likes + EXCLUDED.likes,
+		    clicks = t.clicks + EXCLUDED.clicks,
+		    impressions = t.impressions + EXCLUDED.impressions,
+		    dwell_ms_sum = t.dwell_ms_sum + EXCLUDED.dwell_ms_sum,
+		    dislikes = t.dislikes + EXCLUDED.dislikes,
+		    unlikes = t.unlikes + EXCLUDED.unlikes,
+		    hides = t.hides + EXCLUDED.hides
+	`,
+	domain.CounterVideoHourly: `
+		INSERT INTO app.video_hourly AS t (video_id, hour, views, completes, likes, clicks, impressions, dwell_ms_sum)
+		SELECT v, b, views, completes, likes, clicks, impressions, dwell
+		FROM unnest($1::uuid[], $2::timestamptz[], $3::bigint[], $4::bigint[], $5::bigint[], $6::bigint[],
+		            $7::bigint[], $8::bigint[])
+		     AS d(v, b, views, completes, likes, clicks, impressions, dwell)
+		ON CONFLICT (video_id, hour) DO UPDATE
+		SET views = t.views + EXCLUDED.views,
+		    completes = t.completes + EXCLUDED.completes,
+		    likes = t.likes + EXCLUDED.likes,
+		    clicks = t.clicks + EXCLUDED.clicks,
+		    impressions = t.impressions + EXCLUDED.impressions,
+		    dwell_ms_sum = t.dwell_ms_sum + EXCLUDED.dwell_ms_sum
+	`,
+	domain.CounterVideoCounters: `
+		INSERT INTO app.video_counters AS t (video_id, views, completes, likes, dislikes, hides, last_event_at)
+		SELECT v, views, completes, GREATEST(likes, 0), dislikes, hides,
+			(SELECT MAX(e.ts) FROM app.events e WHERE e.video_id = d.v AND e.flag IS NULL)
+		FROM unnest($1::uuid[], $2::bigint[], $3::bigint[], $4::bigint[], $5::bigint[], $6::bigint[])
+		     AS d(v, views, completes, likes, dislikes, hides)
+		ON CONFLICT (video_id) DO UPDATE
+		SET views = t.views + EXCLUDED.views,
+		    completes = t.completes + EXCLUDED.completes,
+		    likes = GREATEST(t.likes + EXCLUDED.likes, 0),
+		    dislikes = t.dislikes + EXCLUDED.dislikes,
+		    hides = t.hides + EXCLUDED.hides,
+		    last_event_at = GREATEST(t.last_event_at, EXCLUDED.last_event_at)
+	`,
+}
+
+// ApplyCounterDeltas прибавляет Delta расхождений к table одной транзакцией
+func (r *PostgresRepo) ApplyCounterDeltas(ctx context.Context, table domain.CounterTable, diffs []domain.CounterDiff) error {
+	sql, ok := reconcileApplySQL[table]
+	if !ok {
+		return fmt.Errorf("reconcile: unknown table %q", table)
+	}
+	if len(diffs) == 0 {
+		return nil
+	}
+	n := len(diffs)
+	videos := make([]uuid.UUID, n)
+	buckets := make([]time.Time, n)
+	var cols [9][]int64
+	for i := range cols {
+		cols[i] = make([]int64, n)
+	}
+	for i, d := range diffs {
+		videos[i], buckets[i] = d.VideoID, d.Bucket
+		v := d.Delta
+		for j, x := range [9]int64{v.Views, v.Completes, v.Likes, v.Clicks, v.Impressions,
+			v.DwellMsSum, v.Dislikes, v.Unlikes, v.Hides} {
+			cols[j][i] = x
+		}
+	}
+	args := []any{videos, buckets}
+	for _, c := range cols {
+		args = append(args, c)
+	}
+	switch table {
+	case domain.CounterVideoHourly:
+		args = args[:8]
+	case domain.CounterVideoCounters:
+		args = []any{videos, cols[0], cols[1], cols[2], cols[6], cols[8]}
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,102 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// UpsertRetention обновляет гистограмму удержания по событию progress.
+// Зритель (сессия) учитывается в каждой корзине один раз: при продвижении дальше прежнего
+// максимума увеличиваются только новые корзины, перемотка назад ничего не меняет.
+func (r *PostgresRepo) UpsertRetention(ctx context.Context, tx Tx, e domain.Event) error {
+	if e.Type != domain.EventProgress || e.VideoID == uuid.Nil {
+		return nil
+	}
+	t := tx.(*PostgresTx).tx
+
+	var bucket int
+	err := t.QueryRow(ctx, `
+		SELECT LEAST($2::bigint * $3 / (duration_s::bigint * 1000), $3)
+		FROM app.videos
+		WHERE id = $1 AND duration_s > 0
+	`, e.VideoID, e.PositionMs, domain.RetentionBuckets).Scan(&bucket)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Прежний максимум читается под блокировкой строки: параллельное событие той же
+	// сессии ждёт коммита и видит уже обновлённое значение, корзины не считаются дважды.
+	// Новая строка сначала вставляется с max_bucket = -1 («корзин ещё нет»).
+	if _, err := t.Exec(ctx, `
+		INSERT INTO app.video_watch_progress(video_id, session_id, max_bucket, updated_at)
+		VALUES ($1, $2, -1, $3)
+		ON CONFLICT (video_id, session_id) DO NOTHING
+	`, e.VideoID, e.SessionID, e.TS.UTC()); err != nil {
+		return err
+	}
+	var prev int
+	if err := t.QueryRow(ctx, `
+		SELECT max_bucket FROM app.video_watch_progress
+		WHERE video_id = $1 AND session_id = $2
+		FOR UPDATE
+	`, e.VideoID, e.SessionID).Scan(&prev); err != nil {
+		return err
+	}
+
+	_, err = t.Exec(ctx, `
+		WITH up AS (
+			UPDATE app.video_watch_progress
+			SET max_bucket = GREATEST(max_bucket, $3),
+			    updated_at = GREATEST(updated_at, $4)
+			WHERE video_id = $1 AND session_id = $2
+		)
+		INSERT INTO app.video_retention(video_id, bucket, viewers)
+		SELECT $1, b, 1
+		FROM generate_series($5::int + 1, $3::int) AS b
+		ON CONFLICT (video_id, bucket) DO UPDATE
+		SET viewers = app.video_retention.viewers + 1
+	`, e.VideoID, e.SessionID, bucket, e.TS.UTC(), prev)
+	return err
+}
+
+// ChannelRetention гистограммы удержания всех видео автора, у которых есть зрители
+func (r *PostgresRepo) ChannelRetention(ctx context.Context, authorID uuid.UUID) ([]domain.RetentionCounts, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT rt.video_id, rt.bucket, rt.viewers
+		FROM app.video_retention rt
+		JOIN app.videos v ON v.id = rt.video_id
+		WHERE v.author_id = $1
+		ORDER BY rt.video_id, rt.bucket
+	`, authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.RetentionCounts
+	for rows.Next() {
+		var videoID uuid.UUID
+		var bucket int
+		var viewers int64
+		if err := rows.Scan(&videoID, &bucket, &viewers); err != nil {
+			return nil, err
+		}
+		if len(res) == 0 || res[len(res)-1].VideoID != videoID {
+			res = append(res, domain.RetentionCounts{VideoID: videoID})
+		}
+		if bucket >= 0 && bucket <= domain.RetentionBuckets {
+			res[len(res)-1].Viewers[bucket] = viewers
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,187 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const pgUniqueViolation = "23505"
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+// buildExpandedTSQuery строит tsquery, в котором каждое слово запроса
+// заменено на дизъюнкцию с его синонимами: "k8s deploy" -> ('k8s' | 'kubernetes') & 'deploy'.
+// Многословные синонимы превращаются во фразу через оператор <->.
+// Возвращает пустую строку, если расширять нечего.
+func buildExpandedTSQuery(query string, expansions map[string][]string) string {
+	if len(expansions) == 0 {
+		return ""
+	}
+
+	tokens := domain.SearchTokens(query)
+	groups := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		alternatives := append([]string{token}, expansions[token]...)
+		parts := make([]string, 0, len(alternatives))
+		for _, alt := range alternatives {
+			words := strings.Fields(alt)
+			quoted := make([]string, 0, len(words))
+			for _, w := range words {
+				quoted = append(quoted, quoteLexeme(w))
+			}
+			if len(quoted) == 0 {
+				continue
+			}
+			parts = append(parts, "("+strings.Join(quoted, " <-> ")+")")
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		groups = append(groups, "("+strings.Join(parts, " | ")+")")
+	}
+	return strings.Join(groups, " & ")
+}
+
+// quoteLexeme экранирует слово для to_tsquery, чтобы пользовательский ввод не интерпретировался как операторы
+func quoteLexeme(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `''`)
+	return "'" + s + "'"
+}
+
+// FindSynonyms возвращает группы синонимов, в которые входит хотя бы одно из слов
+func (r *PostgresRepo) FindSynonyms(ctx context.Context, tokens []string) ([]domain.Synonym, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, term, synonyms, created_at, updated_at
+		FROM app.search_synonyms
+		WHERE term = ANY($1::text[]) OR synonyms && $1::text[]
+	`, tokens)
+	if err != nil {
+		return nil, err
+	}
+	return scanSynonyms(rows)
+}
+
+// ListSynonyms возвращает весь словарь синонимов
+func (r *PostgresRepo) ListSynonyms(ctx context.Context) ([]domain.Synonym, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, term, synonyms, created_at, updated_at
+		FROM app.search_synonyms
+		ORDER BY term
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanSynonyms(rows)
+}
+
+func (r *PostgresRepo) CreateSynonym(ctx context.Context, s domain.Synonym) (domain.Synonym, error) {
+	err := r.DB.QueryRow(ctx, `
+		INSERT INTO app.search_synonyms(id, term, synonyms)
+		VALUES ($1, $2, $3)
+		RETURNING created_at, updated_at
+	`, s.ID, s.Term, s.Synonyms).Scan(&s.CreatedAt, &s.UpdatedAt)
+	if isUniqueViolation(err) {
+		return domain.Synonym{}, domain.ErrSynonymExists
+	}
+	return s, err
+}
+
+func (r *PostgresRepo) UpdateSynonym(ctx context.Context, s domain.Synonym) (domain.Synonym, error) {
+	err := r.DB.QueryRow(ctx, `
+		UPDATE app.search_synonyms
+		SET term = $2, synonyms = $3, updated_at = now()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, s.ID, s.Term, s.Synonyms).Scan(&s.CreatedAt, &s.UpdatedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return domain.Synonym{}, domain.ErrSynonymNotFound
+	case isUniqueViolation(err):
+		return domain.Synonym{}, domain.ErrSynonymExists
+	}
+	return s, err
+}
+
+func (r *PostgresRepo) DeleteSynonym(ctx context.Context, id uuid.UUID) error {
+	cmd, err := r.DB.Exec(ctx, `DELETE FROM app.search_synonyms WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return domain.ErrSynonymNotFound
+	}
+	return nil
+}
+
+// SuggestQueryTokens исправляет каждое слово на ближайшее по триграммам слово из заголовков.
+// Слова, которые уже есть в словаре или для которых нет похожих, возвращаются без изменений.
+func (r *PostgresRepo) SuggestQueryTokens(ctx context.Context, tokens []string) ([]string, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT COALESCE(s.word, t.token)
+		FROM unnest($1::text[]) WITH ORDINALITY AS t(token, ord)
+		LEFT JOIN LATERAL (
+			SELECT w.word
+			FROM app.title_vocabulary w
+			WHERE w.word % t.token
+			ORDER BY (w.word = t.token) DESC, similarity(w.word, t.token) DESC, w.ndoc DESC
+			LIMIT 1
+		) s ON true
+		ORDER BY t.ord
+	`, tokens)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggested := make([]string, 0, len(tokens))
+	for rows.Next() {
+		var word string
+		if err := rows.Scan(&word); err != nil {
+			return nil, err
+		}
+		suggested = append(suggested, word)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return suggested, nil
+}
+
+// RefreshTitleVocabulary пересобирает словарь слов из заголовков для подсказок
+func (r *PostgresRepo) RefreshTitleVocabulary(ctx context.Context) error {
+	_, err := r.DB.Exec(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY app.title_vocabulary`)
+	return err
+}
+
+func scanSynonyms(rows pgx.Rows) ([]domain.Synonym, error) {
+	defer rows.Close()
+
+	var res []domain.Synonym
+	for rows.Next() {
+		var s domain.Synonym
+		if err := rows.Scan(&s.ID, &s.Term, &s.Synonyms, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
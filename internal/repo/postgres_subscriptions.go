@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+)
+
+func (r *PostgresRepo) UserExists(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.DB.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM app.users WHERE id = $1)`, userID).Scan(&exists)
+	return exists, err
+}
+
+// Follow подписывает пользователя на автора; false — если подписка уже была
+func (r *PostgresRepo) Follow(ctx context.Context, followerID, authorID uuid.UUID) (bool, error) {
+	cmd, err := r.DB.Exec(ctx, `
+		INSERT INTO app.subscriptions(follower_id, author_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, followerID, authorID)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+// Unfollow отписывает пользователя от автора; false — если подписки не было
+func (r *PostgresRepo) Unfollow(ctx context.Context, followerID, authorID uuid.UUID) (bool, error) {
+	cmd, err := r.DB.Exec(ctx, `
+		DELETE FROM app.subscriptions WHERE follower_id = $1 AND author_id = $2
+	`, followerID, authorID)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+// GetAuthorProfile возвращает число подписчиков и видео автора и признак подписки viewerID (uuid.Nil — гость)
+func (r *PostgresRepo) GetAuthorProfile(ctx context.Context, authorID, viewerID uuid.UUID) (domain.AuthorProfile, error) {
+	p := domain.AuthorProfile{AuthorID: authorID}
+	err := r.DB.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM app.subscriptions WHERE author_id = $1),
+			(SELECT COUNT(*) FROM app.videos WHERE author_id = $1),
+			EXISTS(SELECT 1 FROM app.subscriptions WHERE author_id = $1 AND follower_id = $2)
+	`, authorID, viewerID).Scan(&p.Followers, &p.Videos, &p.Following)
+	return p, err
+}
+
+// GetFollowedAuthorIDs возвращает авторов, на которых подписан пользователь
+func (r *PostgresRepo) GetFollowedAuthorIDs(ctx context.Context, userID string) ([]uuid.UUID, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT author_id FROM app.subscriptions WHERE follower_id = $1::uuid
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetSubscriptionVideos возвращает новые видео авторов из подписок по убыванию (uploaded_at, id).
+// Скрытые пользователем видео пропускаются.
+func (r *PostgresRepo) GetSubscriptionVideos(ctx context.Context, userID uuid.UUID, cursor *domain.Cursor, limit int) ([]domain.Video, error) {
+	var cursorTS, cursorID any
+	if cursor != nil {
+		cursorTS, cursorID = cursor.TS, cursor.ID
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+videoColumns+`
+		FROM app.subscriptions s
+		JOIN app.videos v ON v.author_id = s.author_id
+		WHERE s.follower_id = $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM app.user_hidden_videos hv WHERE hv.user_id = $1 AND hv.video_id = v.id
+		  )
+		  AND ($2::timestamptz IS NULL OR (v.uploaded_at, v.id) < ($2::timestamptz, $3::uuid))
+		ORDER BY v.uploaded_at DESC, v.id DESC
+		LIMIT $4
+	`, userID, cursorTS, cursorID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanVideos(rows)
+}
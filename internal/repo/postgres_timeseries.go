@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arasvet/microtube/internal/domain"
+)
+
+// timeseriesMetricColumns колонки агрегата для сортировки значений разбивки по метрике
+var timeseriesMetricColumns = map[domain.StatsMetric]string{
+	domain.MetricViews:       "views",
+	domain.MetricCompletes:   "completes",
+	domain.MetricLikes:       "likes",
+	domain.MetricClicks:      "clicks",
+	domain.MetricImpressions: "impressions",
+	domain.MetricDwell:       "dwell",
+}
+
+// StatsTimeseries суммы метрик по корзинам периода [p.From, p.To) и значениям разбивки.
+// Часовые корзины считаются по video_hourly, остальные — по video_daily.
+// keys == nil — берутся p.Top значений разбивки с наибольшей первой метрикой,
+// иначе только перечисленные (для сравнения с предыдущим периодом).
+func (r *PostgresRepo) StatsTimeseries(ctx context.Context, p domain.TimeseriesParams, keys []string) ([]domain.TimeseriesRow, error) {
+	// Все фрагменты ниже выбираются из фиксированных вариантов, пользовательский ввод идёт параметрами
+	source := `app.video_daily s`
+	bucket := `date_trunc($3, s.day::timestamp) AT TIME ZONE 'UTC'`
+	period := `s.day >= ($1::timestamptz AT TIME ZONE 'UTC')::date AND s.day < ($2::timestamptz AT TIME ZONE 'UTC')::date`
+	if p.Granularity == domain.GranularityHour {
+		source = `app.video_hourly s`
+		bucket = `date_trunc($3, s.hour AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'`
+		period = `s.hour >= $1 AND s.hour < $2`
+	}
+
+	key := `''`
+	switch p.Breakdown {
+	case domain.BreakdownTag:
+		source += ` JOIN app.videos v ON v.id = s.video_id CROSS JOIN LATERAL unnest(v.tags) AS t(tag)`
+		key = `t.tag`
+	case domain.BreakdownLang:
+		source += ` JOIN app.videos v ON v.id = s.video_id`
+		key = `COALESCE(v.lang, '')`
+	case domain.BreakdownAuthor:
+		source += ` JOIN app.videos v ON v.id = s.video_id`
+		key = `COALESCE(v.author_id::text, '')`
+	}
+
+	order := timeseriesMetricColumns[p.Metrics[0]]
+	filter := `SELECT key FROM agg GROUP BY key ORDER BY SUM(` + order + `) DESC, key LIMIT $4`
+	args := []interface{}{p.From, p.To, string(p.Granularity), p.Top}
+	if keys != nil {
+		filter = `SELECT unnest($4::text[]) AS key`
+		args[3] = keys
+	}
+
+	query := fmt.Sprintf(`
+		WITH agg AS (
+			SELECT %s AS bucket, %s AS key,
+				SUM(s.views) AS views, SUM(s.completes) AS completes, SUM(s.likes) AS likes,
+				SUM(s.clicks) AS clicks, SUM(s.impressions) AS impressions, SUM(s.dwell_ms_sum) AS dwell
+			FROM %s
+			WHERE %s
+			GROUP BY 1, 2
+		),
+		top AS (%s)
+		SELECT agg.bucket, agg.key, agg.views, agg.completes, agg.likes, agg.clicks, agg.impressions, agg.dwell
+		FROM agg
+		JOIN top USING (key)
+		ORDER BY agg.key, agg.bucket
+	`, bucket, key, source, period, filter)
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []domain.TimeseriesRow
+	for rows.Next() {
+		var row domain.TimeseriesRow
+		v := &row.Values
+		if err := rows.Scan(&row.Bucket, &row.Key, &v.Views, &v.Completes, &v.Likes,
+			&v.Clicks, &v.Impressions, &v.DwellMs); err != nil {
+			return nil, err
+		}
+		res = append(res, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,39 @@
+package repo
+
+import "context"
+
+// liveStatsChannel канал pub/sub с дельтами live-статистики всех реплик API
+const liveStatsChannel = "live:stats"
+
+// PublishLiveStats рассылает дельту всем подписанным репликам
+func (r *RedisRepo) PublishLiveStats(ctx context.Context, payload []byte) error {
+	return r.Rdb.Publish(ctx, liveStatsChannel, payload).Err()
+}
+
+// SubscribeLiveStats сообщения канала до отмены ctx; после отмены канал закрывается.
+// Переподключение к Redis go-redis выполняет сам, сообщения на время разрыва теряются.
+func (r *RedisRepo) SubscribeLiveStats(ctx context.Context) <-chan []byte {
+	ps := r.Rdb.Subscribe(ctx, liveStatsChannel)
+	out := make(chan []byte, 64)
+	go func() {
+		defer close(out)
+		defer ps.Close()
+		in := ps.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+const rateKeyPrefix = "rate:"
+
+// HitRate увеличивает счётчик key и возвращает его значение. Окно фиксированное:
+// счётчик живёт window с первого попадания.
+func (r *RedisRepo) HitRate(ctx context.Context, key string, window time.Duration) (int64, error) {
+	key = rateKeyPrefix + key
+	pipe := r.Rdb.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// UnhitRate отменяет попадание HitRate, если событие так и не записалось
+func (r *RedisRepo) UnhitRate(ctx context.Context, key string) error {
+	return r.Rdb.Decr(ctx, rateKeyPrefix+key).Err()
+}
@@ -0,0 +1,69 @@
+package repo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const seenKeyPrefix = "seen:"
+
+func seenKey(userID uuid.UUID) string {
+	return seenKeyPrefix + userID.String()
+}
+
+// MarkSeen добавляет видео в seen-set пользователя до момента until.
+// Set хранится как sorted set со score = unix-время окончания cooldown: более длинный
+// cooldown не перетирается коротким, истёкшие записи и всё сверх maxItems вычищаются при записи.
+func (r *RedisRepo) MarkSeen(ctx context.Context, userID uuid.UUID, videoIDs []uuid.UUID, until time.Time, maxItems int) error {
+	if len(videoIDs) == 0 {
+		return nil
+	}
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+
+	key := seenKey(userID)
+	members := make([]redis.Z, 0, len(videoIDs))
+	for _, id := range videoIDs {
+		members = append(members, redis.Z{Score: float64(until.Unix()), Member: id.String()})
+	}
+
+	pipe := r.Rdb.TxPipeline()
+	pipe.ZAddGT(ctx, key, members...)
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(time.Now().Unix(), 10))
+	if maxItems > 0 {
+		// оставляем maxItems записей с самым поздним окончанием cooldown
+		pipe.ZRemRangeByRank(ctx, key, 0, int64(-maxItems-1))
+	}
+	// Ключ живёт до самого позднего окончания cooldown
+	pipe.ExpireNX(ctx, key, ttl)
+	pipe.ExpireGT(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetSeen возвращает видео пользователя, для которых cooldown ещё не истёк
+func (r *RedisRepo) GetSeen(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]bool, error) {
+	members, err := r.Rdb.ZRangeByScore(ctx, seenKey(userID), &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(time.Now().Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uuid.UUID]bool, len(members))
+	for _, m := range members {
+		id, err := uuid.Parse(m)
+		if err != nil {
+			continue
+		}
+		seen[id] = true
+	}
+	return seen, nil
+}
@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const trendingKeyPrefix = "feed:trending"
+
+func trendingKey(window domain.TrendingWindow) string {
+	if window == domain.TrendingWindowBlended {
+		return trendingKeyPrefix
+	}
+	return trendingKeyPrefix + ":" + string(window)
+}
+
+// SaveTrending атомарно заменяет sorted set окна новыми скорами
+func (r *RedisRepo) SaveTrending(ctx context.Context, window domain.TrendingWindow, scores map[uuid.UUID]float64, ttl time.Duration) error {
+	key := trendingKey(window)
+	if len(scores) == 0 {
+		return r.Rdb.Del(ctx, key).Err()
+	}
+
+	members := make([]redis.Z, 0, len(scores))
+	for id, score := range scores {
+		members = append(members, redis.Z{Score: score, Member: id.String()})
+	}
+
+	// Пишем во временный ключ и переименовываем, чтобы читатели не видели полузаполненный набор
+	tmp := key + ":tmp"
+	pipe := r.Rdb.TxPipeline()
+	pipe.Del(ctx, tmp)
+	pipe.ZAdd(ctx, tmp, members...)
+	pipe.Expire(ctx, tmp, ttl)
+	pipe.Rename(ctx, tmp, key)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetTrending возвращает идентификаторы видео окна по убыванию скора
+func (r *RedisRepo) GetTrending(ctx context.Context, window domain.TrendingWindow, limit int) ([]uuid.UUID, error) {
+	members, err := r.Rdb.ZRevRange(ctx, trendingKey(window), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, m := range members {
+		id, err := uuid.Parse(m)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+)
+
+// generateExploration слот исследования: Thompson sampling по свежим видео.
+// Каждое видео получает выборку из апостериорного распределения CTR, в выдачу идут лучшие выборки:
+// новые видео (мало показов, широкое распределение) регулярно пробуются, видео с хорошим CTR
+// показываются чаще и быстрее набирают просмотры для популярных фидов, а неудачные почти не выпадают.
+func (uc *RecommendationsUC) generateExploration(ctx context.Context, req *RecRequest, limit int) ([]domain.RecommendationResult, error) {
+	since := time.Now().Add(-uc.cfg.BanditFreshWindow)
+	arms, err := uc.store.GetBanditArms(ctx, since, uc.cfg.BanditGraduateViews, uc.cfg.BanditPoolSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Просмотренные и скрытые убираем до выборки, чтобы они не занимали места в слоте
+	candidates := arms[:0]
+	for _, a := range arms {
+		if !req.Seen[a.Video.ID] && !req.Feedback.IsHidden(a.Video.ID) {
+			candidates = append(candidates, a)
+		}
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return thompsonSample(rng, candidates, limit), nil
+}
+
+// thompsonSample выбирает до limit видео с наибольшей выборкой CTR из Beta-апостериора
+func thompsonSample(rng *rand.Rand, arms []domain.BanditArm, limit int) []domain.RecommendationResult {
+	type draw struct {
+		arm    domain.BanditArm
+		sample float64
+	}
+	draws := make([]draw, 0, len(arms))
+	for _, a := range arms {
+		alpha, beta := a.Posterior()
+		draws = append(draws, draw{arm: a, sample: sampleBeta(rng, alpha, beta)})
+	}
+	sort.Slice(draws, func(i, j int) bool { return draws[i].sample > draws[j].sample })
+
+	if len(draws) > limit {
+		draws = draws[:limit]
+	}
+	results := make([]domain.RecommendationResult, 0, len(draws))
+	for _, d := range draws {
+		results = append(results, domain.RecommendationResult{Video: d.arm.Video, Score: d.sample})
+	}
+	return results
+}
+
+// recordBanditImpressions засчитывает показы слота exploration; запись не задерживает выдачу
+func (uc *RecommendationsUC) recordBanditImpressions(results []domain.RecommendationResult) {
+	var ids []uuid.UUID
+	for _, r := range results {
+		if r.Reason == domain.ReasonExploration {
+			ids = append(ids, r.Video.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		if err := uc.store.RecordBanditImpressions(ctx, ids); err != nil {
+			slog.Warn("bandit impressions write failed", "err", err)
+		}
+	}()
+}
+
+// sampleBeta выборка из Beta(alpha, beta) через две гамма-выборки
+func sampleBeta(rng *rand.Rand, alpha, beta float64) float64 {
+	x := sampleGamma(rng, alpha)
+	y := sampleGamma(rng, beta)
+	if x+y == 0 {
+		return 0
+	}
+	return x / (x + y)
+}
+
+// sampleGamma выборка из Gamma(shape, 1) методом Марсальи–Цанга (shape >= 1 для апостериора Beta(1+…))
+func sampleGamma(rng *rand.Rand, shape float64) float64 {
+	if shape < 1 {
+		// Gamma(k) = Gamma(k+1) * U^(1/k)
+		return sampleGamma(rng, shape+1) * math.Pow(rng.Float64(), 1/shape)
+	}
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleBeta_Mean(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sum := 0.0
+	for i := 0; i < 20000; i++ {
+		sum += sampleBeta(rng, 3, 7)
+	}
+	assert.InDelta(t, 0.3, sum/20000, 0.01)
+}
+
+func TestThompsonSample_PrefersPromisingArms(t *testing.T) {
+	good := domain.BanditArm{Video: domain.Video{ID: uuid.New()}, Impressions: 200, Clicks: 140}
+	dud := domain.BanditArm{Video: domain.Video{ID: uuid.New()}, Impressions: 200, Clicks: 1}
+	fresh := domain.BanditArm{Video: domain.Video{ID: uuid.New()}}
+
+	rng := rand.New(rand.NewSource(1))
+	picked := map[uuid.UUID]int{}
+	for i := 0; i < 1000; i++ {
+		res := thompsonSample(rng, []domain.BanditArm{dud, good, fresh}, 1)
+		picked[res[0].Video.ID]++
+	}
+
+	// неудачное видео практически не показывается, новое без показов всё ещё пробуется
+	assert.Less(t, picked[dud.Video.ID], 10)
+	assert.Greater(t, picked[fresh.Video.ID], 100)
+	assert.Greater(t, picked[good.Video.ID], picked[fresh.Video.ID])
+}
+
+func TestBanditArm_PosteriorCapsClicks(t *testing.T) {
+	// просмотры из других выдач не дают CTR больше 100%
+	alpha, beta := domain.BanditArm{Impressions: 2, Clicks: 5}.Posterior()
+	assert.Equal(t, 3.0, alpha)
+	assert.Equal(t, 1.0, beta)
+}
+
+func TestPipeline_BanditSampleDecidesSlotNotScore(t *testing.T) {
+	arms := []domain.BanditArm{
+		{Video: domain.Video{ID: uuid.New()}, Impressions: 200, Clicks: 1},
+		{Video: domain.Video{ID: uuid.New()}, Impressions: 200, Clicks: 140},
+		{Video: domain.Video{ID: uuid.New()}, Impressions: 200, Clicks: 60},
+	}
+	p := newRecPipeline(time.Second, 0)
+	p.register(generatorFunc{"exploration", domain.ReasonExploration, func(_ context.Context, _ *RecRequest, limit int) ([]domain.RecommendationResult, error) {
+		return thompsonSample(rand.New(rand.NewSource(1)), arms, limit), nil
+	}})
+
+	results := p.run(context.Background(), &RecRequest{Limit: 2}, []config.RecSource{{Name: "exploration", Quota: 1, Weight: 0.5}})
+
+	// выборка решает состав и порядок слота: неудачное видео не попало, лучшее первым
+	assert.Len(t, results, 2)
+	assert.Equal(t, arms[1].Video.ID, results[0].Video.ID)
+	assert.Equal(t, arms[2].Video.ID, results[1].Video.ID)
+	for _, r := range results {
+		b := r.Explanation.Scores
+		assert.Greater(t, b.BanditSample, 0.0)
+		// итоговый score считается по общим правилам и с выборкой не смешивается
+		assert.Equal(t, b.SourceWeight*b.PositionDecay, b.Final)
+		assert.Equal(t, b.Final, r.Score)
+	}
+	assert.Greater(t, results[0].Explanation.Scores.BanditSample, results[1].Explanation.Scores.BanditSample)
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/arasvet/microtube/internal/als"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+)
+
+// factorIndex факторы видео текущей ALS-модели в памяти процесса.
+// Перечитывается из Postgres раз в ALSCacheTTL, поэтому новая модель
+// из cmd/train подхватывается без перезапуска API. Загруженный снимок не меняется,
+// а подменяется целиком: читатели не держат блокировок, пока ходят в БД.
+type factorIndex struct {
+	current atomic.Pointer[factorSnapshot]
+	reload  sync.Mutex // перезагружает один запрос, остальные ждут его снимок
+}
+
+// factorSnapshot неизменяемое содержимое индекса
+type factorSnapshot struct {
+	loadedAt time.Time
+	model    domain.FactorModel
+	ids      []uuid.UUID
+	vectors  [][]float64
+	rows     map[uuid.UUID]int
+	gram     [][]float64 // YᵀY для fold-in, считается один раз на загрузку
+}
+
+func (s *factorSnapshot) fresh(ttl time.Duration) bool {
+	return s != nil && time.Since(s.loadedAt) < ttl
+}
+
+// factorSnapshot возвращает актуальный снимок индекса, перезагружая его при устаревании
+func (uc *RecommendationsUC) factorSnapshot(ctx context.Context) (*factorSnapshot, error) {
+	idx := uc.factors
+	if snap := idx.current.Load(); snap.fresh(uc.cfg.ALSCacheTTL) {
+		return snap, nil
+	}
+
+	idx.reload.Lock()
+	defer idx.reload.Unlock()
+	// пока ждали, снимок мог перезагрузить другой запрос
+	if snap := idx.current.Load(); snap.fresh(uc.cfg.ALSCacheTTL) {
+		return snap, nil
+	}
+
+	model, ids, vectors, err := uc.store.LoadVideoFactors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows := make(map[uuid.UUID]int, len(ids))
+	for i, id := range ids {
+		rows[id] = i
+	}
+	var gram [][]float64
+	if len(vectors) > 0 {
+		gram = als.Gram(vectors, model.Factors)
+	}
+
+	snap := &factorSnapshot{loadedAt: time.Now(), model: model, ids: ids, vectors: vectors, rows: rows, gram: gram}
+	idx.current.Store(snap)
+	return snap, nil
+}
+
+// collaborativeForUser возвращает видео с наибольшим скалярным произведением факторов пользователя и видео.
+// Пользователь без факторов (новый с момента обучения) получает их через fold-in по своим сигналам.
+// Если модели ещё нет или у пользователя нет сигналов — пустой результат, квоту добирают другие источники.
+// Видео, появившиеся после обучения, факторов не имеют и в этот источник не попадают.
+func (uc *RecommendationsUC) collaborativeForUser(ctx context.Context, userID uuid.UUID, limit int) ([]domain.Video, error) {
+	idx, err := uc.factorSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(idx.ids) == 0 {
+		return nil, nil
+	}
+
+	interactions, err := uc.store.Interactions(ctx, time.Now().Add(-uc.cfg.ALSLookback), &userID)
+	if err != nil {
+		return nil, err
+	}
+	// Уже просмотренное пользователем не рекомендуем повторно
+	interacted := make(map[int]bool, len(interactions))
+	for _, in := range interactions {
+		if row, ok := idx.rows[in.VideoID]; ok {
+			interacted[row] = true
+		}
+	}
+
+	x, err := uc.store.GetUserFactors(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != idx.model.Factors {
+		x = foldIn(idx, interactions)
+		if x == nil {
+			return nil, nil
+		}
+	}
+
+	type scored struct {
+		row   int
+		score float64
+	}
+	candidates := make([]scored, 0, len(idx.vectors))
+	for row, y := range idx.vectors {
+		if interacted[row] {
+			continue
+		}
+		candidates = append(candidates, scored{row: row, score: als.Dot(x, y)})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	ids := make([]uuid.UUID, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, idx.ids[c.row])
+	}
+	return uc.store.GetVideosByIDs(ctx, ids)
+}
+
+// foldIn считает факторы пользователя по его сигналам при фиксированных факторах видео.
+// Возвращает nil, если ни одно из видео пользователя не участвовало в обучении.
+func foldIn(idx *factorSnapshot, interactions []domain.Interaction) []float64 {
+	var items [][]float64
+	var values []float64
+	for _, in := range interactions {
+		row, ok := idx.rows[in.VideoID]
+		if !ok {
+			continue
+		}
+		items = append(items, idx.vectors[row])
+		values = append(values, in.Weight)
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	slog.Debug("als fold-in", "signals", len(items))
+	return als.FoldIn(idx.gram, items, values, als.Params{
+		Factors: idx.model.Factors,
+		Lambda:  idx.model.Lambda,
+		Alpha:   idx.model.Alpha,
+	})
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type factorStore struct {
+	repo.Store
+	loads atomic.Int32
+}
+
+func (s *factorStore) LoadVideoFactors(context.Context) (domain.FactorModel, []uuid.UUID, [][]float64, error) {
+	s.loads.Add(1)
+	time.Sleep(20 * time.Millisecond)
+	return domain.FactorModel{Factors: 2}, []uuid.UUID{uuid.New()}, [][]float64{{1, 0}}, nil
+}
+
+func TestFactorSnapshot_SingleReload(t *testing.T) {
+	store := &factorStore{}
+	uc := NewRecommendationsUC(store, nil, nil, config.Config{ALSCacheTTL: time.Hour})
+
+	var wg sync.WaitGroup
+	snaps := make([]*factorSnapshot, 8)
+	for i := range snaps {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			snaps[i], _ = uc.factorSnapshot(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	// устаревший индекс перечитывает один запрос, остальные получают его снимок
+	assert.Equal(t, int32(1), store.loads.Load())
+	for _, s := range snaps {
+		assert.Same(t, snaps[0], s)
+	}
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+)
+
+// CommentsUCInterface интерфейс для тестирования
+type CommentsUCInterface interface {
+	List(ctx context.Context, params domain.CommentListParams) (domain.CommentPage, error)
+	Create(ctx context.Context, userID, videoID uuid.UUID, parentID *uuid.UUID, body string) (domain.Comment, error)
+	Edit(ctx context.Context, userID, commentID uuid.UUID, body string) (domain.Comment, error)
+	Delete(ctx context.Context, userID, commentID uuid.UUID) error
+	Like(ctx context.Context, userID, commentID uuid.UUID) error
+	Unlike(ctx context.Context, userID, commentID uuid.UUID) error
+}
+
+type CommentsUC struct {
+	store repo.Store
+}
+
+func NewCommentsUC(store repo.Store) *CommentsUC {
+	return &CommentsUC{store: store}
+}
+
+// List возвращает страницу корневых комментариев видео или ответов на комментарий
+func (uc *CommentsUC) List(ctx context.Context, params domain.CommentListParams) (domain.CommentPage, error) {
+	if err := params.Validate(); err != nil {
+		return domain.CommentPage{}, err
+	}
+
+	// Берём на одну запись больше, чтобы понять, есть ли следующая страница
+	limit := params.Limit
+	params.Limit++
+	comments, err := uc.store.ListComments(ctx, params)
+	if err != nil {
+		return domain.CommentPage{}, err
+	}
+
+	page := domain.CommentPage{Comments: comments}
+	if len(comments) > limit {
+		page.Comments = comments[:limit]
+		last := page.Comments[limit-1]
+		page.NextCursor = domain.Cursor{TS: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	if page.Comments == nil {
+		page.Comments = []domain.Comment{}
+	}
+	return page, nil
+}
+
+// Create добавляет комментарий или ответ и увеличивает счётчик комментариев видео в той же транзакции
+func (uc *CommentsUC) Create(ctx context.Context, userID, videoID uuid.UUID, parentID *uuid.UUID, body string) (domain.Comment, error) {
+	body, err := domain.NormalizeCommentBody(body)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	exists, err := uc.store.VideoExists(ctx, videoID)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	if !exists {
+		return domain.Comment{}, domain.ErrVideoNotFound
+	}
+
+	if parentID != nil {
+		parent, err := uc.store.GetComment(ctx, *parentID)
+		if err != nil {
+			return domain.Comment{}, err
+		}
+		if parent.VideoID != videoID || parent.Deleted {
+			return domain.Comment{}, domain.ErrCommentNotFound
+		}
+		// Треды двухуровневые: ответ на ответ уходит в корневой комментарий
+		if parent.ParentID != nil {
+			parentID = parent.ParentID
+		}
+	}
+
+	var c domain.Comment
+	err = uc.inTx(ctx, func(tx repo.Tx) error {
+		var err error
+		c, err = uc.store.InsertComment(ctx, tx, domain.Comment{
+			ID:       uuid.New(),
+			VideoID:  videoID,
+			UserID:   userID,
+			ParentID: parentID,
+			Body:     body,
+		})
+		if err != nil {
+			return err
+		}
+		if parentID != nil {
+			if err := uc.store.IncCommentReplies(ctx, tx, *parentID, 1); err != nil {
+				return err
+			}
+		}
+		return uc.store.IncVideoComments(ctx, tx, videoID, 1)
+	})
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return c, nil
+}
+
+// Edit изменяет текст комментария (только автор)
+func (uc *CommentsUC) Edit(ctx context.Context, userID, commentID uuid.UUID, body string) (domain.Comment, error) {
+	body, err := domain.NormalizeCommentBody(body)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	c, err := uc.store.GetComment(ctx, commentID)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	if c.Deleted {
+		return domain.Comment{}, domain.ErrCommentNotFound
+	}
+	if c.UserID != userID {
+		return domain.Comment{}, domain.ErrCommentForbidden
+	}
+
+	return uc.store.UpdateCommentBody(ctx, commentID, body)
+}
+
+// Delete удаляет комментарий (только автор) и уменьшает счётчики в одной транзакции
+func (uc *CommentsUC) Delete(ctx context.Context, userID, commentID uuid.UUID) error {
+	c, err := uc.store.GetComment(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if c.Deleted {
+		return domain.ErrCommentNotFound
+	}
+	if c.UserID != userID {
+		return domain.ErrCommentForbidden
+	}
+
+	return uc.inTx(ctx, func(tx repo.Tx) error {
+		deleted, err := uc.store.SoftDeleteComment(ctx, tx, commentID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			// параллельное удаление уже всё поправило
+			return domain.ErrCommentNotFound
+		}
+		if c.ParentID != nil {
+			if err := uc.store.IncCommentReplies(ctx, tx, *c.ParentID, -1); err != nil {
+				return err
+			}
+		}
+		return uc.store.IncVideoComments(ctx, tx, c.VideoID, -1)
+	})
+}
+
+// Like ставит лайк комментарию; повторный лайк ничего не меняет
+func (uc *CommentsUC) Like(ctx context.Context, userID, commentID uuid.UUID) error {
+	return uc.toggleLike(ctx, userID, commentID, uc.store.LikeComment)
+}
+
+// Unlike снимает лайк с комментария; снятие отсутствующего лайка ничего не меняет
+func (uc *CommentsUC) Unlike(ctx context.Context, userID, commentID uuid.UUID) error {
+	return uc.toggleLike(ctx, userID, commentID, uc.store.UnlikeComment)
+}
+
+func (uc *CommentsUC) toggleLike(ctx context.Context, userID, commentID uuid.UUID,
+	apply func(ctx context.Context, tx repo.Tx, commentID, userID uuid.UUID) (bool, error)) error {
+	c, err := uc.store.GetComment(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if c.Deleted {
+		return domain.ErrCommentNotFound
+	}
+
+	return uc.inTx(ctx, func(tx repo.Tx) error {
+		_, err := apply(ctx, tx, commentID, userID)
+		return err
+	})
+}
+
+// maxCommentTxAttempts сколько раз выполняется транзакция комментариев при конфликтах
+const maxCommentTxAttempts = 3
+
+// inTx выполняет fn в транзакции (Begin — serializable) и повторяет её при конфликте:
+// параллельные комментарии и лайки одного видео обновляют одни и те же строки счётчиков
+func (uc *CommentsUC) inTx(ctx context.Context, fn func(tx repo.Tx) error) error {
+	var err error
+	for attempt := 0; attempt < maxCommentTxAttempts; attempt++ {
+		if err = uc.runTx(ctx, fn); !repo.IsSerializationFailure(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (uc *CommentsUC) runTx(ctx context.Context, fn func(tx repo.Tx) error) error {
+	tx, err := uc.store.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
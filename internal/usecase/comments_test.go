@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+// commentStore счётчик видео конфликтует conflicts раз, как при параллельных транзакциях
+type commentStore struct {
+	repo.Store
+	comment   domain.Comment
+	conflicts int
+	begins    int
+	commits   int
+}
+
+func (s *commentStore) Begin(context.Context) (repo.Tx, error) {
+	s.begins++
+	return &countingTx{store: s}, nil
+}
+
+func (s *commentStore) VideoExists(context.Context, uuid.UUID) (bool, error) { return true, nil }
+func (s *commentStore) GetComment(context.Context, uuid.UUID) (domain.Comment, error) {
+	return s.comment, nil
+}
+func (s *commentStore) InsertComment(_ context.Context, _ repo.Tx, c domain.Comment) (domain.Comment, error) {
+	return c, nil
+}
+func (s *commentStore) SoftDeleteComment(context.Context, repo.Tx, uuid.UUID) (bool, error) {
+	return false, nil
+}
+func (s *commentStore) LikeComment(context.Context, repo.Tx, uuid.UUID, uuid.UUID) (bool, error) {
+	return true, s.conflict()
+}
+func (s *commentStore) IncVideoComments(context.Context, repo.Tx, uuid.UUID, int) error {
+	return s.conflict()
+}
+
+func (s *commentStore) conflict() error {
+	if s.conflicts == 0 {
+		return nil
+	}
+	s.conflicts--
+	return &pgconn.PgError{Code: "40001"}
+}
+
+type countingTx struct{ store *commentStore }
+
+func (t *countingTx) Commit(context.Context) error   { t.store.commits++; return nil }
+func (t *countingTx) Rollback(context.Context) error { return nil }
+
+func TestCommentsUC_CreateRetriesSerializationFailure(t *testing.T) {
+	store := &commentStore{conflicts: 1}
+	uc := NewCommentsUC(store)
+
+	c, err := uc.Create(context.Background(), uuid.New(), uuid.New(), nil, "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", c.Body)
+	assert.Equal(t, 2, store.begins)
+	assert.Equal(t, 1, store.commits)
+}
+
+func TestCommentsUC_LikeGivesUpAfterAttempts(t *testing.T) {
+	store := &commentStore{conflicts: 10}
+	uc := NewCommentsUC(store)
+
+	err := uc.Like(context.Background(), uuid.New(), uuid.New())
+	assert.True(t, repo.IsSerializationFailure(err))
+	assert.Equal(t, maxCommentTxAttempts, store.begins)
+	assert.Zero(t, store.commits)
+}
+
+func TestCommentsUC_DeleteDoesNotRetryOtherErrors(t *testing.T) {
+	user := uuid.New()
+	store := &commentStore{comment: domain.Comment{ID: uuid.New(), UserID: user}}
+	uc := NewCommentsUC(store)
+
+	// комментарий уже удалён параллельно — ошибка не конфликт, повтора нет
+	err := uc.Delete(context.Background(), user, store.comment.ID)
+	assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+	assert.Equal(t, 1, store.begins)
+}
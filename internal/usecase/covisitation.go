@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+)
+
+// covisitationSeeds сколько последних просмотренных видео используется как затравка для ReasonSimilar
+const covisitationSeeds = 3
+
+// RefreshCovisitation пересчитывает соседей видео по совместным просмотрам в сессиях
+func (uc *RecommendationsUC) RefreshCovisitation(ctx context.Context) error {
+	since := time.Now().Add(-uc.cfg.CovisitationLookback)
+	n, err := uc.store.RebuildCovisitation(ctx, since,
+		uc.cfg.CovisitationMaxGap, uc.cfg.CovisitationMinSessions, uc.cfg.CovisitationTopN)
+	if err != nil {
+		return err
+	}
+	slog.Debug("covisitation rebuilt", "pairs", n)
+	return nil
+}
+
+// Related возвращает видео, которые смотрят вместе с videoID.
+// Пока совместных просмотров мало, выдача добирается видео с общими тегами.
+func (uc *RecommendationsUC) Related(ctx context.Context, videoID uuid.UUID, limit int) ([]domain.RecommendationResult, error) {
+	if limit <= 0 {
+		limit = 20 // значение по умолчанию
+	}
+	if limit > 100 { // ограничиваем максимальный размер выборки
+		limit = 100
+	}
+
+	exists, err := uc.store.VideoExists(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, domain.ErrVideoNotFound
+	}
+
+	results, err := uc.store.GetCovisitedVideos(ctx, []uuid.UUID{videoID}, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) < limit {
+		similar, err := uc.store.GetSimilarVideos(ctx, videoID.String(), limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, video := range similar {
+			if len(results) == limit {
+				break
+			}
+			if !uc.videoExists(results, video.ID.String()) {
+				results = append(results, domain.RecommendationResult{
+					Video:  video,
+					Reason: domain.ReasonSimilar,
+					Score:  0, // только пересечение тегов, ниже любых совместных просмотров
+				})
+			}
+		}
+	}
+
+	if results == nil {
+		results = []domain.RecommendationResult{}
+	}
+	return results, nil
+}
+
+// covisitedForUser возвращает соседей последних просмотренных пользователем видео
+// (в объяснении — просмотренное видео, давшее наибольший вклад)
+func (uc *RecommendationsUC) covisitedForUser(ctx context.Context, userID uuid.UUID, limit int) ([]domain.RecommendationResult, error) {
+	seeds, err := uc.store.GetRecentlyWatchedIDs(ctx, userID, covisitationSeeds)
+	if err != nil || len(seeds) == 0 {
+		return nil, err
+	}
+	return uc.store.GetCovisitedVideos(ctx, seeds, limit)
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+)
+
+// CreatorStatsUCInterface интерфейс для тестирования
+type CreatorStatsUCInterface interface {
+	VideoStats(ctx context.Context, userID, videoID uuid.UUID, from, to time.Time) (domain.CreatorStats, error)
+	ChannelStats(ctx context.Context, userID uuid.UUID, from, to time.Time) (domain.CreatorStats, error)
+	VideoRetention(ctx context.Context, userID, videoID uuid.UUID) (domain.VideoRetention, error)
+}
+
+// CreatorStatsUC статистика авторов по своим видео
+type CreatorStatsUC struct {
+	store repo.Store
+}
+
+func NewCreatorStatsUC(store repo.Store) *CreatorStatsUC {
+	return &CreatorStatsUC{store: store}
+}
+
+// VideoStats статистика видео за дни [from, to]; доступна только автору видео
+func (uc *CreatorStatsUC) VideoStats(ctx context.Context, userID, videoID uuid.UUID, from, to time.Time) (domain.CreatorStats, error) {
+	video, err := uc.ownVideo(ctx, userID, videoID)
+	if err != nil {
+		return domain.CreatorStats{}, err
+	}
+
+	stats, err := uc.collect(ctx, domain.CreatorStatsFilter{AuthorID: userID, VideoID: &videoID, From: from, To: to})
+	if err != nil {
+		return domain.CreatorStats{}, err
+	}
+	stats.Video = &video
+	return stats, nil
+}
+
+// VideoRetention кривая удержания видео и средняя кривая по видео канала; доступна только автору
+func (uc *CreatorStatsUC) VideoRetention(ctx context.Context, userID, videoID uuid.UUID) (domain.VideoRetention, error) {
+	video, err := uc.ownVideo(ctx, userID, videoID)
+	if err != nil {
+		return domain.VideoRetention{}, err
+	}
+	channel, err := uc.store.ChannelRetention(ctx, userID)
+	if err != nil {
+		return domain.VideoRetention{}, err
+	}
+	return buildRetention(video, channel), nil
+}
+
+// ownVideo видео, если его автор — userID
+func (uc *CreatorStatsUC) ownVideo(ctx context.Context, userID, videoID uuid.UUID) (domain.Video, error) {
+	videos, err := uc.store.GetVideosByIDs(ctx, []uuid.UUID{videoID})
+	if err != nil {
+		return domain.Video{}, err
+	}
+	if len(videos) == 0 {
+		return domain.Video{}, domain.ErrVideoNotFound
+	}
+	video := videos[0]
+	if video.AuthorID == nil || *video.AuthorID != userID {
+		return domain.Video{}, domain.ErrVideoStatsForbidden
+	}
+	return video, nil
+}
+
+// ChannelStats статистика всех видео пользователя за дни [from, to] с разбивкой по видео
+func (uc *CreatorStatsUC) ChannelStats(ctx context.Context, userID uuid.UUID, from, to time.Time) (domain.CreatorStats, error) {
+	f := domain.CreatorStatsFilter{AuthorID: userID, From: from, To: to}
+	stats, err := uc.collect(ctx, f)
+	if err != nil {
+		return domain.CreatorStats{}, err
+	}
+	stats.Videos, err = uc.store.CreatorVideoTotals(ctx, f, domain.CreatorTopVideos)
+	if err != nil {
+		return domain.CreatorStats{}, err
+	}
+	if stats.Videos == nil {
+		stats.Videos = []domain.VideoStatsSummary{}
+	}
+	return stats, nil
+}
+
+// collect общая часть статистики видео и канала
+func (uc *CreatorStatsUC) collect(ctx context.Context, f domain.CreatorStatsFilter) (domain.CreatorStats, error) {
+	if err := f.Validate(); err != nil {
+		return domain.CreatorStats{}, err
+	}
+
+	days, err := uc.store.CreatorDaily(ctx, f)
+	if err != nil {
+		return domain.CreatorStats{}, err
+	}
+	traffic, err := uc.store.CreatorTraffic(ctx, f)
+	if err != nil {
+		return domain.CreatorStats{}, err
+	}
+	queries, err := uc.store.CreatorTopQueries(ctx, f, domain.CreatorTopQueries)
+	if err != nil {
+		return domain.CreatorStats{}, err
+	}
+	if queries == nil {
+		queries = []domain.QueryCount{}
+	}
+
+	daily, totals := creatorDaily(f, days)
+	return domain.CreatorStats{
+		AuthorID:   f.AuthorID,
+		From:       f.From,
+		To:         f.To,
+		Totals:     totals,
+		Daily:      daily,
+		Traffic:    trafficShares(traffic),
+		TopQueries: queries,
+	}, nil
+}
+
+// creatorDaily метрики по каждому дню периода (дни без событий — нули) и итоги периода
+func creatorDaily(f domain.CreatorStatsFilter, days []domain.CreatorDayRaw) ([]domain.CreatorDayStats, domain.CreatorDayStats) {
+	byDay := make(map[int64]domain.CreatorDayRaw, len(days))
+	var total domain.CreatorDayRaw
+	for _, d := range days {
+		byDay[d.Day.Unix()] = d
+		total.Views += d.Views
+		total.Completes += d.Completes
+		total.Likes += d.Likes
+		total.DwellMsSum += d.DwellMsSum
+		total.ViewSecs += d.ViewSecs
+	}
+
+	var daily []domain.CreatorDayStats
+	for day := f.From; !day.After(f.To); day = day.AddDate(0, 0, 1) {
+		raw := byDay[day.Unix()]
+		raw.Day = day
+		daily = append(daily, domain.NewCreatorDayStats(raw))
+	}
+	return daily, domain.NewCreatorDayStats(total)
+}
+
+// trafficShares доли источников трафика; все источники присутствуют, даже с нулём просмотров
+func trafficShares(found []domain.TrafficSource) []domain.TrafficSource {
+	views := map[string]int64{}
+	var total int64
+	for _, t := range found {
+		views[t.Source] += t.Views
+		total += t.Views
+	}
+
+	sources := []string{domain.TrafficSearch, domain.TrafficFeed, domain.TrafficRecommendations, domain.TrafficOther}
+	res := make([]domain.TrafficSource, 0, len(sources))
+	for _, s := range sources {
+		t := domain.TrafficSource{Source: s, Views: views[s]}
+		if total > 0 {
+			t.Share = float64(t.Views) / float64(total)
+		}
+		res = append(res, t)
+	}
+	return res
+}
+
+// buildRetention кривая видео и среднее кривых видео канала с достаточным числом зрителей.
+// Каждое видео входит в среднее с одинаковым весом, чтобы популярные видео не задавали «норму» канала.
+func buildRetention(video domain.Video, channel []domain.RetentionCounts) domain.VideoRetention {
+	var own domain.RetentionCounts
+	avg := make([]float64, domain.RetentionBuckets+1)
+	n := 0
+	for _, c := range channel {
+		if c.VideoID == video.ID {
+			own = c
+		}
+		if c.Viewers[0] < domain.RetentionMinViewers {
+			continue
+		}
+		for i, v := range c.Curve() {
+			avg[i] += v
+		}
+		n++
+	}
+
+	res := domain.VideoRetention{Video: video, Viewers: own.Viewers[0], ChannelVideos: n}
+	curve := own.Curve()
+	for i := range avg {
+		p := domain.RetentionPoint{Percent: i * domain.RetentionStepPercent, Viewers: own.Viewers[i]}
+		if curve != nil {
+			p.Retention = curve[i]
+		}
+		if n > 0 {
+			p.ChannelAvg = avg[i] / float64(n)
+		}
+		res.Points = append(res.Points, p)
+	}
+	return res
+}
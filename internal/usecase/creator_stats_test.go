@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatorDaily_FillsDaysAndRates(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	f := domain.CreatorStatsFilter{From: day(1), To: day(3)}
+	assert.NoError(t, f.Validate())
+
+	daily, totals := creatorDaily(f, []domain.CreatorDayRaw{
+		// 4 просмотра видео длиной 60 с, в среднем смотрели по 30 с
+		{Day: day(2), Views: 4, Completes: 1, Likes: 2, DwellMsSum: 120000, ViewSecs: 240},
+	})
+
+	if !assert.Len(t, daily, 3) {
+		return
+	}
+	assert.Equal(t, day(1), daily[0].Day)
+	assert.Equal(t, int64(0), daily[0].Views)
+	assert.InDelta(t, 0.25, daily[1].CompletionRate, 1e-9)
+	assert.InDelta(t, 0.5, daily[1].AvgWatchFraction, 1e-9)
+	assert.InDelta(t, 0.5, daily[1].LikeRate, 1e-9)
+	assert.Equal(t, int64(4), totals.Views)
+}
+
+func TestTrafficShares(t *testing.T) {
+	res := trafficShares([]domain.TrafficSource{
+		{Source: domain.TrafficSearch, Views: 3},
+		{Source: domain.TrafficOther, Views: 1},
+	})
+	assert.Len(t, res, 4)
+	assert.Equal(t, domain.TrafficSearch, res[0].Source)
+	assert.InDelta(t, 0.75, res[0].Share, 1e-9)
+	assert.Equal(t, int64(0), res[1].Views) // feed без просмотров
+}
+
+func TestCreatorStatsFilter_Validate(t *testing.T) {
+	from := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	f := domain.CreatorStatsFilter{From: from, To: from.AddDate(0, 0, -1)}
+	assert.ErrorIs(t, f.Validate(), domain.ErrInvalidStatsPeriod)
+
+	f = domain.CreatorStatsFilter{From: from, To: from.AddDate(2, 0, 0)}
+	assert.ErrorIs(t, f.Validate(), domain.ErrInvalidStatsPeriod)
+}
+
+func TestBuildRetention_ComparesWithChannelAverage(t *testing.T) {
+	video := domain.Video{ID: uuid.New()}
+	// flat: 100 зрителей, до конца дошли все; half: к середине осталась половина
+	flat := domain.RetentionCounts{VideoID: uuid.New()}
+	half := domain.RetentionCounts{VideoID: video.ID}
+	small := domain.RetentionCounts{VideoID: uuid.New()} // слишком мало зрителей для среднего
+	for i := range flat.Viewers {
+		flat.Viewers[i] = 100
+		half.Viewers[i] = 20
+		if i >= domain.RetentionBuckets/2 {
+			half.Viewers[i] = 10
+		}
+	}
+	small.Viewers[0] = 3
+
+	res := buildRetention(video, []domain.RetentionCounts{flat, half, small})
+
+	assert.Equal(t, int64(20), res.Viewers)
+	assert.Equal(t, 2, res.ChannelVideos)
+	if !assert.Len(t, res.Points, domain.RetentionBuckets+1) {
+		return
+	}
+	last := res.Points[domain.RetentionBuckets]
+	assert.Equal(t, 100, last.Percent)
+	assert.InDelta(t, 0.5, last.Retention, 1e-9)
+	assert.InDelta(t, 0.75, last.ChannelAvg, 1e-9)
+	assert.InDelta(t, 1.0, res.Points[0].Retention, 1e-9)
+}
+
+func TestBuildRetention_NoViewers(t *testing.T) {
+	res := buildRetention(domain.Video{ID: uuid.New()}, nil)
+	assert.Equal(t, 0, res.ChannelVideos)
+	assert.Len(t, res.Points, domain.RetentionBuckets+1)
+	assert.Zero(t, res.Points[0].Retention)
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+)
+
+// EventPartitionsUC обслуживание помесячных партиций events: создание будущих и удаление
+// партиций старше срока хранения
+type EventPartitionsUC struct {
+	store     repo.Store
+	reconcile *ReconcileUC
+	ahead     int
+	retention int
+}
+
+func NewEventPartitionsUC(store repo.Store, cfg config.Config) *EventPartitionsUC {
+	return &EventPartitionsUC{
+		store:     store,
+		reconcile: NewReconcileUC(store),
+		ahead:     cfg.EventPartitionsAhead,
+		retention: cfg.EventsRetentionMonths,
+	}
+}
+
+// Maintain вызывается фоновой задачей
+func (uc *EventPartitionsUC) Maintain(ctx context.Context) error {
+	created, err := uc.store.EnsureEventPartitions(ctx, uc.ahead)
+	if err != nil {
+		return err
+	}
+	if created > 0 {
+		slog.Info("events partitions created", "count", created)
+	}
+
+	cutoff := domain.EventsRetentionCutoff(time.Now(), uc.retention)
+	if cutoff.IsZero() {
+		return nil
+	}
+	parts, err := uc.store.EventPartitions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range expiredPartitions(parts, cutoff) {
+		if err := uc.drop(ctx, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drop удаляет партицию, когда её агрегаты в video_daily и video_hourly сверены с событиями:
+// после удаления пересчитать их будет не из чего
+func (uc *EventPartitionsUC) drop(ctx context.Context, p domain.EventPartition) error {
+	repaired, err := uc.reconcile.RepairRollups(ctx, p.From, p.To)
+	if err != nil {
+		return err
+	}
+	events, err := uc.store.DropEventPartition(ctx, p)
+	if err != nil {
+		return err
+	}
+	slog.Info("events partition dropped", "partition", p.Name, "events", events, "repaired", repaired)
+	return nil
+}
+
+// expiredPartitions партиции, целиком закончившиеся до cutoff, от старых к новым
+func expiredPartitions(parts []domain.EventPartition, cutoff time.Time) []domain.EventPartition {
+	var out []domain.EventPartition
+	for _, p := range parts {
+		if !p.To.After(cutoff) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
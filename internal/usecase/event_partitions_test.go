@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEventPartition(t *testing.T) {
+	p, ok := domain.ParseEventPartition("events_2024_12")
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), p.From)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), p.To)
+
+	_, ok = domain.ParseEventPartition("events_default")
+	assert.False(t, ok)
+}
+
+func TestExpiredPartitions(t *testing.T) {
+	now := time.Date(2024, 5, 17, 12, 0, 0, 0, time.UTC)
+	// храним 2 полных месяца помимо текущего: март, апрель и май
+	cutoff := domain.EventsRetentionCutoff(now, 2)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), cutoff)
+	assert.True(t, domain.EventsRetentionCutoff(now, 0).IsZero())
+
+	var parts []domain.EventPartition
+	for _, name := range []string{"events_2024_01", "events_2024_02", "events_2024_03", "events_2024_06"} {
+		p, _ := domain.ParseEventPartition(name)
+		parts = append(parts, p)
+	}
+	expired := expiredPartitions(parts, cutoff)
+	if assert.Len(t, expired, 2) {
+		assert.Equal(t, "events_2024_01", expired[0].Name)
+		assert.Equal(t, "events_2024_02", expired[1].Name)
+	}
+}
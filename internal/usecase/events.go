@@ -5,18 +5,29 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/arasvet/microtube/internal/config"
 	"github.com/arasvet/microtube/internal/domain"
 	"github.com/arasvet/microtube/internal/idem"
 	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
 )
 
+// EventsUCInterface интерфейс для тестирования
+type EventsUCInterface interface {
+	Ingest(ctx context.Context, e domain.Event) (IngestResult, error)
+}
+
 type EventsUC struct {
 	store repo.Store
 	idem  *idem.Service
+	seen  repo.SeenStore
+	live  LiveObserver // nil — live-статистика выключена
+	fraud *FraudFilter // nil — события не помечаются
+	cfg   config.Config
 }
 
-func NewEventsUC(store repo.Store, idemSvc *idem.Service) *EventsUC {
-	return &EventsUC{store: store, idem: idemSvc}
+func NewEventsUC(store repo.Store, idemSvc *idem.Service, seen repo.SeenStore, live LiveObserver, fraud *FraudFilter, cfg config.Config) *EventsUC {
+	return &EventsUC{store: store, idem: idemSvc, seen: seen, live: live, fraud: fraud, cfg: cfg}
 }
 
 type IngestResult struct {
@@ -28,6 +39,13 @@ func (uc *EventsUC) Ingest(ctx context.Context, e domain.Event) (IngestResult, e
 	if err := e.Validate(); err != nil {
 		return IngestResult{}, err
 	}
+	// Партиции старше срока хранения удаляются вместе с вкладом в агрегаты: такое событие не принимаем
+	if e.TS.Before(domain.EventsRetentionCutoff(time.Now(), uc.cfg.EventsRetentionMonths)) {
+		return IngestResult{}, domain.ErrEventTooOld
+	}
+	if err := uc.checkDwell(ctx, e); err != nil {
+		return IngestResult{}, err
+	}
 
 	key := e.EventID.String()
 	status, err := uc.idem.TryReserve(ctx, key)
@@ -41,6 +59,12 @@ func (uc *EventsUC) Ingest(ctx context.Context, e domain.Event) (IngestResult, e
 		return IngestResult{Inserted: false}, nil
 	}
 
+	// Помеченное антифродом событие сохраняется, но не попадает в счётчики и статистику;
+	// клиенту отвечаем как обычно, чтобы не подсказывать, что накрутка замечена
+	if uc.fraud != nil {
+		e.Flag = uc.fraud.PatternFlag(e)
+	}
+
 	// Управление жизненным циклом ключа
 	reserved := status == idem.Miss // мы реально резерв взяли
 	committed := false
@@ -72,11 +96,32 @@ func (uc *EventsUC) Ingest(ctx context.Context, e domain.Event) (IngestResult, e
 		return IngestResult{Inserted: false}, nil
 	}
 
-	// 2) агрегаты
-	if err := uc.store.UpsertVideoCounters(ctx, tx, e); err != nil {
+	// Лимиты считаются только по записанным событиям: дубли и повторы упавших вставок их не расходуют
+	if uc.fraud != nil && e.Flag == "" {
+		flag, undo := uc.fraud.RateFlag(ctx, e)
+		defer func() {
+			if !committed {
+				undo()
+			}
+		}()
+		if flag != "" {
+			e.Flag = flag
+			if err := uc.store.FlagEvent(ctx, tx, e); err != nil {
+				return IngestResult{}, err
+			}
+		}
+	}
+
+	// 2) агрегаты: история и скрытия пользователя пишутся и для помеченных событий
+	if e.Flag == "" {
+		if err := uc.aggregate(ctx, tx, e); err != nil {
+			return IngestResult{}, err
+		}
+	}
+	if err := uc.store.UpsertWatchHistory(ctx, tx, e); err != nil {
 		return IngestResult{}, err
 	}
-	if err := uc.store.UpsertVideoDaily(ctx, tx, e); err != nil {
+	if err := uc.store.UpsertNegativeFeedback(ctx, tx, e); err != nil {
 		return IngestResult{}, err
 	}
 
@@ -101,12 +146,19 @@ func (uc *EventsUC) Ingest(ctx context.Context, e domain.Event) (IngestResult, e
 	if reserved {
 		_ = uc.idem.MarkDone(ctx, key)
 	}
+	if e.Flag != "" {
+		slog.Debug("event flagged", "event_id", e.EventID, "flag", e.Flag)
+	} else if uc.live != nil {
+		uc.live.Observe(e)
+	}
 
 	// ВАЖНО: best-effort вне транзакции
 	go func() {
 		// отдельный контекст с небольшим тайм-аутом, чтобы не висеть
 		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 		defer cancel()
+		uc.markCompleted(ctx, e)
+
 		err := uc.store.UpdateUserSignalsBestEffort(ctx, nil, e)
 		if err != nil {
 			slog.Warn("err store.UpdateUserSignalsBestEffort",
@@ -117,3 +169,58 @@ func (uc *EventsUC) Ingest(ctx context.Context, e domain.Event) (IngestResult, e
 
 	return IngestResult{Inserted: true}, nil
 }
+
+// aggregate обновляет счётчики и статистику видео в транзакции приёма события
+func (uc *EventsUC) aggregate(ctx context.Context, tx repo.Tx, e domain.Event) error {
+	shown, err := uc.store.AttributeClick(ctx, tx, e)
+	if err != nil {
+		return err
+	}
+	// Клик без записанного показа (выдача ещё в очереди, не записалась или serve_id чужой)
+	// считается по времени события, как клик без serve_id
+	rollup := e
+	if !shown {
+		rollup.ServeID = uuid.Nil
+	}
+
+	if err := uc.store.UpsertVideoCounters(ctx, tx, e); err != nil {
+		return err
+	}
+	if err := uc.store.UpsertVideoDaily(ctx, tx, rollup); err != nil {
+		return err
+	}
+	if err := uc.store.UpsertVideoHourly(ctx, tx, rollup); err != nil {
+		return err
+	}
+	if err := uc.store.UpsertRetention(ctx, tx, e); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkDwell отклоняет dwell_ms больше длительности видео: столько смотреть его невозможно
+func (uc *EventsUC) checkDwell(ctx context.Context, e domain.Event) error {
+	if e.DwellMs == 0 || e.VideoID == uuid.Nil {
+		return nil
+	}
+	videos, err := uc.store.GetVideosByIDs(ctx, []uuid.UUID{e.VideoID})
+	if err != nil {
+		return err
+	}
+	// неизвестное видео отклонит внешний ключ при записи
+	if len(videos) == 1 && videos[0].DurationS > 0 && e.DwellMs > videos[0].DurationS*1000 {
+		return domain.ErrDwellTooLong
+	}
+	return nil
+}
+
+// markCompleted добавляет досмотренное видео в seen-set пользователя на SeenCompleteCooldown
+func (uc *EventsUC) markCompleted(ctx context.Context, e domain.Event) {
+	if e.Type != domain.EventViewComplete || e.UserID == uuid.Nil || uc.cfg.SeenCompleteCooldown <= 0 {
+		return
+	}
+	until := e.TS.Add(uc.cfg.SeenCompleteCooldown)
+	if err := uc.seen.MarkSeen(ctx, e.UserID, []uuid.UUID{e.VideoID}, until, uc.cfg.SeenMaxItems); err != nil {
+		slog.Warn("seen-set write failed", "event_id", e.EventID, "err", err)
+	}
+}
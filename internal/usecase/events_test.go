@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/idem"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTx struct{ committed bool }
+
+func (t *fakeTx) Commit(context.Context) error   { t.committed = true; return nil }
+func (t *fakeTx) Rollback(context.Context) error { return nil }
+
+// ingestStore пишущая часть Store для Ingest; остальные методы не вызываются
+type ingestStore struct {
+	repo.Store
+	mu      sync.Mutex
+	signals int
+	writes  []string // вызванные в транзакции методы
+}
+
+func (s *ingestStore) write(name string) error {
+	s.writes = append(s.writes, name)
+	return nil
+}
+
+func (s *ingestStore) Begin(context.Context) (repo.Tx, error) { return &fakeTx{}, nil }
+func (s *ingestStore) InsertEvent(_ context.Context, tx repo.Tx, _ domain.Event) (bool, error) {
+	_ = tx.(*fakeTx)
+	return true, s.write("InsertEvent")
+}
+func (s *ingestStore) FlagEvent(context.Context, repo.Tx, domain.Event) error {
+	return s.write("FlagEvent")
+}
+func (s *ingestStore) UpsertVideoCounters(context.Context, repo.Tx, domain.Event) error {
+	return s.write("UpsertVideoCounters")
+}
+func (s *ingestStore) UpsertVideoDaily(context.Context, repo.Tx, domain.Event) error {
+	return s.write("UpsertVideoDaily")
+}
+func (s *ingestStore) UpsertVideoHourly(context.Context, repo.Tx, domain.Event) error {
+	return s.write("UpsertVideoHourly")
+}
+func (s *ingestStore) UpsertWatchHistory(context.Context, repo.Tx, domain.Event) error {
+	return s.write("UpsertWatchHistory")
+}
+func (s *ingestStore) UpsertNegativeFeedback(context.Context, repo.Tx, domain.Event) error {
+	return s.write("UpsertNegativeFeedback")
+}
+func (s *ingestStore) AttributeClick(context.Context, repo.Tx, domain.Event) (bool, error) {
+	return false, s.write("AttributeClick")
+}
+func (s *ingestStore) UpsertRetention(context.Context, repo.Tx, domain.Event) error {
+	return s.write("UpsertRetention")
+}
+
+// UpdateUserSignalsBestEffort как в PostgresRepo: nil — запись вне транзакции, ошибка БД наружу
+func (s *ingestStore) UpdateUserSignalsBestEffort(_ context.Context, tx repo.Tx, _ domain.Event) error {
+	if tx != nil {
+		_ = tx.(*fakeTx)
+	}
+	s.mu.Lock()
+	s.signals++
+	s.mu.Unlock()
+	return errors.New("user_signals unavailable")
+}
+
+type chanSeen struct{ marked chan uuid.UUID }
+
+func (s *chanSeen) MarkSeen(_ context.Context, _ uuid.UUID, videoIDs []uuid.UUID, _ time.Time, _ int) error {
+	for _, id := range videoIDs {
+		s.marked <- id
+	}
+	return nil
+}
+func (s *chanSeen) GetSeen(context.Context, uuid.UUID) (map[uuid.UUID]bool, error) { return nil, nil }
+
+// newTestIdem idem без Redis: TryReserve всегда возвращает Unknown
+func newTestIdem() *idem.Service {
+	return idem.New(redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1}))
+}
+
+func TestEventsUC_IngestMarksCompleted(t *testing.T) {
+	store := &ingestStore{}
+	seen := &chanSeen{marked: make(chan uuid.UUID, 1)}
+	uc := NewEventsUC(store, newTestIdem(), seen, nil, nil, config.Config{
+		SeenCompleteCooldown: time.Hour,
+		SeenMaxItems:         100,
+	})
+
+	video := uuid.New()
+	res, err := uc.Ingest(context.Background(), domain.Event{
+		EventID: uuid.New(), TS: time.Now(), Type: domain.EventViewComplete,
+		SessionID: "s", UserID: uuid.New(), VideoID: video,
+	})
+	assert.NoError(t, err)
+	assert.True(t, res.Inserted)
+
+	select {
+	case id := <-seen.marked:
+		assert.Equal(t, video, id)
+	case <-time.After(2 * time.Second):
+		t.Fatal("completed video was not added to seen-set")
+	}
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return store.signals == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestEventsUC_IngestFlagged(t *testing.T) {
+	store := &ingestStore{}
+	rate := &memRate{hits: map[string]int64{}}
+	fraud := newTestFraudFilter(rate)
+	uc := NewEventsUC(store, newTestIdem(), &chanSeen{marked: make(chan uuid.UUID, 10)}, nil, fraud, config.Config{})
+
+	user, video := uuid.New(), uuid.New()
+	ingest := func(ua string) {
+		store.writes = nil
+		res, err := uc.Ingest(context.Background(), domain.Event{
+			EventID: uuid.New(), TS: time.Now(), Type: domain.EventViewStart,
+			SessionID: "s", UserID: user, VideoID: video, IP: "203.0.113.7", UserAgent: ua,
+		})
+		assert.NoError(t, err)
+		assert.True(t, res.Inserted)
+	}
+
+	// бот: лимиты не расходуются, счётчики не трогаются, история пользователя пишется
+	ingest("curl/8.0")
+	assert.Equal(t, []string{"InsertEvent", "UpsertWatchHistory", "UpsertNegativeFeedback"}, store.writes)
+	assert.Empty(t, rate.hits)
+
+	// в пределах лимита — обычное событие
+	ingest("Mozilla/5.0")
+	ingest("Mozilla/5.0")
+	assert.Contains(t, store.writes, "UpsertVideoCounters")
+	assert.NotContains(t, store.writes, "FlagEvent")
+
+	// сверх лимита на сессию — пометка в той же транзакции
+	ingest("Mozilla/5.0")
+	assert.Equal(t, []string{"InsertEvent", "FlagEvent", "UpsertWatchHistory", "UpsertNegativeFeedback"}, store.writes)
+}
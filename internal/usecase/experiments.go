@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+)
+
+// ExperimentsUCInterface интерфейс для тестирования
+type ExperimentsUCInterface interface {
+	Create(ctx context.Context, e domain.Experiment) (domain.Experiment, error)
+	List(ctx context.Context) ([]domain.Experiment, error)
+	Get(ctx context.Context, id uuid.UUID) (domain.Experiment, error)
+	Start(ctx context.Context, id uuid.UUID) (domain.Experiment, error)
+	Stop(ctx context.Context, id uuid.UUID) (domain.Experiment, error)
+	Results(ctx context.Context, id uuid.UUID) (domain.ExperimentResults, error)
+}
+
+// Experimenter назначает вариант идущего эксперимента для поверхности выдачи.
+// ok == false — эксперимента нет или единицу трафика не удалось определить.
+type Experimenter interface {
+	Assign(ctx context.Context, surface domain.ExperimentSurface, userID uuid.UUID, sessionID string) (domain.Assignment, bool)
+}
+
+// ExperimentsUC управление экспериментами (для админов) и назначение вариантов
+type ExperimentsUC struct {
+	store repo.Store
+	cfg   config.Config
+
+	mu       sync.RWMutex
+	loadedAt time.Time
+	running  map[domain.ExperimentSurface]domain.Experiment
+
+	// единицы, чья выдача уже записана этим процессом: повторная запись
+	// ничего не меняет (ON CONFLICT DO NOTHING), но стоит запроса в БД
+	seenMu sync.Mutex
+	seen   map[string]struct{}
+}
+
+// maxSeenExposures ограничивает память под отметки выдачи; при переполнении
+// набор сбрасывается, и повторные записи гасит ON CONFLICT
+const maxSeenExposures = 100000
+
+func NewExperimentsUC(store repo.Store, cfg config.Config) *ExperimentsUC {
+	return &ExperimentsUC{store: store, cfg: cfg}
+}
+
+func (uc *ExperimentsUC) Create(ctx context.Context, e domain.Experiment) (domain.Experiment, error) {
+	e.Key = strings.TrimSpace(e.Key)
+	if err := e.Validate(); err != nil {
+		return domain.Experiment{}, err
+	}
+	for _, v := range e.Variants {
+		if err := validateVariantParams(e.Surface, v.Params); err != nil {
+			return domain.Experiment{}, err
+		}
+	}
+	e.ID = uuid.New()
+	e.Status = domain.ExperimentDraft
+	return uc.store.CreateExperiment(ctx, e)
+}
+
+func (uc *ExperimentsUC) List(ctx context.Context) ([]domain.Experiment, error) {
+	return uc.store.ListExperiments(ctx)
+}
+
+func (uc *ExperimentsUC) Get(ctx context.Context, id uuid.UUID) (domain.Experiment, error) {
+	return uc.store.GetExperiment(ctx, id)
+}
+
+// Start запускает черновик; на поверхности не может идти второй эксперимент
+func (uc *ExperimentsUC) Start(ctx context.Context, id uuid.UUID) (domain.Experiment, error) {
+	return uc.transition(ctx, id, domain.ExperimentDraft, domain.ExperimentRunning)
+}
+
+// Stop останавливает эксперимент; результаты считаются по момент остановки
+func (uc *ExperimentsUC) Stop(ctx context.Context, id uuid.UUID) (domain.Experiment, error) {
+	return uc.transition(ctx, id, domain.ExperimentRunning, domain.ExperimentStopped)
+}
+
+func (uc *ExperimentsUC) transition(ctx context.Context, id uuid.UUID, from, to domain.ExperimentStatus) (domain.Experiment, error) {
+	if err := uc.store.SetExperimentStatus(ctx, id, from, to); err != nil {
+		return domain.Experiment{}, err
+	}
+	uc.invalidate()
+	return uc.store.GetExperiment(ctx, id)
+}
+
+// Results считает CTR, долю досмотров и среднее время просмотра по вариантам с 95% интервалами
+func (uc *ExperimentsUC) Results(ctx context.Context, id uuid.UUID) (domain.ExperimentResults, error) {
+	e, err := uc.store.GetExperiment(ctx, id)
+	if err != nil {
+		return domain.ExperimentResults{}, err
+	}
+	counts, err := uc.store.ExperimentCounts(ctx, id, e.StoppedAt)
+	if err != nil {
+		return domain.ExperimentResults{}, err
+	}
+
+	res := domain.ExperimentResults{Experiment: e, Variants: make([]domain.VariantResult, 0, len(counts))}
+	for _, c := range counts {
+		res.Variants = append(res.Variants, domain.VariantResult{
+			Variant:        c.Variant,
+			Units:          c.Units,
+			CTR:            domain.WilsonInterval(c.ClickUnits, c.Units),
+			CompletionRate: domain.WilsonInterval(min(c.Completes, c.Starts), c.Starts),
+			DwellMs:        domain.MeanInterval(c.DwellN, c.DwellSum, c.DwellSqrSum),
+		})
+	}
+	return res, nil
+}
+
+// Assign выбирает вариант идущего на поверхности эксперимента и асинхронно логирует
+// первую выдачу единице трафика
+func (uc *ExperimentsUC) Assign(ctx context.Context, surface domain.ExperimentSurface, userID uuid.UUID, sessionID string) (domain.Assignment, bool) {
+	unit := domain.ExperimentUnitID(userID, sessionID)
+	if unit == "" {
+		return domain.Assignment{}, false
+	}
+	e, ok := uc.runningOn(ctx, surface)
+	if !ok {
+		return domain.Assignment{}, false
+	}
+
+	v := e.Assign(unit)
+	exposure := domain.Exposure{ExperimentID: e.ID, UnitID: unit, Variant: v.Name}
+	if userID != uuid.Nil {
+		exposure.UserID = &userID
+	} else {
+		exposure.SessionID = &sessionID
+	}
+
+	// best-effort: выдача не должна ждать записи
+	if key := e.ID.String() + "/" + unit; uc.firstSeen(key) {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+			if err := uc.store.LogExposure(ctx, exposure); err != nil {
+				uc.forget(key) // запишем при следующей выдаче
+				slog.Warn("experiment exposure write failed", "experiment", e.Key, "err", err)
+			}
+		}()
+	}
+
+	return domain.Assignment{ExperimentID: e.ID, Key: e.Key, Variant: v.Name, Params: v.Params}, true
+}
+
+// runningOn возвращает эксперимент, идущий на поверхности, из кеша с TTL ExperimentsCacheTTL
+func (uc *ExperimentsUC) runningOn(ctx context.Context, surface domain.ExperimentSurface) (domain.Experiment, bool) {
+	uc.mu.RLock()
+	fresh := !uc.loadedAt.IsZero() && time.Since(uc.loadedAt) < uc.cfg.ExperimentsCacheTTL
+	e, ok := uc.running[surface]
+	uc.mu.RUnlock()
+	if fresh {
+		return e, ok
+	}
+
+	list, err := uc.store.ListRunningExperiments(ctx)
+	if err != nil {
+		slog.Warn("running experiments read failed", "err", err)
+		return e, ok // при ошибке продолжаем со старым списком
+	}
+	running := make(map[domain.ExperimentSurface]domain.Experiment, len(list))
+	for _, e := range list {
+		if len(e.Variants) > 0 {
+			running[e.Surface] = e
+		}
+	}
+
+	uc.mu.Lock()
+	uc.running, uc.loadedAt = running, time.Now()
+	uc.mu.Unlock()
+
+	e, ok = running[surface]
+	return e, ok
+}
+
+// firstSeen отмечает единицу и сообщает, видел ли её процесс раньше
+func (uc *ExperimentsUC) firstSeen(key string) bool {
+	uc.seenMu.Lock()
+	defer uc.seenMu.Unlock()
+	if _, ok := uc.seen[key]; ok {
+		return false
+	}
+	if uc.seen == nil || len(uc.seen) >= maxSeenExposures {
+		uc.seen = make(map[string]struct{})
+	}
+	uc.seen[key] = struct{}{}
+	return true
+}
+
+func (uc *ExperimentsUC) forget(key string) {
+	uc.seenMu.Lock()
+	delete(uc.seen, key)
+	uc.seenMu.Unlock()
+}
+
+// invalidate сбрасывает кеш после запуска или остановки эксперимента
+func (uc *ExperimentsUC) invalidate() {
+	uc.mu.Lock()
+	uc.loadedAt = time.Time{}
+	uc.mu.Unlock()
+}
+
+// assignVariant назначает вариант, если поверхность подключена к экспериментам (exp != nil)
+func assignVariant(ctx context.Context, exp Experimenter, surface domain.ExperimentSurface, userID uuid.UUID, sessionID string) (domain.Assignment, bool) {
+	if exp == nil {
+		return domain.Assignment{}, false
+	}
+	return exp.Assign(ctx, surface, userID, sessionID)
+}
+
+// validateVariantParams проверяет, что параметры варианта понимает поверхность эксперимента
+func validateVariantParams(surface domain.ExperimentSurface, params map[string]string) error {
+	for key, value := range params {
+		var ok bool
+		switch {
+		case surface == domain.SurfaceRecommendations && (key == domain.ParamPersonalSources || key == domain.ParamColdSources):
+			_, err := config.ParseRecSources(value)
+			ok = err == nil
+		case surface == domain.SurfaceRecommendations && key == domain.ParamDiversity:
+			d, err := strconv.ParseFloat(value, 64)
+			ok = err == nil && d >= 0 && d <= 1
+		case surface == domain.SurfaceFeed && key == domain.ParamPersonalize,
+			surface == domain.SurfaceSearch && key == domain.ParamSynonyms:
+			_, err := strconv.ParseBool(value)
+			ok = err == nil
+		case surface == domain.SurfaceFeed && key == domain.ParamTrendingWindow:
+			for _, w := range domain.TrendingWindows {
+				ok = ok || string(w) == value
+			}
+		}
+		if !ok {
+			return domain.ErrInvalidExperiment
+		}
+	}
+	return nil
+}
+
+// boolParam значение булевого параметра варианта или def, если он не задан
+func boolParam(params map[string]string, key string, def bool) bool {
+	if v, err := strconv.ParseBool(params[key]); err == nil {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type exposureStore struct {
+	repo.Store
+	running   []domain.Experiment
+	exposures chan domain.Exposure
+}
+
+func (s *exposureStore) ListRunningExperiments(ctx context.Context) ([]domain.Experiment, error) {
+	return s.running, nil
+}
+
+func (s *exposureStore) LogExposure(ctx context.Context, e domain.Exposure) error {
+	s.exposures <- e
+	return nil
+}
+
+func TestExperimentAssign_DeterministicAndWeighted(t *testing.T) {
+	e := domain.Experiment{
+		Key: "feed-personalize",
+		Variants: []domain.Variant{
+			{Name: "control", Weight: 3},
+			{Name: "treatment", Weight: 1},
+		},
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		unit := fmt.Sprintf("s:session-%d", i)
+		v := e.Assign(unit)
+		assert.Equal(t, v.Name, e.Assign(unit).Name)
+		counts[v.Name]++
+	}
+
+	// 3:1 с допуском на разброс хеша
+	assert.InDelta(t, 3000, counts["control"], 150)
+	assert.InDelta(t, 1000, counts["treatment"], 150)
+}
+
+func TestExperimentsUC_AssignLogsFirstExposure(t *testing.T) {
+	store := &exposureStore{
+		running: []domain.Experiment{{
+			ID:       uuid.New(),
+			Key:      "feed-personalize",
+			Surface:  domain.SurfaceFeed,
+			Variants: []domain.Variant{{Name: "control", Weight: 1}},
+		}},
+		exposures: make(chan domain.Exposure, 4),
+	}
+	uc := NewExperimentsUC(store, config.Config{ExperimentsCacheTTL: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		a, ok := uc.Assign(context.Background(), domain.SurfaceFeed, uuid.Nil, "s1")
+		assert.True(t, ok)
+		assert.Equal(t, "control", a.Variant)
+	}
+	_, ok := uc.Assign(context.Background(), domain.SurfaceFeed, uuid.Nil, "s2")
+	assert.True(t, ok)
+
+	// по одной записи на единицу трафика
+	var units []string
+	for i := 0; i < 2; i++ {
+		select {
+		case e := <-store.exposures:
+			units = append(units, e.UnitID)
+		case <-time.After(time.Second):
+			t.Fatal("exposure not logged")
+		}
+	}
+	assert.ElementsMatch(t, []string{domain.ExperimentUnitID(uuid.Nil, "s1"), domain.ExperimentUnitID(uuid.Nil, "s2")}, units)
+	select {
+	case e := <-store.exposures:
+		t.Fatalf("repeated exposure logged: %s", e.UnitID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWilsonInterval(t *testing.T) {
+	m := domain.WilsonInterval(50, 100)
+	assert.InDelta(t, 0.5, m.Value, 1e-9)
+	assert.InDelta(t, 0.404, m.Low, 0.001)
+	assert.InDelta(t, 0.596, m.High, 0.001)
+
+	assert.Equal(t, domain.Metric{}, domain.WilsonInterval(0, 0))
+}
+
+func TestMeanInterval(t *testing.T) {
+	// 1000, 2000, 3000: среднее 2000, стандартное отклонение 1000
+	m := domain.MeanInterval(3, 6000, 14e6)
+	assert.InDelta(t, 2000, m.Value, 1e-9)
+	assert.InDelta(t, 2000-1.96*1000/1.7320508, m.Low, 0.01)
+}
+
+func TestValidateVariantParams(t *testing.T) {
+	assert.NoError(t, validateVariantParams(domain.SurfaceRecommendations, map[string]string{
+		domain.ParamDiversity:       "0.5",
+		domain.ParamPersonalSources: "popular:0.5:0.8,similar:0.5:0.7",
+	}))
+	assert.NoError(t, validateVariantParams(domain.SurfaceFeed, map[string]string{domain.ParamPersonalize: "false"}))
+
+	// параметр другой поверхности
+	assert.ErrorIs(t, validateVariantParams(domain.SurfaceSearch, map[string]string{domain.ParamDiversity: "0.5"}), domain.ErrInvalidExperiment)
+	// значение вне диапазона
+	assert.ErrorIs(t, validateVariantParams(domain.SurfaceRecommendations, map[string]string{domain.ParamDiversity: "2"}), domain.ErrInvalidExperiment)
+	assert.ErrorIs(t, validateVariantParams(domain.SurfaceFeed, map[string]string{domain.ParamTrendingWindow: "1y"}), domain.ErrInvalidExperiment)
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/export"
+	"github.com/arasvet/microtube/internal/repo"
+)
+
+// ExportUCInterface интерфейс для тестирования
+type ExportUCInterface interface {
+	Export(ctx context.Context, p domain.ExportParams, w io.Writer) error
+}
+
+// ExportUC потоковые выгрузки аналитики для аналитиков (HTTP и cmd/export)
+type ExportUC struct {
+	store repo.Store
+	stats *StatsUC
+}
+
+func NewExportUC(store repo.Store) *ExportUC {
+	return &ExportUC{store: store, stats: NewStatsUC(store)}
+}
+
+// Export пишет выгрузку в w. Параметры проверяются до первой записи,
+// поэтому ошибка валидации означает, что в w ничего не записано.
+func (uc *ExportUC) Export(ctx context.Context, p domain.ExportParams, w io.Writer) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	cols := p.SelectedColumns()
+
+	var gz *gzip.Writer
+	if p.Gzip {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+	ew, err := export.NewWriter(p.Format, w, cols)
+	if err != nil {
+		return err
+	}
+
+	if p.Dataset == domain.ExportTimeseries {
+		err = uc.exportTimeseries(ctx, p, cols, ew)
+	} else {
+		names := make([]string, len(cols))
+		for i, c := range cols {
+			names[i] = c.Name
+		}
+		err = uc.store.ExportRows(ctx, p.Dataset, p.From, p.To, names, ew.WriteRow)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := ew.Close(); err != nil {
+		return err
+	}
+	if gz != nil {
+		return gz.Close()
+	}
+	return nil
+}
+
+// exportTimeseries строка на корзину каждого значения разбивки. Ряды ограничены
+// MaxTimeseriesPoints корзинами и top значениями, поэтому считаются целиком через StatsUC.
+func (uc *ExportUC) exportTimeseries(ctx context.Context, p domain.ExportParams, cols []domain.ExportColumn, ew export.Writer) error {
+	ts, err := uc.stats.Timeseries(ctx, p.Timeseries)
+	if err != nil {
+		return err
+	}
+	row := make([]any, len(cols))
+	for _, s := range ts.Series {
+		for _, pt := range s.Points {
+			for i, c := range cols {
+				switch c.Name {
+				case "ts":
+					row[i] = pt.TS
+				case "key":
+					row[i] = s.Key
+				default:
+					row[i] = int64(pt.Values[domain.StatsMetric(c.Name)])
+				}
+			}
+			if err := ew.WriteRow(row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -3,9 +3,12 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
+	"github.com/arasvet/microtube/internal/config"
 	"github.com/arasvet/microtube/internal/domain"
 	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
 )
 
 // FeedUCInterface интерфейс для тестирования
@@ -14,11 +17,15 @@ type FeedUCInterface interface {
 }
 
 type FeedUC struct {
-	store repo.Store
+	store    repo.Store
+	trending repo.TrendingStore
+	seen     repo.SeenStore
+	exp      Experimenter
+	cfg      config.Config
 }
 
-func NewFeedUC(store repo.Store) *FeedUC {
-	return &FeedUC{store: store}
+func NewFeedUC(store repo.Store, trending repo.TrendingStore, seen repo.SeenStore, exp Experimenter, cfg config.Config) *FeedUC {
+	return &FeedUC{store: store, trending: trending, seen: seen, exp: exp, cfg: cfg}
 }
 
 // GetFeed возвращает фид видео в зависимости от типа
@@ -28,7 +35,47 @@ func (uc *FeedUC) GetFeed(ctx context.Context, params domain.FeedParams) ([]doma
 		return nil, err
 	}
 
-	// Получаем видео в зависимости от типа фида
+	// Фид подписок фильтрует скрытые видео сам, чтобы не ломать курсорную пагинацию
+	if params.Type == domain.FeedTypeSubscriptions {
+		return uc.store.GetSubscriptionVideos(ctx, params.UserID, params.Cursor, params.Limit)
+	}
+
+	// Вариант эксперимента может отключить персонализацию и сменить окно trending по умолчанию
+	personalize := true
+	if a, ok := assignVariant(ctx, uc.exp, domain.SurfaceFeed, params.UserID, params.SessionID); ok {
+		personalize = boolParam(a.Params, domain.ParamPersonalize, true)
+		if w, ok := a.Params[domain.ParamTrendingWindow]; ok && params.Window == domain.TrendingWindowBlended {
+			params.Window = domain.TrendingWindow(w)
+		}
+	}
+
+	// Для авторизованного пользователя учитываем негативные сигналы и seen-set:
+	// берём запас на скрытые и просмотренные видео, затем фильтруем и переранжируем
+	var fb domain.NegativeFeedback
+	var seen map[uuid.UUID]bool
+	fetch := params
+	if params.UserID != uuid.Nil && personalize {
+		var err error
+		fb, err = uc.store.GetNegativeFeedback(ctx, params.UserID)
+		if err != nil {
+			slog.Warn("negative feedback read failed", "err", err, "user_id", params.UserID)
+		}
+		seen, err = uc.seen.GetSeen(ctx, params.UserID)
+		if err != nil {
+			slog.Warn("seen-set read failed", "err", err, "user_id", params.UserID)
+		}
+		fetch.Limit += min(len(fb.Hidden)+len(seen), params.Limit)
+	}
+
+	videos, err := uc.fetchFeed(ctx, fetch)
+	if err != nil {
+		return nil, err
+	}
+	return rerankFeed(videos, fb, seen, params.Limit), nil
+}
+
+// fetchFeed возвращает видео фида без учёта пользователя
+func (uc *FeedUC) fetchFeed(ctx context.Context, params domain.FeedParams) ([]domain.Video, error) {
 	switch params.Type {
 	case domain.FeedTypePopular:
 		return uc.store.GetPopularVideos(ctx, params.Limit)
@@ -36,7 +83,22 @@ func (uc *FeedUC) GetFeed(ctx context.Context, params domain.FeedParams) ([]doma
 		return uc.store.GetCommentedVideos(ctx, params.Limit)
 	case domain.FeedTypeRandom:
 		return uc.store.GetRandomVideos(ctx, params.Limit)
+	case domain.FeedTypeTrending:
+		return uc.getTrendingVideos(ctx, params)
 	default:
 		return nil, fmt.Errorf("unsupported feed type: %s", params.Type)
 	}
 }
+
+// getTrendingVideos читает предрасчитанный рейтинг из Redis.
+// Пока рейтинг не посчитан (холодный старт, недоступен Redis) — отдаём popular.
+func (uc *FeedUC) getTrendingVideos(ctx context.Context, params domain.FeedParams) ([]domain.Video, error) {
+	ids, err := uc.trending.GetTrending(ctx, params.Window, params.Limit)
+	if err != nil {
+		slog.Warn("trending read failed, fallback to popular", "err", err)
+	}
+	if len(ids) == 0 {
+		return uc.store.GetPopularVideos(ctx, params.Limit)
+	}
+	return uc.store.GetVideosByIDs(ctx, ids)
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"sort"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+)
+
+// rerankFeed убирает из фида скрытые и недавно просмотренные (seen) видео и опускает видео с нелюбимыми тегами.
+// Исходный порядок фида превращается в score 1..0, из которого вычитается штраф за теги.
+func rerankFeed(videos []domain.Video, fb domain.NegativeFeedback, seen map[uuid.UUID]bool, limit int) []domain.Video {
+	if fb.Empty() && len(seen) == 0 {
+		if len(videos) > limit {
+			videos = videos[:limit]
+		}
+		return videos
+	}
+
+	type scored struct {
+		video domain.Video
+		score float64
+	}
+	ranked := make([]scored, 0, len(videos))
+	for i, v := range videos {
+		if fb.IsHidden(v.ID) || seen[v.ID] {
+			continue
+		}
+		score := 1 - float64(i)/float64(len(videos))
+		ranked = append(ranked, scored{video: v, score: score - fb.Penalty(v.Tags)})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	res := make([]domain.Video, 0, len(ranked))
+	for _, r := range ranked {
+		res = append(res, r.video)
+	}
+	return res
+}
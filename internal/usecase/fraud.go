@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+)
+
+// FraudFilter помечает события ботов и накрутки просмотров и лайков
+type FraudFilter struct {
+	rate       repo.RateCounter
+	botAgents  []string
+	blocked    []netip.Prefix
+	window     time.Duration
+	sessionCap int
+	ipCap      int
+}
+
+func NewFraudFilter(rate repo.RateCounter, cfg config.Config) *FraudFilter {
+	return &FraudFilter{
+		rate:       rate,
+		botAgents:  cfg.FraudBotUserAgents,
+		blocked:    cfg.FraudBlockedIPs,
+		window:     cfg.FraudRateWindow,
+		sessionCap: cfg.FraudSessionCap,
+		ipCap:      cfg.FraudIPCap,
+	}
+}
+
+// PatternFlag пометка по User-Agent и IP, без обращения к Redis
+func (f *FraudFilter) PatternFlag(e domain.Event) domain.EventFlag {
+	ua := strings.ToLower(e.UserAgent)
+	for _, bot := range f.botAgents {
+		if strings.Contains(ua, bot) {
+			return domain.FlagBotUserAgent
+		}
+	}
+	if len(f.blocked) > 0 {
+		if addr, err := netip.ParseAddr(e.IP); err == nil {
+			addr = addr.Unmap()
+			for _, p := range f.blocked {
+				if p.Contains(addr) {
+					return domain.FlagBlockedIP
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// RateFlag засчитывает записанное событие в лимиты на сессию и IP и возвращает пометку,
+// если лимит превышен. undo отменяет попадания, если событие в итоге не записалось:
+// повтор упавшей вставки не должен расходовать лимит дважды.
+// Если Redis недоступен, событие не помечается: лучше пропустить накрутку, чем не засчитать настоящие просмотры.
+func (f *FraudFilter) RateFlag(ctx context.Context, e domain.Event) (flag domain.EventFlag, undo func()) {
+	var hits []string
+	undo = func() {
+		for _, key := range hits {
+			if err := f.rate.UnhitRate(context.WithoutCancel(ctx), key); err != nil {
+				slog.Warn("fraud rate counter undo failed", "event_id", e.EventID, "err", err)
+			}
+		}
+	}
+	if !domain.RateLimitedEvent(e.Type) || e.VideoID == uuid.Nil || f.rate == nil || f.window <= 0 {
+		return "", undo
+	}
+	// Лимит на сессию отсекает только наивные повторы: session_id выбирает клиент
+	if f.sessionCap > 0 && f.overCap(ctx, "s:"+e.SessionID, e, f.sessionCap, &hits) {
+		return domain.FlagSessionRate, undo
+	}
+	if f.ipCap > 0 && e.IP != "" && f.overCap(ctx, "ip:"+e.IP, e, f.ipCap, &hits) {
+		return domain.FlagIPRate, undo
+	}
+	return "", undo
+}
+
+// overCap засчитывает событие actor'у и сообщает, превышен ли лимит на видео за окно
+func (f *FraudFilter) overCap(ctx context.Context, actor string, e domain.Event, limit int, hits *[]string) bool {
+	key := string(e.Type) + ":" + actor + ":" + e.VideoID.String()
+	n, err := f.rate.HitRate(ctx, key, f.window)
+	if err != nil {
+		slog.Warn("fraud rate counter failed", "event_id", e.EventID, "err", err)
+		return false
+	}
+	*hits = append(*hits, key)
+	return n > int64(limit)
+}
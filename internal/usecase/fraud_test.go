@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// memRate счётчики в памяти вместо Redis
+type memRate struct {
+	hits map[string]int64
+	err  error
+}
+
+func (m *memRate) HitRate(_ context.Context, key string, _ time.Duration) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	m.hits[key]++
+	return m.hits[key], nil
+}
+
+func (m *memRate) UnhitRate(_ context.Context, key string) error {
+	m.hits[key]--
+	return nil
+}
+
+func newTestFraudFilter(rate *memRate) *FraudFilter {
+	return NewFraudFilter(rate, config.Config{
+		FraudBotUserAgents: []string{"bot", "curl"},
+		FraudBlockedIPs:    []netip.Prefix{netip.MustParsePrefix("198.51.100.0/24")},
+		FraudRateWindow:    time.Hour,
+		FraudSessionCap:    2,
+		FraudIPCap:         3,
+	})
+}
+
+func TestFraudFilter_Patterns(t *testing.T) {
+	f := newTestFraudFilter(&memRate{hits: map[string]int64{}})
+	e := domain.Event{Type: domain.EventViewStart, SessionID: "s", VideoID: uuid.New(), IP: "203.0.113.7"}
+
+	assert.Equal(t, domain.EventFlag(""), f.PatternFlag(e))
+
+	bot := e
+	bot.UserAgent = "Mozilla/5.0 (compatible; Googlebot/2.1)"
+	assert.Equal(t, domain.FlagBotUserAgent, f.PatternFlag(bot))
+
+	blocked := e
+	blocked.IP = "::ffff:198.51.100.20"
+	assert.Equal(t, domain.FlagBlockedIP, f.PatternFlag(blocked))
+}
+
+func TestFraudFilter_RateCaps(t *testing.T) {
+	f := newTestFraudFilter(&memRate{hits: map[string]int64{}})
+	video := uuid.New()
+	view := func(session string) domain.EventFlag {
+		flag, _ := f.RateFlag(context.Background(), domain.Event{
+			Type: domain.EventViewStart, SessionID: session, VideoID: video, IP: "203.0.113.7",
+		})
+		return flag
+	}
+
+	assert.Equal(t, domain.EventFlag(""), view("a"))
+	assert.Equal(t, domain.EventFlag(""), view("a"))
+	assert.Equal(t, domain.FlagSessionRate, view("a"))
+	// лимит на IP: третий засчитанный просмотр с адреса последний
+	assert.Equal(t, domain.EventFlag(""), view("b"))
+	assert.Equal(t, domain.FlagIPRate, view("c"))
+
+	// другие типы событий не лимитируются
+	flag, _ := f.RateFlag(context.Background(), domain.Event{
+		Type: domain.EventViewComplete, SessionID: "a", VideoID: video, IP: "203.0.113.7",
+	})
+	assert.Equal(t, domain.EventFlag(""), flag)
+}
+
+func TestFraudFilter_RateUndo(t *testing.T) {
+	rate := &memRate{hits: map[string]int64{}}
+	f := newTestFraudFilter(rate)
+	e := domain.Event{Type: domain.EventLike, SessionID: "s", VideoID: uuid.New(), IP: "203.0.113.7"}
+
+	// вставка не записалась и повторяется: лимит расходуется один раз
+	for i := 0; i < 5; i++ {
+		flag, undo := f.RateFlag(context.Background(), e)
+		assert.Equal(t, domain.EventFlag(""), flag)
+		undo()
+	}
+	for _, n := range rate.hits {
+		assert.Equal(t, int64(0), n)
+	}
+}
+
+func TestFraudFilter_RateCounterDown(t *testing.T) {
+	f := newTestFraudFilter(&memRate{err: errors.New("redis down")})
+	e := domain.Event{Type: domain.EventLike, SessionID: "s", VideoID: uuid.New(), IP: "203.0.113.7"}
+	for i := 0; i < 5; i++ {
+		flag, undo := f.RateFlag(context.Background(), e)
+		assert.Equal(t, domain.EventFlag(""), flag)
+		undo()
+	}
+}
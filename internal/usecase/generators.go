@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+)
+
+// RegisterGenerator подключает источник кандидатов; в выдачу он попадает,
+// когда его имя указано в RECS_PERSONAL_SOURCES или RECS_COLD_SOURCES
+func (uc *RecommendationsUC) RegisterGenerator(g CandidateGenerator) {
+	uc.pipeline.register(g)
+}
+
+// registerDefaultGenerators встроенные источники кандидатов
+func (uc *RecommendationsUC) registerDefaultGenerators() {
+	uc.RegisterGenerator(generatorFunc{"collaborative", domain.ReasonCollaborative, uc.generateCollaborative})
+	uc.RegisterGenerator(generatorFunc{"user_tags", domain.ReasonUserTags, uc.generateUserTags})
+	uc.RegisterGenerator(generatorFunc{"session_tags", domain.ReasonUserTags, uc.generateSessionTags})
+	uc.RegisterGenerator(generatorFunc{"similar", domain.ReasonSimilar, uc.generateSimilar})
+	uc.RegisterGenerator(generatorFunc{"popular", domain.ReasonPopular, uc.generatePopular})
+	uc.RegisterGenerator(generatorFunc{"diversify", domain.ReasonDiversify, uc.generateRandom})
+	uc.RegisterGenerator(generatorFunc{"exploration", domain.ReasonExploration, uc.generateExploration})
+}
+
+// generateCollaborative видео по факторам ALS
+func (uc *RecommendationsUC) generateCollaborative(ctx context.Context, req *RecRequest, limit int) ([]domain.RecommendationResult, error) {
+	if req.UserID == uuid.Nil {
+		return nil, nil
+	}
+	videos, err := uc.collaborativeForUser(ctx, req.UserID, limit)
+	return asResults(videos), err
+}
+
+// generateUserTags видео по любимым тегам пользователя
+func (uc *RecommendationsUC) generateUserTags(ctx context.Context, req *RecRequest, limit int) ([]domain.RecommendationResult, error) {
+	if req.UserID == uuid.Nil {
+		return nil, nil
+	}
+	tags, err := uc.store.GetUserTopTags(ctx, req.UserID.String())
+	if err != nil || len(tags) == 0 {
+		return nil, err
+	}
+	return uc.videosByTags(ctx, tags, limit)
+}
+
+// generateSessionTags видео по тегам, просмотренным в гостевой сессии
+func (uc *RecommendationsUC) generateSessionTags(ctx context.Context, req *RecRequest, limit int) ([]domain.RecommendationResult, error) {
+	if req.SessionID == "" {
+		return nil, nil
+	}
+	tags, err := uc.store.GetSessionTopTags(ctx, req.SessionID)
+	if err != nil || len(tags) == 0 {
+		return nil, err
+	}
+	return uc.videosByTags(ctx, tags, limit)
+}
+
+// generateSimilar соседи последних просмотренных видео по совместным просмотрам
+func (uc *RecommendationsUC) generateSimilar(ctx context.Context, req *RecRequest, limit int) ([]domain.RecommendationResult, error) {
+	if req.UserID == uuid.Nil {
+		return nil, nil
+	}
+	return uc.covisitedForUser(ctx, req.UserID, limit)
+}
+
+// generatePopular популярные видео
+func (uc *RecommendationsUC) generatePopular(ctx context.Context, _ *RecRequest, limit int) ([]domain.RecommendationResult, error) {
+	videos, err := uc.store.GetPopularVideos(ctx, limit)
+	return asResults(videos), err
+}
+
+// generateRandom случайные видео для диверсификации
+func (uc *RecommendationsUC) generateRandom(ctx context.Context, req *RecRequest, limit int) ([]domain.RecommendationResult, error) {
+	videos, err := uc.store.GetDiversifiedVideos(ctx, seenIDs(req.Seen), limit)
+	return asResults(videos), err
+}
+
+// videosByTags видео по тегам интересов с совпавшими тегами в объяснении
+func (uc *RecommendationsUC) videosByTags(ctx context.Context, tags []domain.TagWeight, limit int) ([]domain.RecommendationResult, error) {
+	videos, err := uc.store.GetVideosByTags(ctx, domain.TagNames(tags), limit)
+	if err != nil {
+		return nil, err
+	}
+	results := asResults(videos)
+	for i := range results {
+		results[i].Explanation.MatchedTags = domain.MatchTags(results[i].Video.Tags, tags)
+	}
+	return results, nil
+}
+
+func asResults(videos []domain.Video) []domain.RecommendationResult {
+	results := make([]domain.RecommendationResult, 0, len(videos))
+	for _, v := range videos {
+		results = append(results, domain.RecommendationResult{Video: v})
+	}
+	return results
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+)
+
+// HistoryUCInterface интерфейс для тестирования
+type HistoryUCInterface interface {
+	History(ctx context.Context, params domain.HistoryParams) (domain.HistoryPage, error)
+	ContinueWatching(ctx context.Context, userID uuid.UUID, limit int) ([]domain.HistoryItem, error)
+	Delete(ctx context.Context, userID uuid.UUID, videoID *uuid.UUID) (int64, error)
+	Settings(ctx context.Context, userID uuid.UUID) (domain.HistorySettings, error)
+	SetPaused(ctx context.Context, userID uuid.UUID, paused bool) (domain.HistorySettings, error)
+}
+
+type HistoryUC struct {
+	store repo.Store
+}
+
+func NewHistoryUC(store repo.Store) *HistoryUC {
+	return &HistoryUC{store: store}
+}
+
+// History возвращает страницу истории просмотров (одна запись на видео, новые сверху)
+func (uc *HistoryUC) History(ctx context.Context, params domain.HistoryParams) (domain.HistoryPage, error) {
+	if err := params.Validate(); err != nil {
+		return domain.HistoryPage{}, err
+	}
+
+	limit := params.Limit
+	params.Limit++
+	items, err := uc.store.ListWatchHistory(ctx, params)
+	if err != nil {
+		return domain.HistoryPage{}, err
+	}
+
+	page := domain.HistoryPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = domain.Cursor{TS: last.LastWatchedAt, ID: last.Video.ID}.Encode()
+	}
+	if page.Items == nil {
+		page.Items = []domain.HistoryItem{}
+	}
+	return page, nil
+}
+
+// ContinueWatching возвращает начатые, но не досмотренные видео с позицией продолжения
+func (uc *HistoryUC) ContinueWatching(ctx context.Context, userID uuid.UUID, limit int) ([]domain.HistoryItem, error) {
+	params := domain.HistoryParams{UserID: userID, Limit: limit}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	items, err := uc.store.ListUnfinished(ctx, userID, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []domain.HistoryItem{}
+	}
+	return items, nil
+}
+
+// Delete удаляет видео из истории или, при videoID == nil, всю историю
+func (uc *HistoryUC) Delete(ctx context.Context, userID uuid.UUID, videoID *uuid.UUID) (int64, error) {
+	return uc.store.DeleteWatchHistory(ctx, userID, videoID)
+}
+
+func (uc *HistoryUC) Settings(ctx context.Context, userID uuid.UUID) (domain.HistorySettings, error) {
+	return uc.store.GetHistorySettings(ctx, userID)
+}
+
+// SetPaused включает или выключает паузу истории
+func (uc *HistoryUC) SetPaused(ctx context.Context, userID uuid.UUID, paused bool) (domain.HistorySettings, error) {
+	if err := uc.store.SetHistoryPaused(ctx, userID, paused); err != nil {
+		return domain.HistorySettings{}, err
+	}
+	return domain.HistorySettings{Paused: paused}, nil
+}
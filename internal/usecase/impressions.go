@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+)
+
+const (
+	// serveBatch сколько выдач пишется одной транзакцией; заполненная пачка пишется не дожидаясь интервала
+	serveBatch = 500
+	// maxPendingServes сколько выдач ждёт записи; если БД не успевает, новые отбрасываются
+	maxPendingServes = 20000
+)
+
+// ServeLogger логирует выдачу и возвращает её serve_id
+type ServeLogger interface {
+	LogServe(ctx context.Context, s domain.Serve) uuid.UUID
+}
+
+// ImpressionsUC логирование показов выдачи и их срок хранения
+type ImpressionsUC struct {
+	store     repo.Store
+	reconcile *ReconcileUC
+	every     time.Duration
+	retention time.Duration
+
+	mu      sync.Mutex
+	pending []domain.Serve
+	full    chan struct{}
+}
+
+func NewImpressionsUC(store repo.Store, cfg config.Config) *ImpressionsUC {
+	return &ImpressionsUC{
+		store:     store,
+		reconcile: NewReconcileUC(store),
+		every:     cfg.ImpressionsFlushInterval,
+		retention: cfg.ServesRetention,
+		full:      make(chan struct{}, 1),
+	}
+}
+
+// LogServe присваивает выдаче serve_id и ставит её в очередь на запись (Run).
+// best-effort: ответ клиенту не ждёт записи, потерянная выдача только занижает статистику.
+func (uc *ImpressionsUC) LogServe(_ context.Context, s domain.Serve) uuid.UUID {
+	s.ID = uuid.New()
+	if s.TS.IsZero() {
+		s.TS = time.Now()
+	}
+
+	uc.mu.Lock()
+	n := len(uc.pending)
+	if n < maxPendingServes {
+		uc.pending = append(uc.pending, s)
+	}
+	uc.mu.Unlock()
+
+	switch {
+	case n >= maxPendingServes:
+		slog.Warn("serve log queue is full, serve dropped", "surface", s.Surface)
+	case n+1 >= serveBatch:
+		select {
+		case uc.full <- struct{}{}:
+		default:
+		}
+	}
+	return s.ID
+}
+
+// Run пишет накопленные выдачи раз в интервал или по заполнении пачки до отмены ctx.
+// Оставшееся в очереди при остановке пишет Flush.
+func (uc *ImpressionsUC) Run(ctx context.Context) {
+	every := uc.every
+	if every <= 0 {
+		every = time.Second
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-uc.full:
+		}
+		uc.Flush(ctx)
+	}
+}
+
+// Flush пишет все выдачи из очереди пачками по serveBatch
+func (uc *ImpressionsUC) Flush(ctx context.Context) {
+	uc.mu.Lock()
+	serves := uc.pending
+	uc.pending = nil
+	uc.mu.Unlock()
+
+	for start := 0; start < len(serves); start += serveBatch {
+		batch := serves[start:min(start+serveBatch, len(serves))]
+		if err := uc.store.LogServes(ctx, batch); err != nil {
+			slog.Warn("serve log write failed", "serves", len(batch), "err", err)
+		}
+	}
+}
+
+// Cleanup удаляет выдачи и показы старше срока хранения, целыми сутками. Перед удалением
+// video_daily и video_hourly за эти дни сверяются и исправляются: потом пересчитать показы будет не из чего.
+func (uc *ImpressionsUC) Cleanup(ctx context.Context) error {
+	if uc.retention <= 0 {
+		return nil
+	}
+	cutoff := time.Now().UTC().Add(-uc.retention).Truncate(24 * time.Hour)
+	oldest, err := uc.store.OldestServe(ctx)
+	if err != nil {
+		return err
+	}
+	if oldest.IsZero() || !oldest.Before(cutoff) {
+		return nil
+	}
+
+	repaired, err := uc.reconcile.RepairRollups(ctx, oldest, cutoff)
+	if err != nil {
+		return err
+	}
+	deleted, err := uc.store.DeleteServes(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+	slog.Info("serves deleted", "before", cutoff, "serves", deleted, "repaired", repaired)
+	return nil
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type serveStore struct {
+	repo.Store
+	batches [][]domain.Serve
+}
+
+func (s *serveStore) LogServes(_ context.Context, serves []domain.Serve) error {
+	s.batches = append(s.batches, serves)
+	return nil
+}
+
+func TestImpressionsUC_FlushBatches(t *testing.T) {
+	store := &serveStore{}
+	uc := NewImpressionsUC(store, config.Config{})
+
+	ids := map[uuid.UUID]bool{}
+	for i := 0; i < serveBatch+1; i++ {
+		ids[uc.LogServe(context.Background(), domain.Serve{Surface: domain.SurfaceFeed})] = true
+	}
+	assert.Len(t, ids, serveBatch+1)
+	// заполненная пачка будит Run
+	assert.Len(t, uc.full, 1)
+
+	uc.Flush(context.Background())
+	if assert.Len(t, store.batches, 2) {
+		assert.Len(t, store.batches[0], serveBatch)
+		assert.Len(t, store.batches[1], 1)
+		assert.False(t, store.batches[0][0].TS.IsZero())
+	}
+
+	// очередь пуста — повторная запись ничего не пишет
+	uc.Flush(context.Background())
+	assert.Len(t, store.batches, 2)
+}
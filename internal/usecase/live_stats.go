@@ -0,0 +1,287 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+)
+
+// LiveObserver принимает события, записанные ingest'ом
+type LiveObserver interface {
+	Observe(e domain.Event)
+}
+
+// LiveStatsUCInterface интерфейс для тестирования
+type LiveStatsUCInterface interface {
+	Subscribe() (<-chan domain.LiveSnapshot, func())
+}
+
+// LiveStatsUC live-статистика по всем репликам API. Каждая реплика копит принятые события
+// за секунду и публикует дельту в Redis; все реплики складывают дельты из канала
+// и раз в секунду рассылают снимок своим SSE-подписчикам.
+type LiveStatsUC struct {
+	store  repo.Store
+	bus    repo.LiveStatsBus
+	window time.Duration // окно зрителей и live-топа
+	id     string        // реплика, для логов
+
+	mu      sync.Mutex
+	pending domain.LiveDelta // события этой реплики за текущую секунду
+
+	agg liveAggregate // заполняется только из Run
+
+	subsMu sync.Mutex
+	subs   map[chan domain.LiveSnapshot]struct{}
+	last   domain.LiveSnapshot
+
+	titles map[uuid.UUID]string // кэш названий видео топа, только из Run
+}
+
+func NewLiveStatsUC(store repo.Store, bus repo.LiveStatsBus, window time.Duration) *LiveStatsUC {
+	return &LiveStatsUC{
+		store:   store,
+		bus:     bus,
+		window:  window,
+		id:      uuid.NewString(),
+		pending: domain.LiveDelta{ByType: map[domain.EventType]int64{}},
+		agg:     newLiveAggregate(),
+		subs:    map[chan domain.LiveSnapshot]struct{}{},
+		titles:  map[uuid.UUID]string{},
+	}
+}
+
+// Observe учитывает событие в дельте текущей секунды; не блокирует ingest
+func (uc *LiveStatsUC) Observe(e domain.Event) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.pending.ByType[e.Type]++
+	if e.Type == domain.EventViewStart && e.VideoID != uuid.Nil {
+		uc.pending.Views = append(uc.pending.Views, domain.LiveView{SessionID: e.SessionID, VideoID: e.VideoID})
+	}
+}
+
+// Subscribe снимки раз в секунду; сразу отдаётся последний. Медленный подписчик
+// пропускает промежуточные снимки. Вызов cancel отписывает и закрывает канал.
+func (uc *LiveStatsUC) Subscribe() (<-chan domain.LiveSnapshot, func()) {
+	ch := make(chan domain.LiveSnapshot, 1)
+	uc.subsMu.Lock()
+	uc.subs[ch] = struct{}{}
+	if !uc.last.TS.IsZero() {
+		ch <- uc.last
+	}
+	uc.subsMu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			uc.subsMu.Lock()
+			delete(uc.subs, ch)
+			uc.subsMu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Run публикует дельты, принимает дельты всех реплик и рассылает снимки до отмены ctx
+func (uc *LiveStatsUC) Run(ctx context.Context) {
+	deltas := uc.bus.SubscribeLiveStats(ctx)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload, ok := <-deltas:
+			if !ok {
+				return
+			}
+			var d domain.LiveDelta
+			if err := json.Unmarshal(payload, &d); err != nil {
+				slog.Warn("live stats: bad delta", "err", err)
+				continue
+			}
+			uc.agg.merge(d, time.Now(), uc.window)
+		case now := <-ticker.C:
+			uc.flush(ctx, now)
+			uc.broadcast(ctx, now)
+		}
+	}
+}
+
+// flush публикует события этой реплики за прошедшую секунду
+func (uc *LiveStatsUC) flush(ctx context.Context, now time.Time) {
+	uc.mu.Lock()
+	d := uc.pending
+	uc.pending = domain.LiveDelta{ByType: map[domain.EventType]int64{}}
+	uc.mu.Unlock()
+	if len(d.ByType) == 0 {
+		return
+	}
+	d.Replica = uc.id
+	d.TS = now.Truncate(time.Second).Add(-time.Second)
+
+	payload, err := json.Marshal(d)
+	if err == nil {
+		pctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		err = uc.bus.PublishLiveStats(pctx, payload)
+		cancel()
+	}
+	if err != nil {
+		// Redis недоступен: учитываем хотя бы свои события
+		slog.Warn("live stats: publish failed", "replica", uc.id, "err", err)
+		uc.agg.merge(d, now, uc.window)
+	}
+}
+
+// broadcast считает снимок и рассылает подписчикам
+func (uc *LiveStatsUC) broadcast(ctx context.Context, now time.Time) {
+	snap := uc.agg.snapshot(now, uc.window)
+	uc.fillTitles(ctx, snap.TopVideos)
+
+	uc.subsMu.Lock()
+	defer uc.subsMu.Unlock()
+	uc.last = snap
+	for ch := range uc.subs {
+		// Вытесняем непрочитанный снимок: подписчику нужен только свежий
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- snap
+	}
+}
+
+// fillTitles названия видео топа; кэш сбрасывается, когда разрастается
+func (uc *LiveStatsUC) fillTitles(ctx context.Context, top []domain.LiveVideo) {
+	var missing []uuid.UUID
+	for _, v := range top {
+		if _, ok := uc.titles[v.VideoID]; !ok {
+			missing = append(missing, v.VideoID)
+		}
+	}
+	if len(missing) > 0 {
+		if len(uc.titles) > 10000 {
+			uc.titles = map[uuid.UUID]string{}
+		}
+		qctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		videos, err := uc.store.GetVideosByIDs(qctx, missing)
+		cancel()
+		if err != nil {
+			slog.Warn("live stats: video titles", "err", err)
+		}
+		for _, v := range videos {
+			uc.titles[v.ID] = v.Title
+		}
+	}
+	for i := range top {
+		top[i].Title = uc.titles[top[i].VideoID]
+	}
+}
+
+// liveSecond сумма дельт всех реплик за одну секунду
+type liveSecond struct {
+	byType map[domain.EventType]int64
+	videos map[uuid.UUID]int64
+}
+
+// liveAggregate скользящие окна по секундам: поток событий, зрители и просмотры видео
+type liveAggregate struct {
+	seconds map[int64]*liveSecond
+	viewers map[string]time.Time // сессия -> последний view_start
+	videos  map[uuid.UUID]int64  // просмотры за окно, сумма по seconds
+}
+
+func newLiveAggregate() liveAggregate {
+	return liveAggregate{
+		seconds: map[int64]*liveSecond{},
+		viewers: map[string]time.Time{},
+		videos:  map[uuid.UUID]int64{},
+	}
+}
+
+// merge добавляет дельту; слишком старые (за окном) отбрасываются
+func (a *liveAggregate) merge(d domain.LiveDelta, now time.Time, window time.Duration) {
+	if !d.TS.After(now.Add(-window)) {
+		return
+	}
+	sec := d.TS.Unix()
+	s, ok := a.seconds[sec]
+	if !ok {
+		s = &liveSecond{byType: map[domain.EventType]int64{}, videos: map[uuid.UUID]int64{}}
+		a.seconds[sec] = s
+	}
+	for t, n := range d.ByType {
+		s.byType[t] += n
+	}
+	for _, v := range d.Views {
+		s.videos[v.VideoID]++
+		a.videos[v.VideoID]++
+		if d.TS.After(a.viewers[v.SessionID]) {
+			a.viewers[v.SessionID] = d.TS
+		}
+	}
+}
+
+// snapshot вычищает вышедшее из окна и считает снимок на момент now
+func (a *liveAggregate) snapshot(now time.Time, window time.Duration) domain.LiveSnapshot {
+	from := now.Add(-window).Unix()
+	for sec, s := range a.seconds {
+		if sec > from {
+			continue
+		}
+		for id, n := range s.videos {
+			if a.videos[id] -= n; a.videos[id] <= 0 {
+				delete(a.videos, id)
+			}
+		}
+		delete(a.seconds, sec)
+	}
+	for session, ts := range a.viewers {
+		if ts.Unix() <= from {
+			delete(a.viewers, session)
+		}
+	}
+
+	// Текущая и прошлая секунды ещё неполные: дельты реплик приходят с задержкой
+	snap := domain.LiveSnapshot{
+		TS:        now,
+		ByType:    map[domain.EventType]int64{},
+		Viewers:   len(a.viewers),
+		TopVideos: []domain.LiveVideo{},
+	}
+	last := now.Truncate(time.Second).Add(-2 * time.Second).Unix()
+	var events int64
+	for sec := last - domain.LiveRateWindow + 1; sec <= last; sec++ {
+		s, ok := a.seconds[sec]
+		if !ok {
+			continue
+		}
+		for t, n := range s.byType {
+			snap.ByType[t] += n
+			events += n
+		}
+	}
+	snap.EventsPerSec = float64(events) / domain.LiveRateWindow
+
+	for id, n := range a.videos {
+		snap.TopVideos = append(snap.TopVideos, domain.LiveVideo{VideoID: id, Views: n})
+	}
+	sort.Slice(snap.TopVideos, func(i, j int) bool {
+		if snap.TopVideos[i].Views != snap.TopVideos[j].Views {
+			return snap.TopVideos[i].Views > snap.TopVideos[j].Views
+		}
+		return snap.TopVideos[i].VideoID.String() < snap.TopVideos[j].VideoID.String()
+	})
+	if len(snap.TopVideos) > domain.LiveTopVideos {
+		snap.TopVideos = snap.TopVideos[:domain.LiveTopVideos]
+	}
+	return snap
+}
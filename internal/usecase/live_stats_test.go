@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveAggregate_Snapshot(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 10, 300e6, time.UTC)
+	sec := func(ago int) time.Time { return now.Truncate(time.Second).Add(-time.Duration(ago) * time.Second) }
+	hot, cold := uuid.New(), uuid.New()
+	window := time.Minute
+
+	a := newLiveAggregate()
+	// Две реплики в одну секунду складываются
+	a.merge(domain.LiveDelta{Replica: "a", TS: sec(2), ByType: map[domain.EventType]int64{domain.EventViewStart: 2},
+		Views: []domain.LiveView{{SessionID: "s1", VideoID: hot}, {SessionID: "s2", VideoID: hot}}}, now, window)
+	a.merge(domain.LiveDelta{Replica: "b", TS: sec(2), ByType: map[domain.EventType]int64{domain.EventLike: 3},
+		Views: nil}, now, window)
+	a.merge(domain.LiveDelta{Replica: "a", TS: sec(4), ByType: map[domain.EventType]int64{domain.EventViewStart: 1},
+		Views: []domain.LiveView{{SessionID: "s1", VideoID: cold}}}, now, window)
+	// Прошлая секунда ещё неполная — в поток событий не входит, но зрителей и топ пополняет
+	a.merge(domain.LiveDelta{Replica: "a", TS: sec(1), ByType: map[domain.EventType]int64{domain.EventViewStart: 1},
+		Views: []domain.LiveView{{SessionID: "s3", VideoID: hot}}}, now, window)
+	// За окном — отбрасывается
+	a.merge(domain.LiveDelta{Replica: "a", TS: sec(120), ByType: map[domain.EventType]int64{domain.EventViewStart: 1},
+		Views: []domain.LiveView{{SessionID: "old", VideoID: cold}}}, now, window)
+
+	snap := a.snapshot(now, window)
+
+	assert.InDelta(t, 6.0/domain.LiveRateWindow, snap.EventsPerSec, 1e-9)
+	assert.Equal(t, int64(3), snap.ByType[domain.EventViewStart])
+	assert.Equal(t, int64(3), snap.ByType[domain.EventLike])
+	assert.Equal(t, 3, snap.Viewers)
+	if assert.Len(t, snap.TopVideos, 2) {
+		assert.Equal(t, domain.LiveVideo{VideoID: hot, Views: 3}, snap.TopVideos[0])
+		assert.Equal(t, domain.LiveVideo{VideoID: cold, Views: 1}, snap.TopVideos[1])
+	}
+
+	// Через окно всё вычищено
+	later := a.snapshot(now.Add(window+2*time.Second), window)
+	assert.Zero(t, later.Viewers)
+	assert.Empty(t, later.TopVideos)
+	assert.Empty(t, a.seconds)
+	assert.Empty(t, a.videos)
+}
+
+func TestLiveStatsUC_SubscribeGetsLatestSnapshot(t *testing.T) {
+	uc := NewLiveStatsUC(nil, nil, time.Minute)
+	ch, cancel := uc.Subscribe()
+
+	now := time.Now()
+	uc.broadcast(context.Background(), now)
+	uc.broadcast(context.Background(), now.Add(time.Second))
+
+	// Медленный подписчик получает только последний снимок
+	snap := <-ch
+	assert.Equal(t, now.Add(time.Second), snap.TS)
+
+	// Новый подписчик сразу получает последний снимок
+	ch2, cancel2 := uc.Subscribe()
+	assert.Equal(t, now.Add(time.Second), (<-ch2).TS)
+
+	cancel()
+	cancel() // повторная отписка безопасна
+	cancel2()
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func TestLiveStatsUC_Observe(t *testing.T) {
+	uc := NewLiveStatsUC(nil, nil, time.Minute)
+	video := uuid.New()
+	uc.Observe(domain.Event{Type: domain.EventViewStart, SessionID: "s1", VideoID: video})
+	uc.Observe(domain.Event{Type: domain.EventSearchQuery, SessionID: "s1", Query: "go"})
+
+	assert.Equal(t, int64(1), uc.pending.ByType[domain.EventViewStart])
+	assert.Equal(t, int64(1), uc.pending.ByType[domain.EventSearchQuery])
+	assert.Equal(t, []domain.LiveView{{SessionID: "s1", VideoID: video}}, uc.pending.Views)
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"github.com/arasvet/microtube/internal/domain"
+)
+
+// Веса признаков в сходстве двух видео для MMR (в сумме 1)
+const (
+	mmrTagWeight    = 0.5
+	mmrAuthorWeight = 0.3
+	mmrLangWeight   = 0.2
+)
+
+// mmrRerank выбирает limit видео по maximal marginal relevance:
+// на каждом шаге берётся кандидат с максимумом (1-diversity)*score - diversity*max sim(кандидат, выбранные).
+// Дополнительно подряд идёт не больше maxAuthorRun видео одного автора (0 — без ограничения),
+// если только кроме них кандидатов не осталось.
+func mmrRerank(pool []domain.RecommendationResult, limit int, diversity float64, maxAuthorRun int) []domain.RecommendationResult {
+	if limit > len(pool) {
+		limit = len(pool)
+	}
+	results := make([]domain.RecommendationResult, 0, limit)
+	used := make([]bool, len(pool))
+	// maxSim[i] — наибольшее сходство кандидата i с уже выбранными
+	maxSim := make([]float64, len(pool))
+
+	for len(results) < limit {
+		best, bestBlocked := -1, -1
+		var bestValue, bestBlockedValue float64
+		for i, c := range pool {
+			if used[i] {
+				continue
+			}
+			value := (1-diversity)*c.Score - diversity*maxSim[i]
+			if authorRunExceeded(results, c.Video, maxAuthorRun) {
+				if bestBlocked < 0 || value > bestBlockedValue {
+					bestBlocked, bestBlockedValue = i, value
+				}
+				continue
+			}
+			if best < 0 || value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+		if best < 0 {
+			best = bestBlocked
+		}
+
+		used[best] = true
+		chosen := pool[best]
+		if chosen.Explanation.Scores != nil {
+			scores := *chosen.Explanation.Scores
+			scores.Redundancy = diversity * maxSim[best]
+			chosen.Explanation.Scores = &scores
+		}
+		results = append(results, chosen)
+		for i := range pool {
+			if !used[i] {
+				if s := videoSimilarity(pool[i].Video, pool[best].Video); s > maxSim[i] {
+					maxSim[i] = s
+				}
+			}
+		}
+	}
+	return results
+}
+
+// authorRunExceeded true, если последние maxRun выбранных видео того же автора, что и v
+func authorRunExceeded(results []domain.RecommendationResult, v domain.Video, maxRun int) bool {
+	if maxRun <= 0 || v.AuthorID == nil || len(results) < maxRun {
+		return false
+	}
+	for _, r := range results[len(results)-maxRun:] {
+		if r.Video.AuthorID == nil || *r.Video.AuthorID != *v.AuthorID {
+			return false
+		}
+	}
+	return true
+}
+
+// videoSimilarity сходство видео в [0, 1]: Жаккар по тегам, общий автор и общий язык
+func videoSimilarity(a, b domain.Video) float64 {
+	var sim float64
+	if ta, tb := tagSet(a.Tags), tagSet(b.Tags); len(ta) > 0 && len(tb) > 0 {
+		common := 0
+		for t := range ta {
+			if tb[t] {
+				common++
+			}
+		}
+		sim += mmrTagWeight * float64(common) / float64(len(ta)+len(tb)-common)
+	}
+	if a.AuthorID != nil && b.AuthorID != nil && *a.AuthorID == *b.AuthorID {
+		sim += mmrAuthorWeight
+	}
+	if a.Lang != "" && a.Lang == b.Lang {
+		sim += mmrLangWeight
+	}
+	return sim
+}
+
+func tagSet(tags []string) map[string]bool {
+	set := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		set[domain.NormalizeTag(t)] = true
+	}
+	return set
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMMRRerank_PrefersDifferentTags(t *testing.T) {
+	pool := []domain.RecommendationResult{
+		{Video: domain.Video{ID: uuid.New(), Tags: []string{"go", "docker"}}, Score: 0.9},
+		{Video: domain.Video{ID: uuid.New(), Tags: []string{"go", "docker"}}, Score: 0.85},
+		{Video: domain.Video{ID: uuid.New(), Tags: []string{"cooking"}}, Score: 0.7},
+	}
+
+	relevant := mmrRerank(pool, 2, 0, 0)
+	assert.Equal(t, pool[1].Video.ID, relevant[1].Video.ID)
+
+	diverse := mmrRerank(pool, 2, 0.5, 0)
+	assert.Equal(t, pool[0].Video.ID, diverse[0].Video.ID)
+	assert.Equal(t, pool[2].Video.ID, diverse[1].Video.ID)
+}
+
+func TestMMRRerank_AuthorRunCap(t *testing.T) {
+	author, other := uuid.New(), uuid.New()
+	pool := []domain.RecommendationResult{
+		{Video: domain.Video{ID: uuid.New(), AuthorID: &author}, Score: 0.9},
+		{Video: domain.Video{ID: uuid.New(), AuthorID: &author}, Score: 0.8},
+		{Video: domain.Video{ID: uuid.New(), AuthorID: &author}, Score: 0.7},
+		{Video: domain.Video{ID: uuid.New(), AuthorID: &other}, Score: 0.1},
+	}
+
+	results := mmrRerank(pool, 4, 0, 2)
+	assert.Equal(t, pool[3].Video.ID, results[2].Video.ID)
+	assert.Equal(t, pool[2].Video.ID, results[3].Video.ID) // других кандидатов нет — допускаем
+}
+
+func TestVideoSimilarity(t *testing.T) {
+	author := uuid.New()
+	a := domain.Video{Tags: []string{"Go", "docker"}, AuthorID: &author, Lang: "en"}
+	b := domain.Video{Tags: []string{"go", "k8s"}, AuthorID: &author, Lang: "en"}
+
+	assert.InDelta(t, mmrTagWeight/3+mmrAuthorWeight+mmrLangWeight, videoSimilarity(a, b), 1e-9)
+	assert.InDelta(t, 1.0, videoSimilarity(a, a), 1e-9)
+	assert.Zero(t, videoSimilarity(domain.Video{}, domain.Video{}))
+}
@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+)
+
+// CandidateGenerator источник кандидатов для конвейера рекомендаций.
+// Генераторы регистрируются по имени и включаются в выдачу через config.RecSource,
+// поэтому новый источник не требует изменений в оркестрации.
+type CandidateGenerator interface {
+	Name() string
+	Reason() domain.RecommendationReason
+	// Generate возвращает до limit кандидатов в порядке убывания релевантности.
+	// Score кандидатов пересчитывается конвейером, Explanation дополняется.
+	Generate(ctx context.Context, req *RecRequest, limit int) ([]domain.RecommendationResult, error)
+}
+
+// RecRequest контекст запроса рекомендаций, общий для всех стадий конвейера
+type RecRequest struct {
+	UserID    uuid.UUID // uuid.Nil для гостя
+	SessionID string
+	Limit     int
+
+	Diversity float64 // вес разнообразия в MMR (0 — без переранжирования)
+
+	Seen     map[uuid.UUID]bool      // недавно досмотренные и показанные видео
+	Followed map[uuid.UUID]bool      // авторы из подписок
+	Feedback domain.NegativeFeedback // скрытые видео и нелюбимые теги
+}
+
+// generatorFunc адаптер функции к CandidateGenerator
+type generatorFunc struct {
+	name   string
+	reason domain.RecommendationReason
+	fn     func(ctx context.Context, req *RecRequest, limit int) ([]domain.RecommendationResult, error)
+}
+
+func (g generatorFunc) Name() string                        { return g.name }
+func (g generatorFunc) Reason() domain.RecommendationReason { return g.reason }
+func (g generatorFunc) Generate(ctx context.Context, req *RecRequest, limit int) ([]domain.RecommendationResult, error) {
+	return g.fn(ctx, req, limit)
+}
+
+const (
+	// positionDecay насколько последний кандидат источника ниже первого (доля от веса источника)
+	positionDecay = 0.1
+	// mmrPoolFactor во сколько раз пул кандидатов для MMR больше выдачи
+	mmrPoolFactor = 2
+)
+
+// candidate кандидат в выдачу с источником и итоговым score
+type candidate struct {
+	result domain.RecommendationResult
+	source int // индекс источника в конфигурации
+}
+
+// recPipeline конвейер рекомендаций: генерация → фильтры → скоринг → смешивание
+type recPipeline struct {
+	mu           sync.RWMutex
+	generators   map[string]CandidateGenerator
+	timeout      time.Duration
+	maxAuthorRun int
+}
+
+func newRecPipeline(timeout time.Duration, maxAuthorRun int) *recPipeline {
+	return &recPipeline{generators: map[string]CandidateGenerator{}, timeout: timeout, maxAuthorRun: maxAuthorRun}
+}
+
+// register добавляет генератор; генератор с тем же именем заменяется
+func (p *recPipeline) register(g CandidateGenerator) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.generators[g.Name()] = g
+}
+
+// run собирает выдачу из источников sources
+func (p *recPipeline) run(ctx context.Context, req *RecRequest, sources []config.RecSource) []domain.RecommendationResult {
+	lists := p.generate(ctx, req, sources)
+	for i := range lists {
+		lists[i] = filterCandidates(req, lists[i])
+		scoreCandidates(req, sources[i], lists[i])
+	}
+	if req.Diversity <= 0 && p.maxAuthorRun <= 0 {
+		return blend(sources, lists, req.Limit)
+	}
+	// Смешиваем с запасом, чтобы MMR было из чего выбирать
+	pool := blend(sources, lists, req.Limit*mmrPoolFactor)
+	return mmrRerank(pool, req.Limit, req.Diversity, p.maxAuthorRun)
+}
+
+// generate параллельно опрашивает источники, каждый со своим таймаутом.
+// Упавший или не успевший источник просто не даёт кандидатов.
+func (p *recPipeline) generate(ctx context.Context, req *RecRequest, sources []config.RecSource) [][]candidate {
+	lists := make([][]candidate, len(sources))
+
+	var wg sync.WaitGroup
+	for i, src := range sources {
+		p.mu.RLock()
+		g, ok := p.generators[src.Name]
+		p.mu.RUnlock()
+		if !ok {
+			slog.Warn("unknown recommendation source", "source", src.Name)
+			continue
+		}
+
+		timeout := src.Timeout
+		if timeout <= 0 {
+			timeout = p.timeout
+		}
+		fetch := fetchLimit(sourceQuota(src, req.Limit*mmrPoolFactor), req.Seen)
+		if fetch == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, name string, g CandidateGenerator) {
+			defer wg.Done()
+
+			sctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			started := time.Now()
+			found, err := g.Generate(sctx, req, fetch)
+			if err != nil {
+				slog.Warn("recommendation source failed", "source", name, "err", err,
+					"took", time.Since(started))
+				return
+			}
+
+			list := make([]candidate, 0, len(found))
+			for _, r := range found {
+				r.Reason = g.Reason()
+				r.Explanation.Source = name
+				list = append(list, candidate{result: r, source: i})
+			}
+			lists[i] = list
+		}(i, src.Name, g)
+	}
+	wg.Wait()
+
+	return lists
+}
+
+// filterCandidates применяет бизнес-правила: без просмотренных, скрытых и повторов внутри источника
+func filterCandidates(req *RecRequest, list []candidate) []candidate {
+	filtered := list[:0]
+	dup := make(map[uuid.UUID]bool, len(list))
+	for _, c := range list {
+		id := c.result.Video.ID
+		if req.Seen[id] || req.Feedback.IsHidden(id) || dup[id] {
+			continue
+		}
+		dup[id] = true
+		filtered = append(filtered, c)
+	}
+	return filtered
+}
+
+// scoreCandidates считает единый score: вес источника с затуханием по позиции,
+// бонус за авторов из подписок и штраф за нелюбимые теги. Слагаемые сохраняются в объяснении.
+// Выборка бандита в score не входит: она уже определила, какие видео попали в слот и в каком
+// порядке (через затухание по позиции), а смешивание с другими источниками идёт по общим правилам.
+func scoreCandidates(req *RecRequest, src config.RecSource, list []candidate) {
+	for rank := range list {
+		c := &list[rank]
+		b := &domain.ScoreBreakdown{
+			SourceWeight:  src.Weight,
+			PositionDecay: 1 - positionDecay*float64(rank)/float64(len(list)),
+		}
+		if c.result.Reason == domain.ReasonExploration {
+			b.BanditSample = c.result.Score
+		}
+		score := b.SourceWeight * b.PositionDecay
+		if a := c.result.Video.AuthorID; a != nil && req.Followed[*a] {
+			boosted := math.Min(score+followedAuthorBoost, 1)
+			b.FollowedBoost = boosted - score
+			score = boosted
+			c.result.Explanation.FollowedAuthor = a
+		}
+		b.TagPenalty = req.Feedback.Penalty(c.result.Video.Tags)
+		score -= b.TagPenalty
+		b.Final = score
+
+		c.result.Score = score
+		c.result.Explanation.Scores = b
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].result.Score > list[j].result.Score })
+}
+
+// blend сначала берёт лучших кандидатов каждого источника в пределах его квоты,
+// затем добирает недостающее лучшими оставшимися кандидатами любых источников.
+// Итог упорядочен по score; видео, пришедшее из нескольких источников, попадает один раз.
+func blend(sources []config.RecSource, lists [][]candidate, limit int) []domain.RecommendationResult {
+	taken := make(map[uuid.UUID]bool, limit)
+	results := make([]domain.RecommendationResult, 0, limit)
+
+	var rest []candidate
+	for i, list := range lists {
+		quota := sourceQuota(sources[i], limit)
+		for _, c := range list {
+			if taken[c.result.Video.ID] {
+				continue
+			}
+			if quota == 0 || len(results) == limit {
+				rest = append(rest, c)
+				continue
+			}
+			taken[c.result.Video.ID] = true
+			results = append(results, c.result)
+			quota--
+		}
+	}
+
+	sort.SliceStable(rest, func(i, j int) bool { return rest[i].result.Score > rest[j].result.Score })
+	for _, c := range rest {
+		if len(results) == limit {
+			break
+		}
+		if taken[c.result.Video.ID] {
+			continue
+		}
+		taken[c.result.Video.ID] = true
+		results = append(results, c.result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results
+}
+
+// sourceQuota число мест в выдаче, гарантированных источнику
+func sourceQuota(src config.RecSource, limit int) int {
+	return int(math.Ceil(src.Quota * float64(limit)))
+}
+
+// fetchLimit сколько кандидатов запрашивать у источника: квота в пуле MMR
+// плюс запас на просмотренные видео
+func fetchLimit(quota int, seen map[uuid.UUID]bool) int {
+	return seenMargin(quota, seen)
+}
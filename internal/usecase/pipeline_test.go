@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func testVideos(n int) []domain.Video {
+	videos := make([]domain.Video, n)
+	for i := range videos {
+		videos[i] = domain.Video{ID: uuid.New()}
+	}
+	return videos
+}
+
+func staticGenerator(name string, videos []domain.Video, delay time.Duration, err error) CandidateGenerator {
+	return generatorFunc{name, domain.RecommendationReason(name), func(ctx context.Context, _ *RecRequest, limit int) ([]domain.RecommendationResult, error) {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(videos) > limit {
+			videos = videos[:limit]
+		}
+		return asResults(videos), nil
+	}}
+}
+
+func TestPipeline_QuotasAndBackfill(t *testing.T) {
+	a, b := testVideos(10), testVideos(10)
+	p := newRecPipeline(time.Second, 0)
+	p.register(staticGenerator("a", a, 0, nil))
+	p.register(staticGenerator("b", b[:1], 0, nil)) // недобор квоты
+
+	sources := []config.RecSource{
+		{Name: "a", Quota: 0.5, Weight: 0.5},
+		{Name: "b", Quota: 0.5, Weight: 0.9},
+	}
+	results := p.run(context.Background(), &RecRequest{Limit: 6}, sources)
+
+	assert.Len(t, results, 6)
+	assert.Equal(t, b[0].ID, results[0].Video.ID) // выше вес источника
+	assert.Equal(t, domain.RecommendationReason("b"), results[0].Reason)
+	assert.Equal(t, "b", results[0].Explanation.Source)
+	for _, r := range results[1:] {
+		assert.Equal(t, domain.RecommendationReason("a"), r.Reason) // недобор "b" добран из "a"
+	}
+	for i := 1; i < len(results); i++ {
+		assert.GreaterOrEqual(t, results[i-1].Score, results[i].Score)
+	}
+}
+
+func TestPipeline_FiltersAndDedup(t *testing.T) {
+	videos := testVideos(4)
+	author := uuid.New()
+	videos[3].AuthorID = &author
+
+	p := newRecPipeline(time.Second, 0)
+	p.register(staticGenerator("a", videos, 0, nil))
+	p.register(staticGenerator("b", videos, 0, nil))
+
+	req := &RecRequest{
+		Limit:    10,
+		Seen:     map[uuid.UUID]bool{videos[0].ID: true},
+		Feedback: domain.NegativeFeedback{Hidden: map[uuid.UUID]bool{videos[1].ID: true}},
+		Followed: map[uuid.UUID]bool{author: true},
+	}
+	results := p.run(context.Background(), req, []config.RecSource{
+		{Name: "a", Quota: 0.5, Weight: 0.5},
+		{Name: "b", Quota: 0.5, Weight: 0.5},
+	})
+
+	assert.Len(t, results, 2)
+	assert.Equal(t, videos[3].ID, results[0].Video.ID) // бонус за подписку
+	assert.Equal(t, &author, results[0].Explanation.FollowedAuthor)
+	assert.InDelta(t, followedAuthorBoost, results[0].Explanation.Scores.FollowedBoost, 1e-9)
+	assert.InDelta(t, results[0].Score, results[0].Explanation.Scores.Final, 1e-9)
+	assert.Equal(t, videos[2].ID, results[1].Video.ID)
+	assert.Nil(t, results[1].Explanation.FollowedAuthor)
+}
+
+func TestPipeline_SlowAndFailingSourcesSkipped(t *testing.T) {
+	ok := testVideos(3)
+	p := newRecPipeline(20*time.Millisecond, 0)
+	p.register(staticGenerator("ok", ok, 0, nil))
+	p.register(staticGenerator("slow", testVideos(3), time.Second, nil))
+	p.register(staticGenerator("broken", nil, 0, errors.New("boom")))
+
+	started := time.Now()
+	results := p.run(context.Background(), &RecRequest{Limit: 3}, []config.RecSource{
+		{Name: "slow", Quota: 0.4, Weight: 1},
+		{Name: "broken", Quota: 0.3, Weight: 1},
+		{Name: "ok", Quota: 0.5, Weight: 0.5},
+		{Name: "unknown", Quota: 0.1, Weight: 1},
+	})
+
+	assert.Less(t, time.Since(started), 500*time.Millisecond)
+	assert.Len(t, results, 3)
+	for _, r := range results {
+		assert.Equal(t, domain.RecommendationReason("ok"), r.Reason)
+	}
+}
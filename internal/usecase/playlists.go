@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+)
+
+// PlaylistsUCInterface интерфейс для тестирования
+type PlaylistsUCInterface interface {
+	Create(ctx context.Context, ownerID uuid.UUID, title string, visibility domain.PlaylistVisibility) (domain.Playlist, error)
+	Get(ctx context.Context, viewerID, playlistID uuid.UUID) (domain.PlaylistWithItems, error)
+	Mine(ctx context.Context, ownerID uuid.UUID) ([]domain.Playlist, error)
+	AuthorPlaylists(ctx context.Context, authorID uuid.UUID) ([]domain.Playlist, error)
+	Update(ctx context.Context, ownerID, playlistID uuid.UUID, upd domain.PlaylistUpdate) (domain.Playlist, error)
+	Delete(ctx context.Context, ownerID, playlistID uuid.UUID) error
+	AddItem(ctx context.Context, ownerID, playlistID, videoID uuid.UUID, position *int) error
+	MoveItem(ctx context.Context, ownerID, playlistID, videoID uuid.UUID, position int) error
+	RemoveItem(ctx context.Context, ownerID, playlistID, videoID uuid.UUID) error
+	WatchLater(ctx context.Context, ownerID uuid.UUID) (domain.PlaylistWithItems, error)
+	AddToWatchLater(ctx context.Context, ownerID, videoID uuid.UUID) error
+	RemoveFromWatchLater(ctx context.Context, ownerID, videoID uuid.UUID) error
+}
+
+type PlaylistsUC struct {
+	store repo.Store
+}
+
+func NewPlaylistsUC(store repo.Store) *PlaylistsUC {
+	return &PlaylistsUC{store: store}
+}
+
+// Create создаёт пустой плейлист; видимость по умолчанию — private
+func (uc *PlaylistsUC) Create(ctx context.Context, ownerID uuid.UUID, title string, visibility domain.PlaylistVisibility) (domain.Playlist, error) {
+	title, err := domain.NormalizePlaylistTitle(title)
+	if err != nil {
+		return domain.Playlist{}, err
+	}
+	if visibility == "" {
+		visibility = domain.PlaylistPrivate
+	}
+	if !visibility.Valid() {
+		return domain.Playlist{}, domain.ErrInvalidPlaylist
+	}
+
+	return uc.store.CreatePlaylist(ctx, domain.Playlist{
+		ID:         uuid.New(),
+		OwnerID:    ownerID,
+		Title:      title,
+		Visibility: visibility,
+		Kind:       domain.PlaylistKindUser,
+	})
+}
+
+// Get возвращает плейлист с видео. Чужой приватный плейлист неотличим от несуществующего.
+func (uc *PlaylistsUC) Get(ctx context.Context, viewerID, playlistID uuid.UUID) (domain.PlaylistWithItems, error) {
+	p, err := uc.store.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		return domain.PlaylistWithItems{}, err
+	}
+	if !p.CanView(viewerID) {
+		return domain.PlaylistWithItems{}, domain.ErrPlaylistNotFound
+	}
+	return uc.withItems(ctx, p)
+}
+
+// Mine возвращает все плейлисты пользователя, первым — "смотреть позже"
+func (uc *PlaylistsUC) Mine(ctx context.Context, ownerID uuid.UUID) ([]domain.Playlist, error) {
+	if _, err := uc.store.GetOrCreateWatchLater(ctx, ownerID); err != nil {
+		return nil, err
+	}
+	return uc.list(ctx, ownerID, false)
+}
+
+// AuthorPlaylists возвращает публичные плейлисты автора
+func (uc *PlaylistsUC) AuthorPlaylists(ctx context.Context, authorID uuid.UUID) ([]domain.Playlist, error) {
+	return uc.list(ctx, authorID, true)
+}
+
+// Update переименовывает плейлист или меняет его видимость (только владелец)
+func (uc *PlaylistsUC) Update(ctx context.Context, ownerID, playlistID uuid.UUID, upd domain.PlaylistUpdate) (domain.Playlist, error) {
+	if upd.Title != nil {
+		title, err := domain.NormalizePlaylistTitle(*upd.Title)
+		if err != nil {
+			return domain.Playlist{}, err
+		}
+		upd.Title = &title
+	}
+	if upd.Visibility != nil && !upd.Visibility.Valid() {
+		return domain.Playlist{}, domain.ErrInvalidPlaylist
+	}
+
+	p, err := uc.owned(ctx, ownerID, playlistID)
+	if err != nil {
+		return domain.Playlist{}, err
+	}
+	if p.Kind == domain.PlaylistKindWatchLater {
+		return domain.Playlist{}, domain.ErrWatchLaterReadOnly
+	}
+	if upd.Title == nil && upd.Visibility == nil {
+		return p, nil
+	}
+	return uc.store.UpdatePlaylist(ctx, playlistID, upd)
+}
+
+// Delete удаляет плейлист вместе с его видео (только владелец)
+func (uc *PlaylistsUC) Delete(ctx context.Context, ownerID, playlistID uuid.UUID) error {
+	p, err := uc.owned(ctx, ownerID, playlistID)
+	if err != nil {
+		return err
+	}
+	if p.Kind == domain.PlaylistKindWatchLater {
+		return domain.ErrWatchLaterReadOnly
+	}
+	return uc.store.DeletePlaylist(ctx, playlistID)
+}
+
+// AddItem вставляет видео на позицию position (nil или за концом списка — в конец)
+func (uc *PlaylistsUC) AddItem(ctx context.Context, ownerID, playlistID, videoID uuid.UUID, position *int) error {
+	if _, err := uc.owned(ctx, ownerID, playlistID); err != nil {
+		return err
+	}
+	return uc.insert(ctx, playlistID, videoID, position)
+}
+
+// MoveItem переносит видео на позицию position; позиция за концом списка означает конец
+func (uc *PlaylistsUC) MoveItem(ctx context.Context, ownerID, playlistID, videoID uuid.UUID, position int) error {
+	if _, err := uc.owned(ctx, ownerID, playlistID); err != nil {
+		return err
+	}
+
+	tx, err := uc.store.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	count, err := uc.store.LockPlaylist(ctx, tx, playlistID)
+	if err != nil {
+		return err
+	}
+	if err := uc.store.MovePlaylistItem(ctx, tx, playlistID, videoID, clampPosition(position, count-1)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// RemoveItem убирает видео из плейлиста
+func (uc *PlaylistsUC) RemoveItem(ctx context.Context, ownerID, playlistID, videoID uuid.UUID) error {
+	if _, err := uc.owned(ctx, ownerID, playlistID); err != nil {
+		return err
+	}
+	return uc.remove(ctx, playlistID, videoID)
+}
+
+// WatchLater возвращает список "смотреть позже" пользователя
+func (uc *PlaylistsUC) WatchLater(ctx context.Context, ownerID uuid.UUID) (domain.PlaylistWithItems, error) {
+	p, err := uc.store.GetOrCreateWatchLater(ctx, ownerID)
+	if err != nil {
+		return domain.PlaylistWithItems{}, err
+	}
+	return uc.withItems(ctx, p)
+}
+
+// AddToWatchLater добавляет видео в конец списка "смотреть позже"
+func (uc *PlaylistsUC) AddToWatchLater(ctx context.Context, ownerID, videoID uuid.UUID) error {
+	p, err := uc.store.GetOrCreateWatchLater(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	return uc.insert(ctx, p.ID, videoID, nil)
+}
+
+func (uc *PlaylistsUC) RemoveFromWatchLater(ctx context.Context, ownerID, videoID uuid.UUID) error {
+	p, err := uc.store.GetOrCreateWatchLater(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	return uc.remove(ctx, p.ID, videoID)
+}
+
+// owned возвращает плейлист, если его владелец ownerID.
+// Чужой плейлист, который пользователь может видеть, даёт ErrPlaylistForbidden, невидимый — ErrPlaylistNotFound.
+func (uc *PlaylistsUC) owned(ctx context.Context, ownerID, playlistID uuid.UUID) (domain.Playlist, error) {
+	p, err := uc.store.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		return domain.Playlist{}, err
+	}
+	if p.OwnerID != ownerID {
+		if p.CanView(ownerID) {
+			return domain.Playlist{}, domain.ErrPlaylistForbidden
+		}
+		return domain.Playlist{}, domain.ErrPlaylistNotFound
+	}
+	return p, nil
+}
+
+// insert вставляет видео под блокировкой плейлиста, соблюдая лимит domain.MaxPlaylistItems
+func (uc *PlaylistsUC) insert(ctx context.Context, playlistID, videoID uuid.UUID, position *int) error {
+	exists, err := uc.store.VideoExists(ctx, videoID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrVideoNotFound
+	}
+
+	tx, err := uc.store.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	count, err := uc.store.LockPlaylist(ctx, tx, playlistID)
+	if err != nil {
+		return err
+	}
+	if count >= domain.MaxPlaylistItems {
+		return domain.ErrPlaylistFull
+	}
+
+	pos := count
+	if position != nil {
+		pos = clampPosition(*position, count)
+	}
+	if err := uc.store.InsertPlaylistItem(ctx, tx, playlistID, videoID, pos); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (uc *PlaylistsUC) remove(ctx context.Context, playlistID, videoID uuid.UUID) error {
+	tx, err := uc.store.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := uc.store.LockPlaylist(ctx, tx, playlistID); err != nil {
+		return err
+	}
+	if err := uc.store.DeletePlaylistItem(ctx, tx, playlistID, videoID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (uc *PlaylistsUC) withItems(ctx context.Context, p domain.Playlist) (domain.PlaylistWithItems, error) {
+	items, err := uc.store.ListPlaylistItems(ctx, p.ID)
+	if err != nil {
+		return domain.PlaylistWithItems{}, err
+	}
+	if items == nil {
+		items = []domain.PlaylistItem{}
+	}
+	p.ItemsCount = len(items)
+	return domain.PlaylistWithItems{Playlist: p, Items: items}, nil
+}
+
+func (uc *PlaylistsUC) list(ctx context.Context, ownerID uuid.UUID, onlyPublic bool) ([]domain.Playlist, error) {
+	playlists, err := uc.store.ListUserPlaylists(ctx, ownerID, onlyPublic)
+	if err != nil {
+		return nil, err
+	}
+	if playlists == nil {
+		playlists = []domain.Playlist{}
+	}
+	return playlists, nil
+}
+
+// clampPosition ограничивает позицию диапазоном [0, max]
+func clampPosition(position, max int) int {
+	if position > max {
+		position = max
+	}
+	if position < 0 {
+		position = 0
+	}
+	return position
+}
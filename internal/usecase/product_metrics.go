@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+)
+
+// RefreshProductMetrics пересчитывает агрегаты продуктовых метрик; вызывается фоновой задачей
+// каждые every на всех репликах. Пересчёт пропускается, если его уже ведёт другая реплика
+// или он был меньше половины интервала назад.
+func (uc *StatsUC) RefreshProductMetrics(ctx context.Context, lookback, every time.Duration) error {
+	since, ok, err := uc.store.RefreshProductMetrics(ctx, lookback, every/2)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		slog.Debug("product metrics refresh skipped")
+		return nil
+	}
+	slog.Debug("product metrics refreshed", "since", since)
+	return nil
+}
+
+// ActiveUsers DAU/WAU/MAU на каждый день [from, to]
+func (uc *StatsUC) ActiveUsers(ctx context.Context, from, to time.Time) ([]domain.ActiveUsers, error) {
+	from, to, err := productStatsPeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+	res, err := uc.store.StatsActiveUsers(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for i := range res {
+		if res[i].MAU > 0 {
+			res[i].Stickiness = float64(res[i].DAU) / float64(res[i].MAU)
+		}
+	}
+	return res, nil
+}
+
+// Cohorts удержание недельных когорт регистрации: последние weeks недель, включая текущую
+func (uc *StatsUC) Cohorts(ctx context.Context, weeks int) ([]domain.Cohort, error) {
+	if weeks <= 0 {
+		weeks = domain.DefaultCohortWeeks
+	}
+	weeks = min(weeks, domain.MaxCohortWeeks)
+
+	current := domain.StartOfWeek(time.Now())
+	from := current.AddDate(0, 0, -7*(weeks-1))
+	sizes, active, err := uc.store.StatsCohorts(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	return buildCohorts(from, current, sizes, active), nil
+}
+
+// Funnel воронка поиска за дни [from, to]
+func (uc *StatsUC) Funnel(ctx context.Context, from, to time.Time) (domain.Funnel, error) {
+	from, to, err := productStatsPeriod(from, to)
+	if err != nil {
+		return domain.Funnel{}, err
+	}
+	counts, err := uc.store.StatsFunnel(ctx, from, to)
+	if err != nil {
+		return domain.Funnel{}, err
+	}
+	return domain.Funnel{From: from, To: to, Steps: funnelSteps(counts)}, nil
+}
+
+// productStatsPeriod приводит период к дням UTC и проверяет длину
+func productStatsPeriod(from, to time.Time) (time.Time, time.Time, error) {
+	from = from.UTC().Truncate(24 * time.Hour)
+	to = to.UTC().Truncate(24 * time.Hour)
+	if to.Before(from) || to.Sub(from) > domain.MaxProductStatsDays*24*time.Hour {
+		return time.Time{}, time.Time{}, domain.ErrInvalidStatsPeriod
+	}
+	return from, to, nil
+}
+
+// buildCohorts матрица удержания: по когорте на каждую неделю [from, current], даже пустую
+func buildCohorts(from, current time.Time, sizes, active []domain.CohortCell) []domain.Cohort {
+	var res []domain.Cohort
+	index := map[int64]int{}
+	for w := from; !w.After(current); w = w.AddDate(0, 0, 7) {
+		n := int(current.Sub(w).Hours()/24/7) + 1
+		index[w.Unix()] = len(res)
+		res = append(res, domain.Cohort{Week: w, Active: make([]int64, n), Retention: make([]float64, n)})
+	}
+
+	for _, s := range sizes {
+		if i, ok := index[s.Cohort.Unix()]; ok {
+			res[i].Users = s.Users
+		}
+	}
+	for _, a := range active {
+		i, ok := index[a.Cohort.Unix()]
+		if !ok {
+			continue
+		}
+		offset := int(a.Week.Sub(a.Cohort).Hours() / 24 / 7)
+		if offset >= 0 && offset < len(res[i].Active) {
+			res[i].Active[offset] = a.Users
+		}
+	}
+
+	for i := range res {
+		if res[i].Users == 0 {
+			continue
+		}
+		for n, a := range res[i].Active {
+			res[i].Retention[n] = float64(a) / float64(res[i].Users)
+		}
+	}
+	return res
+}
+
+// funnelSteps шаги воронки с конверсией от предыдущего и от первого шага
+func funnelSteps(c domain.FunnelCounts) []domain.FunnelStep {
+	steps := []domain.FunnelStep{
+		{Step: domain.FunnelSearch, Sessions: c.Searched},
+		{Step: domain.FunnelClick, Sessions: c.Clicked},
+		{Step: domain.FunnelViewStart, Sessions: c.Viewed},
+		{Step: domain.FunnelViewComplete, Sessions: c.Completed},
+	}
+	for i := range steps {
+		if c.Searched > 0 {
+			steps[i].Overall = float64(steps[i].Sessions) / float64(c.Searched)
+		}
+		if i == 0 {
+			steps[i].Conversion = steps[i].Overall
+			continue
+		}
+		if prev := steps[i-1].Sessions; prev > 0 {
+			steps[i].Conversion = float64(steps[i].Sessions) / float64(prev)
+		}
+	}
+	return steps
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartOfWeek(t *testing.T) {
+	// 2024-03-06 — среда, неделя начинается в понедельник 2024-03-04
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), domain.StartOfWeek(time.Date(2024, 3, 6, 15, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), domain.StartOfWeek(time.Date(2024, 3, 10, 23, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), domain.StartOfWeek(time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestBuildCohorts(t *testing.T) {
+	w := func(n int) time.Time { return time.Date(2024, 3, 4+7*n, 0, 0, 0, 0, time.UTC) }
+	sizes := []domain.CohortCell{{Cohort: w(0), Week: w(0), Users: 10}, {Cohort: w(2), Week: w(2), Users: 4}}
+	active := []domain.CohortCell{
+		{Cohort: w(0), Week: w(0), Users: 10},
+		{Cohort: w(0), Week: w(2), Users: 3},
+		{Cohort: w(2), Week: w(2), Users: 2},
+	}
+
+	res := buildCohorts(w(0), w(2), sizes, active)
+
+	if !assert.Len(t, res, 3) {
+		return
+	}
+	// Треугольная матрица: у первой когорты три недели, у последней — одна
+	assert.Len(t, res[0].Active, 3)
+	assert.Equal(t, []int64{10, 0, 3}, res[0].Active)
+	assert.InDelta(t, 0.3, res[0].Retention[2], 1e-9)
+	// Неделя без регистраций присутствует с нулями
+	assert.Equal(t, w(1), res[1].Week)
+	assert.Zero(t, res[1].Users)
+	assert.Equal(t, []float64{0.5}, res[2].Retention)
+}
+
+func TestFunnelSteps(t *testing.T) {
+	steps := funnelSteps(domain.FunnelCounts{Searched: 100, Clicked: 40, Viewed: 30, Completed: 6})
+
+	if !assert.Len(t, steps, 4) {
+		return
+	}
+	assert.Equal(t, domain.FunnelSearch, steps[0].Step)
+	assert.InDelta(t, 1.0, steps[0].Conversion, 1e-9)
+	assert.InDelta(t, 0.4, steps[1].Conversion, 1e-9)
+	assert.InDelta(t, 0.75, steps[2].Conversion, 1e-9)
+	assert.InDelta(t, 0.2, steps[3].Conversion, 1e-9)
+	assert.InDelta(t, 0.06, steps[3].Overall, 1e-9)
+
+	// Пустой период: без деления на ноль
+	empty := funnelSteps(domain.FunnelCounts{})
+	assert.Zero(t, empty[1].Conversion)
+}
@@ -2,24 +2,49 @@ package usecase
 
 import (
 	"context"
-	"math/rand"
+	"log/slog"
+	"strconv"
 	"time"
 
+	"github.com/arasvet/microtube/internal/config"
 	"github.com/arasvet/microtube/internal/domain"
 	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
 )
 
 // RecommendationsUCInterface интерфейс для тестирования
 type RecommendationsUCInterface interface {
 	GetRecommendations(ctx context.Context, params domain.RecommendationParams) ([]domain.RecommendationResult, error)
+	Related(ctx context.Context, videoID uuid.UUID, limit int) ([]domain.RecommendationResult, error)
 }
 
+const (
+	// followedAuthorBoost прибавка к score для видео авторов из подписок
+	followedAuthorBoost = 0.15
+	// playlistHintCount сколько следующих видео плейлиста подмешивать в начало выдачи
+	playlistHintCount = 3
+)
+
 type RecommendationsUC struct {
-	store repo.Store
+	store    repo.Store
+	seen     repo.SeenStore
+	exp      Experimenter
+	cfg      config.Config
+	factors  *factorIndex
+	pipeline *recPipeline
 }
 
-func NewRecommendationsUC(store repo.Store) *RecommendationsUC {
-	return &RecommendationsUC{store: store}
+func NewRecommendationsUC(store repo.Store, seen repo.SeenStore, exp Experimenter, cfg config.Config) *RecommendationsUC {
+	uc := &RecommendationsUC{
+		store:    store,
+		seen:     seen,
+		exp:      exp,
+		cfg:      cfg,
+		factors:  &factorIndex{},
+		pipeline: newRecPipeline(cfg.RecsSourceTimeout, cfg.RecsMaxAuthorRun),
+	}
+	uc.registerDefaultGenerators()
+	return uc
 }
 
 // GetRecommendations возвращает персональные или холодные рекомендации
@@ -40,187 +65,224 @@ func (uc *RecommendationsUC) GetRecommendations(ctx context.Context, params doma
 	// Получаем рекомендации в зависимости от типа
 	var results []domain.RecommendationResult
 	var err error
+	opts := uc.options(ctx, params)
 
 	switch recType {
 	case domain.RecommendationTypePersonal:
-		results, err = uc.getPersonalRecommendations(ctx, *params.UserID, params.Limit)
+		results, err = uc.getPersonalRecommendations(ctx, params, opts)
 	case domain.RecommendationTypeCold:
-		results, err = uc.getColdRecommendations(ctx, *params.SessionID, params.Limit)
+		results, err = uc.getColdRecommendations(ctx, *params.SessionID, opts)
 	default:
-		results, err = uc.getMixedRecommendations(ctx, params, params.Limit)
+		results, err = uc.getMixedRecommendations(ctx, params, opts)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	if params.PlaylistID != nil {
+		results = uc.withPlaylistHints(ctx, params, results)
+	}
+	uc.recordBanditImpressions(results)
+
+	// Слагаемые score отдаём только в режиме отладки. Совпавшие теги, просмотренное видео
+	// и автор из подписок раскрывают вкусы пользователя: по чужому user_id их видит только админ.
+	private := params.UserID != nil && !params.IsViewer() && !params.Admin
+	for i := range results {
+		e := &results[i].Explanation
+		if !params.Debug {
+			e.Scores = nil
+		}
+		if private {
+			e.MatchedTags, e.SimilarTo, e.FollowedAuthor = nil, nil, nil
+		}
+	}
+
 	return results, nil
 }
 
-// getPersonalRecommendations возвращает персональные рекомендации для авторизованного пользователя
-func (uc *RecommendationsUC) getPersonalRecommendations(ctx context.Context, userID string, limit int) ([]domain.RecommendationResult, error) {
-	var results []domain.RecommendationResult
+// withPlaylistHints ставит в начало выдачи следующие видео проигрываемого плейлиста (автоплей).
+// Недоступный или несуществующий плейлист просто не даёт подсказок.
+func (uc *RecommendationsUC) withPlaylistHints(ctx context.Context, params domain.RecommendationParams, results []domain.RecommendationResult) []domain.RecommendationResult {
+	playlist, err := uc.store.GetPlaylist(ctx, *params.PlaylistID)
+	if err != nil || !playlist.CanView(params.ViewerID) {
+		return results
+	}
+	items, err := uc.store.ListPlaylistItems(ctx, playlist.ID)
+	if err != nil {
+		return results
+	}
 
-	// 1. Получаем топ теги пользователя (40% рекомендаций)
-	userTags, err := uc.store.GetUserTopTags(ctx, userID)
-	if err == nil && len(userTags) > 0 {
-		tagLimit := limit * 4 / 10 // 40%
-		videos, err := uc.store.GetVideosByTags(ctx, userTags, tagLimit)
-		if err == nil {
-			for _, video := range videos {
-				results = append(results, domain.RecommendationResult{
-					Video:  video,
-					Reason: domain.ReasonUserTags,
-					Score:  0.9, // высокий score для персональных рекомендаций
-				})
+	start := 0
+	if params.VideoID != nil {
+		for i, item := range items {
+			if item.Video.ID == *params.VideoID {
+				start = i + 1
+				break
 			}
 		}
 	}
 
-	// 2. Добавляем популярные видео (30% рекомендаций)
-	popularLimit := limit * 3 / 10 // 30%
-	if len(results) < limit {
-		popularVideos, err := uc.store.GetPopularVideos(ctx, popularLimit)
-		if err == nil {
-			for _, video := range popularVideos {
-				// Проверяем, что видео еще не добавлено
-				if !uc.videoExists(results, video.ID.String()) {
-					results = append(results, domain.RecommendationResult{
-						Video:  video,
-						Reason: domain.ReasonPopular,
-						Score:  0.7,
-					})
-				}
-			}
+	hints := make([]domain.RecommendationResult, 0, params.Limit)
+	for _, item := range items[start:] {
+		if len(hints) == playlistHintCount {
+			break
 		}
+		hints = append(hints, domain.RecommendationResult{
+			Video:       item.Video,
+			Reason:      domain.ReasonPlaylistNext,
+			Score:       1,
+			Explanation: domain.Explanation{Source: "playlist"},
+		})
 	}
-
-	// 3. Добавляем диверсификацию (20% рекомендаций)
-	diversifyLimit := limit * 2 / 10 // 20%
-	if len(results) < limit {
-		excludeIDs := uc.getVideoIDs(results)
-		diversifiedVideos, err := uc.store.GetDiversifiedVideos(ctx, excludeIDs, diversifyLimit)
-		if err == nil {
-			for _, video := range diversifiedVideos {
-				if !uc.videoExists(results, video.ID.String()) {
-					results = append(results, domain.RecommendationResult{
-						Video:  video,
-						Reason: domain.ReasonDiversify,
-						Score:  0.6,
-					})
-				}
-			}
+	for _, r := range results {
+		if params.VideoID != nil && r.Video.ID == *params.VideoID {
+			continue
+		}
+		if !uc.videoExists(hints, r.Video.ID.String()) {
+			hints = append(hints, r)
 		}
 	}
 
-	// 4. Добавляем exploration (10% рекомендаций)
-	explorationLimit := limit * 1 / 10 // 10%
-	if len(results) < limit {
-		excludeIDs := uc.getVideoIDs(results)
-		explorationVideos, err := uc.store.GetDiversifiedVideos(ctx, excludeIDs, explorationLimit)
-		if err == nil {
-			for _, video := range explorationVideos {
-				if !uc.videoExists(results, video.ID.String()) {
-					results = append(results, domain.RecommendationResult{
-						Video:  video,
-						Reason: domain.ReasonExploration,
-						Score:  0.5,
-					})
-				}
-			}
-		}
+	if len(hints) > params.Limit {
+		hints = hints[:params.Limit]
 	}
+	return hints
+}
 
-	// Ограничиваем результат
-	if len(results) > limit {
-		results = results[:limit]
+// getPersonalRecommendations возвращает персональные рекомендации для авторизованного пользователя.
+// Источники и их квоты задаются RecsPersonalSources; видео из seen-set и скрытые пропускаются.
+// Seen-set, подписки и скрытые видео используются, только если user_id совпадает
+// с пользователем из JWT: иначе по ранжированию видно чужие подписки и скрытые видео.
+func (uc *RecommendationsUC) getPersonalRecommendations(ctx context.Context, params domain.RecommendationParams, opts recOptions) ([]domain.RecommendationResult, error) {
+	userID := *params.UserID
+	uid, _ := uuid.Parse(userID)
+	var own uuid.UUID // uuid.Nil — чужой user_id: личные данные не читаем и не пополняем
+	if params.IsViewer() {
+		own = uid
 	}
+	req := &RecRequest{UserID: uid, Limit: opts.Limit, Diversity: opts.Diversity, Seen: uc.seenSet(ctx, own)}
+
+	if own != uuid.Nil {
+		req.Followed = uc.followedAuthors(ctx, userID)
+		if fb, err := uc.store.GetNegativeFeedback(ctx, own); err == nil {
+			req.Feedback = fb
+		}
+	}
+
+	results := uc.pipeline.run(ctx, req, opts.PersonalSources)
+
+	// Запоминаем показ, чтобы не повторять те же видео до истечения cooldown
+	uc.markImpressions(ctx, own, results)
 
 	return results, nil
 }
 
-// getColdRecommendations возвращает холодные рекомендации для гостей
-func (uc *RecommendationsUC) getColdRecommendations(ctx context.Context, sessionID string, limit int) ([]domain.RecommendationResult, error) {
-	var results []domain.RecommendationResult
+// seenSet возвращает seen-set пользователя; при недоступности Redis — пустой
+func (uc *RecommendationsUC) seenSet(ctx context.Context, userID uuid.UUID) map[uuid.UUID]bool {
+	if userID == uuid.Nil {
+		return nil
+	}
+	seen, err := uc.seen.GetSeen(ctx, userID)
+	if err != nil {
+		slog.Warn("seen-set read failed", "err", err, "user_id", userID)
+		return nil
+	}
+	return seen
+}
 
-	// 1. Популярные видео (50% рекомендаций)
-	popularLimit := limit * 5 / 10
-	popularVideos, err := uc.store.GetPopularVideos(ctx, popularLimit)
-	if err == nil {
-		for _, video := range popularVideos {
-			results = append(results, domain.RecommendationResult{
-				Video:  video,
-				Reason: domain.ReasonPopular,
-				Score:  0.8,
-			})
-		}
+// markImpressions добавляет выданные рекомендации в seen-set на SeenImpressionCooldown
+func (uc *RecommendationsUC) markImpressions(ctx context.Context, userID uuid.UUID, results []domain.RecommendationResult) {
+	if userID == uuid.Nil || uc.cfg.SeenImpressionCooldown <= 0 || len(results) == 0 {
+		return
 	}
+	ids := make([]uuid.UUID, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.Video.ID)
+	}
+	until := time.Now().Add(uc.cfg.SeenImpressionCooldown)
+	if err := uc.seen.MarkSeen(ctx, userID, ids, until, uc.cfg.SeenMaxItems); err != nil {
+		slog.Warn("seen-set write failed", "err", err, "user_id", userID)
+	}
+}
 
-	// 2. Попробуем получить теги сессии (30% рекомендаций)
-	if len(results) < limit {
-		sessionTags, err := uc.store.GetSessionTopTags(ctx, sessionID)
-		if err == nil && len(sessionTags) > 0 {
-			tagLimit := limit * 3 / 10
-			videos, err := uc.store.GetVideosByTags(ctx, sessionTags, tagLimit)
-			if err == nil {
-				for _, video := range videos {
-					if !uc.videoExists(results, video.ID.String()) {
-						results = append(results, domain.RecommendationResult{
-							Video:  video,
-							Reason: domain.ReasonUserTags,
-							Score:  0.7,
-						})
-					}
-				}
-			}
-		}
+// recOptions настройки выдачи с учётом запроса и варианта эксперимента
+type recOptions struct {
+	Limit           int
+	Diversity       float64
+	PersonalSources []config.RecSource
+	ColdSources     []config.RecSource
+}
+
+// options собирает настройки выдачи: значения из конфигурации, поверх них — параметры
+// варианта эксперимента, поверх них — явный diversity из запроса
+func (uc *RecommendationsUC) options(ctx context.Context, params domain.RecommendationParams) recOptions {
+	opts := recOptions{
+		Limit:           params.Limit,
+		Diversity:       uc.cfg.RecsDiversity,
+		PersonalSources: uc.cfg.RecsPersonalSources,
+		ColdSources:     uc.cfg.RecsColdSources,
 	}
 
-	// 3. Диверсификация (20% рекомендаций)
-	if len(results) < limit {
-		diversifyLimit := limit - len(results)
-		excludeIDs := uc.getVideoIDs(results)
-		diversifiedVideos, err := uc.store.GetDiversifiedVideos(ctx, excludeIDs, diversifyLimit)
-		if err == nil {
-			for _, video := range diversifiedVideos {
-				if !uc.videoExists(results, video.ID.String()) {
-					results = append(results, domain.RecommendationResult{
-						Video:  video,
-						Reason: domain.ReasonDiversify,
-						Score:  0.6,
-					})
-				}
+	// Вариант назначается по пользователю из JWT, а не по user_id из запроса:
+	// иначе любой клиент выбирает себе группу эксперимента
+	var sessionID string
+	if params.SessionID != nil {
+		sessionID = *params.SessionID
+	}
+	if a, ok := assignVariant(ctx, uc.exp, domain.SurfaceRecommendations, params.ViewerID, sessionID); ok {
+		if v, ok := a.Params[domain.ParamPersonalSources]; ok {
+			if sources, err := config.ParseRecSources(v); err == nil {
+				opts.PersonalSources = sources
 			}
 		}
+		if v, ok := a.Params[domain.ParamColdSources]; ok {
+			if sources, err := config.ParseRecSources(v); err == nil {
+				opts.ColdSources = sources
+			}
+		}
+		if d, err := strconv.ParseFloat(a.Params[domain.ParamDiversity], 64); err == nil {
+			opts.Diversity = d
+		}
 	}
 
-	// Ограничиваем результат
-	if len(results) > limit {
-		results = results[:limit]
+	if params.Diversity != nil {
+		opts.Diversity = *params.Diversity
 	}
+	return opts
+}
 
-	return results, nil
+// followedAuthors возвращает авторов из подписок пользователя для бонуса в скоринге
+func (uc *RecommendationsUC) followedAuthors(ctx context.Context, userID string) map[uuid.UUID]bool {
+	authors, err := uc.store.GetFollowedAuthorIDs(ctx, userID)
+	if err != nil || len(authors) == 0 {
+		return nil
+	}
+	followed := make(map[uuid.UUID]bool, len(authors))
+	for _, id := range authors {
+		followed[id] = true
+	}
+	return followed
 }
 
-// getMixedRecommendations возвращает смешанные рекомендации
-func (uc *RecommendationsUC) getMixedRecommendations(ctx context.Context, params domain.RecommendationParams, limit int) ([]domain.RecommendationResult, error) {
-	// Для смешанных рекомендаций используем комбинацию подходов
-	rand.Seed(time.Now().UnixNano())
+// getColdRecommendations возвращает холодные рекомендации для гостей (источники RecsColdSources)
+func (uc *RecommendationsUC) getColdRecommendations(ctx context.Context, sessionID string, opts recOptions) ([]domain.RecommendationResult, error) {
+	req := &RecRequest{SessionID: sessionID, Limit: opts.Limit, Diversity: opts.Diversity}
+	return uc.pipeline.run(ctx, req, opts.ColdSources), nil
+}
 
-	if rand.Float32() < 0.5 {
-		// 50% вероятность персональных рекомендаций
-		if params.UserID != nil {
-			return uc.getPersonalRecommendations(ctx, *params.UserID, limit)
-		}
+// getMixedRecommendations возвращает рекомендации, когда тип не определён заранее.
+// Выбор между подходами детерминирован; сравнение подходов делается через эксперименты.
+func (uc *RecommendationsUC) getMixedRecommendations(ctx context.Context, params domain.RecommendationParams, opts recOptions) ([]domain.RecommendationResult, error) {
+	if params.UserID != nil {
+		return uc.getPersonalRecommendations(ctx, params, opts)
 	}
-
-	// Fallback к холодным рекомендациям
 	if params.SessionID != nil {
-		return uc.getColdRecommendations(ctx, *params.SessionID, limit)
+		return uc.getColdRecommendations(ctx, *params.SessionID, opts)
 	}
 
 	// Если ничего не подходит, возвращаем популярные
-	popularVideos, err := uc.store.GetPopularVideos(ctx, limit)
+	popularVideos, err := uc.store.GetPopularVideos(ctx, opts.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -247,10 +309,15 @@ func (uc *RecommendationsUC) videoExists(results []domain.RecommendationResult,
 	return false
 }
 
-func (uc *RecommendationsUC) getVideoIDs(results []domain.RecommendationResult) []string {
-	ids := make([]string, 0, len(results))
-	for _, result := range results {
-		ids = append(ids, result.Video.ID.String())
+// seenMargin лимит запроса к источнику с запасом на отфильтрованные просмотренные видео
+func seenMargin(limit int, seen map[uuid.UUID]bool) int {
+	return limit + min(len(seen), limit)
+}
+
+func seenIDs(seen map[uuid.UUID]bool) []string {
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id.String())
 	}
 	return ids
 }
@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/config"
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// memSeen seen-set в памяти; err возвращается из GetSeen
+type memSeen struct {
+	sets  map[uuid.UUID]map[uuid.UUID]time.Time
+	marks int
+	err   error
+}
+
+func newMemSeen() *memSeen {
+	return &memSeen{sets: map[uuid.UUID]map[uuid.UUID]time.Time{}}
+}
+
+func (s *memSeen) MarkSeen(_ context.Context, userID uuid.UUID, videoIDs []uuid.UUID, until time.Time, _ int) error {
+	s.marks++
+	if s.sets[userID] == nil {
+		s.sets[userID] = map[uuid.UUID]time.Time{}
+	}
+	for _, id := range videoIDs {
+		s.sets[userID][id] = until
+	}
+	return nil
+}
+
+func (s *memSeen) GetSeen(_ context.Context, userID uuid.UUID) (map[uuid.UUID]bool, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	seen := map[uuid.UUID]bool{}
+	for id, until := range s.sets[userID] {
+		if until.After(time.Now()) {
+			seen[id] = true
+		}
+	}
+	return seen, nil
+}
+
+func TestRecommendationsUC_MarkImpressions(t *testing.T) {
+	seen := newMemSeen()
+	uc := NewRecommendationsUC(nil, seen, nil, config.Config{SeenImpressionCooldown: time.Hour, SeenMaxItems: 100})
+
+	user, video := uuid.New(), uuid.New()
+	uc.markImpressions(context.Background(), user, []domain.RecommendationResult{{Video: domain.Video{ID: video}}})
+	assert.Equal(t, map[uuid.UUID]bool{video: true}, uc.seenSet(context.Background(), user))
+	assert.WithinDuration(t, time.Now().Add(time.Hour), seen.sets[user][video], time.Minute)
+
+	// Гость и выключенный cooldown seen-set не трогают
+	uc.markImpressions(context.Background(), uuid.Nil, []domain.RecommendationResult{{Video: domain.Video{ID: video}}})
+	uc.cfg.SeenImpressionCooldown = 0
+	uc.markImpressions(context.Background(), user, []domain.RecommendationResult{{Video: domain.Video{ID: uuid.New()}}})
+	assert.Equal(t, 1, seen.marks)
+}
+
+func TestRecommendationsUC_SeenSetUnavailable(t *testing.T) {
+	seen := newMemSeen()
+	seen.err = assert.AnError
+	uc := NewRecommendationsUC(nil, seen, nil, config.Config{})
+
+	// Redis недоступен — выдача без фильтра, а не ошибка
+	assert.Empty(t, uc.seenSet(context.Background(), uuid.New()))
+	assert.Nil(t, uc.seenSet(context.Background(), uuid.Nil))
+}
+
+func TestSeenMargin(t *testing.T) {
+	seen := map[uuid.UUID]bool{uuid.New(): true}
+
+	// источник запрашивается с запасом на отфильтрованные, но не больше чем вдвое
+	assert.Equal(t, 3, seenMargin(2, seen))
+	assert.Equal(t, 2, seenMargin(2, nil))
+	assert.Equal(t, 2, seenMargin(1, map[uuid.UUID]bool{uuid.New(): true, uuid.New(): true}))
+}
+
+// personalStore личные данные пользователя для персональной выдачи; reads — сколько раз их читали
+type personalStore struct {
+	repo.Store
+	followed []uuid.UUID
+	hidden   map[uuid.UUID]bool
+	reads    int
+}
+
+func (s *personalStore) GetFollowedAuthorIDs(context.Context, string) ([]uuid.UUID, error) {
+	s.reads++
+	return s.followed, nil
+}
+
+func (s *personalStore) GetNegativeFeedback(context.Context, uuid.UUID) (domain.NegativeFeedback, error) {
+	s.reads++
+	return domain.NegativeFeedback{Hidden: s.hidden}, nil
+}
+
+func newPersonalUC(store repo.Store, seen repo.SeenStore, videos []domain.Video) *RecommendationsUC {
+	uc := NewRecommendationsUC(store, seen, nil, config.Config{
+		RecsPersonalSources:    []config.RecSource{{Name: "static", Quota: 1, Weight: 0.5}},
+		RecsSourceTimeout:      time.Second,
+		SeenImpressionCooldown: time.Hour,
+	})
+	uc.pipeline.register(staticGenerator("static", videos, 0, nil))
+	return uc
+}
+
+func TestRecommendationsUC_SeenSetOnlyForViewer(t *testing.T) {
+	videos := testVideos(3)
+	victim := uuid.New()
+	victimID := victim.String()
+	seen := newMemSeen()
+	_ = seen.MarkSeen(context.Background(), victim, []uuid.UUID{videos[0].ID}, time.Now().Add(time.Hour), 0)
+	uc := newPersonalUC(&personalStore{}, seen, videos)
+
+	// Чужой user_id: seen-set жертвы не применяется и не пополняется
+	results, err := uc.GetRecommendations(context.Background(), domain.RecommendationParams{
+		UserID: &victimID, Limit: 10, ViewerID: uuid.New(),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, 1, seen.marks)
+	assert.Len(t, seen.sets[victim], 1)
+
+	// Сам пользователь: просмотренное пропускается, показ запоминается
+	results, err = uc.GetRecommendations(context.Background(), domain.RecommendationParams{
+		UserID: &victimID, Limit: 10, ViewerID: victim,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, 2, seen.marks)
+	assert.Len(t, seen.sets[victim], 3)
+}
+
+func TestRecommendationsUC_FollowsAndHiddenOnlyForViewer(t *testing.T) {
+	videos := testVideos(3)
+	author := uuid.New()
+	videos[2].AuthorID = &author
+	victim := uuid.New()
+	victimID := victim.String()
+	store := &personalStore{followed: []uuid.UUID{author}, hidden: map[uuid.UUID]bool{videos[0].ID: true}}
+	uc := newPersonalUC(store, newMemSeen(), videos)
+
+	// Чужой user_id: подписки и скрытые видео жертвы не влияют на выдачу
+	results, err := uc.GetRecommendations(context.Background(), domain.RecommendationParams{
+		UserID: &victimID, Limit: 10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, videos[0].ID, results[0].Video.ID)
+	assert.Zero(t, store.reads)
+
+	// Сам пользователь: скрытое пропускается, автор из подписок поднимается
+	results, err = uc.GetRecommendations(context.Background(), domain.RecommendationParams{
+		UserID: &victimID, Limit: 10, ViewerID: victim,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, videos[2].ID, results[0].Video.ID)
+	assert.Equal(t, 2, store.reads)
+}
+
+func TestRecommendationsUC_PrivateExplanationOnlyForViewerOrAdmin(t *testing.T) {
+	watched := uuid.New()
+	uc := NewRecommendationsUC(&personalStore{}, newMemSeen(), nil, config.Config{
+		RecsPersonalSources: []config.RecSource{{Name: "tags", Quota: 1, Weight: 0.5}},
+		RecsSourceTimeout:   time.Second,
+	})
+	uc.pipeline.register(generatorFunc{"tags", domain.ReasonUserTags, func(context.Context, *RecRequest, int) ([]domain.RecommendationResult, error) {
+		return []domain.RecommendationResult{{
+			Video: domain.Video{ID: uuid.New()},
+			Explanation: domain.Explanation{
+				MatchedTags: []domain.TagWeight{{Tag: "go", Weight: 3}},
+				SimilarTo:   &watched,
+			},
+		}}, nil
+	}})
+
+	user := uuid.New()
+	userID := user.String()
+	cases := []struct {
+		name    string
+		params  domain.RecommendationParams
+		visible bool
+	}{
+		{"чужой user_id", domain.RecommendationParams{UserID: &userID, ViewerID: uuid.New()}, false},
+		{"без JWT", domain.RecommendationParams{UserID: &userID}, false},
+		{"сам пользователь", domain.RecommendationParams{UserID: &userID, ViewerID: user}, true},
+		{"админ", domain.RecommendationParams{UserID: &userID, ViewerID: uuid.New(), Admin: true}, true},
+	}
+	for _, c := range cases {
+		results, err := uc.GetRecommendations(context.Background(), c.params)
+		assert.NoError(t, err, c.name)
+		if assert.Len(t, results, 1, c.name) {
+			e := results[0].Explanation
+			assert.Equal(t, "tags", e.Source, c.name)
+			assert.Equal(t, c.visible, e.MatchedTags != nil, c.name)
+			assert.Equal(t, c.visible, e.SimilarTo != nil, c.name)
+			assert.Nil(t, e.Scores, c.name)
+		}
+	}
+}
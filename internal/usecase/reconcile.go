@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+)
+
+// reconcileVideoBatch сколько видео сверяется в video_counters за один снимок
+const reconcileVideoBatch = 500
+
+// ReconcileUCInterface интерфейс для тестирования
+type ReconcileUCInterface interface {
+	Reconcile(ctx context.Context, p domain.ReconcileParams) (domain.ReconcileReport, error)
+}
+
+// ReconcileUC сверка video_counters, video_daily и video_hourly с сырыми событиями (HTTP и cmd/reconcile)
+type ReconcileUC struct {
+	store repo.Store
+}
+
+func NewReconcileUC(store repo.Store) *ReconcileUC {
+	return &ReconcileUC{store: store}
+}
+
+// Reconcile пересчитывает счётчики и, если p.Repair, исправляет расхождения.
+// Сверка идёт кусками (день таблицы или пачка видео), каждый кусок исправляется своей
+// транзакцией, поэтому прерванная сверка оставляет уже исправленные куски корректными.
+func (uc *ReconcileUC) Reconcile(ctx context.Context, p domain.ReconcileParams) (domain.ReconcileReport, error) {
+	if err := p.Validate(); err != nil {
+		return domain.ReconcileReport{}, err
+	}
+	from := p.From.UTC().Truncate(24 * time.Hour)
+	to := p.To.UTC().Truncate(24 * time.Hour)
+
+	rollups := make([]domain.CounterTable, 0, 2)
+	for _, table := range []domain.CounterTable{domain.CounterVideoDaily, domain.CounterVideoHourly} {
+		if p.HasTable(table) {
+			rollups = append(rollups, table)
+		}
+	}
+	if len(rollups) > 0 {
+		horizon, err := uc.rollupsHorizon(ctx)
+		if err != nil {
+			return domain.ReconcileReport{}, err
+		}
+		if from.Before(horizon) {
+			return domain.ReconcileReport{}, domain.ErrReconcileRetention
+		}
+	}
+
+	unlock, err := uc.store.LockReconcile(ctx)
+	if err != nil {
+		return domain.ReconcileReport{}, err
+	}
+	defer unlock()
+
+	rep := domain.ReconcileReport{
+		From:    from,
+		To:      to,
+		Repair:  p.Repair,
+		Checked: map[domain.CounterTable]int{},
+		Diffs:   map[domain.CounterTable]int{},
+	}
+
+	var videoIDs []uuid.UUID // nil — все видео
+	if len(p.VideoIDs) > 0 {
+		videoIDs = p.VideoIDs
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		for _, table := range rollups {
+			if err := uc.reconcileChunk(ctx, &rep, table, day, videoIDs); err != nil {
+				return rep, err
+			}
+		}
+	}
+
+	if !p.HasTable(domain.CounterVideoCounters) {
+		return rep, nil
+	}
+	if videoIDs == nil {
+		if videoIDs, err = uc.store.ReconcileVideos(ctx, from, to); err != nil {
+			return rep, err
+		}
+	}
+	for start := 0; start < len(videoIDs); start += reconcileVideoBatch {
+		batch := videoIDs[start:min(start+reconcileVideoBatch, len(videoIDs))]
+		if err := uc.reconcileChunk(ctx, &rep, domain.CounterVideoCounters, time.Time{}, batch); err != nil {
+			return rep, err
+		}
+	}
+	return rep, nil
+}
+
+// RepairRollups сверяет и исправляет video_daily и video_hourly за дни [from, to) перед удалением
+// событий или показов, из которых они пересчитываются. Дни раньше границы уже сверены
+// при прошлом удалении и пропускаются. Возвращает число исправленных строк.
+func (uc *ReconcileUC) RepairRollups(ctx context.Context, from, to time.Time) (int, error) {
+	horizon, err := uc.rollupsHorizon(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if from.Before(horizon) {
+		from = horizon
+	}
+	from = from.UTC().Truncate(24 * time.Hour)
+
+	repaired := 0
+	for day := from; day.Before(to); {
+		last := day.AddDate(0, 0, domain.MaxReconcileDays-1)
+		if !last.Before(to) {
+			last = to.AddDate(0, 0, -1)
+		}
+		rep, err := uc.Reconcile(ctx, domain.ReconcileParams{
+			From:   day,
+			To:     last,
+			Tables: []domain.CounterTable{domain.CounterVideoDaily, domain.CounterVideoHourly},
+			Repair: true,
+		})
+		if err != nil {
+			return repaired, err
+		}
+		repaired += rep.Repaired
+		day = last.AddDate(0, 0, 1)
+	}
+	return repaired, nil
+}
+
+// rollupsHorizon граница, раньше которой video_daily и video_hourly не сверяются:
+// события или показы, из которых они пересчитываются, удалены по сроку хранения
+func (uc *ReconcileUC) rollupsHorizon(ctx context.Context) (time.Time, error) {
+	events, err := uc.store.EventsHorizon(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	serves, err := uc.store.ServesHorizon(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if serves.After(events) {
+		return serves, nil
+	}
+	return events, nil
+}
+
+func (uc *ReconcileUC) reconcileChunk(ctx context.Context, rep *domain.ReconcileReport, table domain.CounterTable, day time.Time, videoIDs []uuid.UUID) error {
+	expected, actual, err := uc.store.ReconcileRows(ctx, table, day, videoIDs)
+	if err != nil {
+		return err
+	}
+	diffs, rows := domain.DiffCounters(table, expected, actual)
+	rep.Checked[table] += rows
+	rep.Diffs[table] += len(diffs)
+	if free := domain.MaxReconcileItems - len(rep.Items); free > 0 {
+		rep.Items = append(rep.Items, diffs[:min(free, len(diffs))]...)
+	}
+	if len(diffs) == 0 {
+		return nil
+	}
+	slog.Info("reconcile diffs", "table", table, "day", day, "diffs", len(diffs), "repair", rep.Repair)
+
+	if !rep.Repair {
+		return nil
+	}
+	if err := uc.store.ApplyCounterDeltas(ctx, table, diffs); err != nil {
+		return err
+	}
+	rep.Repaired += len(diffs)
+	return nil
+}
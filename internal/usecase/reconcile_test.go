@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCounters(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	same, drift, orphan, missing := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	expected := []domain.CounterRow{
+		{VideoID: same, Bucket: day, Values: domain.CounterValues{Views: 3}},
+		{VideoID: drift, Bucket: day, Values: domain.CounterValues{Views: 5, Clicks: 2}},
+		{VideoID: missing, Bucket: day, Values: domain.CounterValues{Likes: 1}},
+	}
+	actual := []domain.CounterRow{
+		{VideoID: same, Bucket: day, Values: domain.CounterValues{Views: 3}},
+		{VideoID: drift, Bucket: day, Values: domain.CounterValues{Views: 4, Clicks: 3}},
+		{VideoID: orphan, Bucket: day, Values: domain.CounterValues{Hides: 2}},
+	}
+
+	diffs, rows := domain.DiffCounters(domain.CounterVideoDaily, expected, actual)
+
+	assert.Equal(t, 4, rows)
+	if !assert.Len(t, diffs, 3) {
+		return
+	}
+	assert.Equal(t, drift, diffs[0].VideoID)
+	assert.Equal(t, domain.CounterValues{Views: 1, Clicks: -1}, diffs[0].Delta)
+	assert.Equal(t, missing, diffs[1].VideoID)
+	assert.Equal(t, domain.CounterValues{Likes: 1}, diffs[1].Delta)
+	// строка без событий обнуляется
+	assert.Equal(t, orphan, diffs[2].VideoID)
+	assert.Equal(t, domain.CounterValues{}, diffs[2].Expected)
+	assert.Equal(t, domain.CounterValues{Hides: -2}, diffs[2].Delta)
+}
+
+func TestReconcileParamsValidate(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, domain.ReconcileParams{From: day, To: day}.Validate())
+	assert.NoError(t, domain.ReconcileParams{From: day, To: day.AddDate(0, 0, domain.MaxReconcileDays-1)}.Validate())
+	assert.ErrorIs(t, domain.ReconcileParams{From: day, To: day.AddDate(0, 0, domain.MaxReconcileDays)}.Validate(), domain.ErrInvalidReconcile)
+	assert.ErrorIs(t, domain.ReconcileParams{From: day, To: day.AddDate(0, 0, -1)}.Validate(), domain.ErrInvalidReconcile)
+	assert.ErrorIs(t, domain.ReconcileParams{To: day}.Validate(), domain.ErrInvalidReconcile)
+}
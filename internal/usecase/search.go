@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"log/slog"
+	"strings"
 
 	"github.com/arasvet/microtube/internal/domain"
 	"github.com/arasvet/microtube/internal/repo"
@@ -10,14 +12,16 @@ import (
 // SearchUCInterface интерфейс для тестирования
 type SearchUCInterface interface {
 	SearchVideos(ctx context.Context, params domain.SearchParams) ([]domain.SearchResult, error)
+	Suggest(ctx context.Context, query string) (string, error)
 }
 
 type SearchUC struct {
 	store repo.Store
+	exp   Experimenter
 }
 
-func NewSearchUC(store repo.Store) *SearchUC {
-	return &SearchUC{store: store}
+func NewSearchUC(store repo.Store, exp Experimenter) *SearchUC {
+	return &SearchUC{store: store, exp: exp}
 }
 
 // SearchVideos выполняет поиск видео с валидацией параметров
@@ -27,6 +31,21 @@ func (uc *SearchUC) SearchVideos(ctx context.Context, params domain.SearchParams
 		return nil, err
 	}
 
+	// Вариант эксперимента может отключить расширение синонимами
+	synonyms := true
+	if a, ok := assignVariant(ctx, uc.exp, domain.SurfaceSearch, params.UserID, params.SessionID); ok {
+		synonyms = boolParam(a.Params, domain.ParamSynonyms, true)
+	}
+
+	// Расширяем запрос синонимами; без словаря поиск всё равно работает
+	if synonyms {
+		expansions, err := uc.expandQuery(ctx, params.Query)
+		if err != nil {
+			slog.Warn("search synonyms lookup failed", "err", err)
+		}
+		params.Expansions = expansions
+	}
+
 	// Выполняем поиск в репозитории
 	results, err := uc.store.SearchVideos(ctx, params)
 	if err != nil {
@@ -35,3 +54,62 @@ func (uc *SearchUC) SearchVideos(ctx context.Context, params domain.SearchParams
 
 	return results, nil
 }
+
+// Suggest возвращает исправленный запрос ("возможно, вы имели в виду") или пустую строку,
+// если исправлять нечего
+func (uc *SearchUC) Suggest(ctx context.Context, query string) (string, error) {
+	tokens := domain.SearchTokens(query)
+	if len(tokens) == 0 {
+		return "", nil
+	}
+
+	suggested, err := uc.store.SuggestQueryTokens(ctx, tokens)
+	if err != nil {
+		return "", err
+	}
+
+	suggestion := strings.Join(suggested, " ")
+	if suggestion == strings.Join(tokens, " ") {
+		return "", nil
+	}
+	return suggestion, nil
+}
+
+// RefreshVocabulary пересобирает словарь заголовков, по которому строятся подсказки
+func (uc *SearchUC) RefreshVocabulary(ctx context.Context) error {
+	return uc.store.RefreshTitleVocabulary(ctx)
+}
+
+// expandQuery находит синонимы для слов запроса: слово -> остальные слова его групп
+func (uc *SearchUC) expandQuery(ctx context.Context, query string) (map[string][]string, error) {
+	tokens := domain.SearchTokens(query)
+	synonyms, err := uc.store.FindSynonyms(ctx, tokens)
+	if err != nil || len(synonyms) == 0 {
+		return nil, err
+	}
+
+	expansions := make(map[string][]string)
+	for _, token := range tokens {
+		for _, s := range synonyms {
+			group := s.Group()
+			if !containsString(group, token) {
+				continue
+			}
+			for _, alt := range group {
+				if alt != token && !containsString(expansions[token], alt) {
+					expansions[token] = append(expansions[token], alt)
+				}
+			}
+		}
+	}
+	return expansions, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
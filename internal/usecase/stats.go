@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/arasvet/microtube/internal/domain"
@@ -10,6 +11,11 @@ import (
 
 type StatsUCInterface interface {
 	Overview(ctx context.Context, from, to time.Time, top int) (domain.StatsOverview, error)
+	Positions(ctx context.Context, surface domain.ExperimentSurface, from, to time.Time) ([]domain.PositionCTR, error)
+	Timeseries(ctx context.Context, p domain.TimeseriesParams) (domain.Timeseries, error)
+	ActiveUsers(ctx context.Context, from, to time.Time) ([]domain.ActiveUsers, error)
+	Cohorts(ctx context.Context, weeks int) ([]domain.Cohort, error)
+	Funnel(ctx context.Context, from, to time.Time) (domain.Funnel, error)
 }
 
 type StatsUC struct {
@@ -47,3 +53,157 @@ func (uc *StatsUC) Overview(ctx context.Context, from, to time.Time, top int) (d
 		TopVideos: topVideos,
 	}, nil
 }
+
+// Positions CTR по позициям выдачи: доля показов, после которых был клик или просмотр с тем же serve_id
+func (uc *StatsUC) Positions(ctx context.Context, surface domain.ExperimentSurface, from, to time.Time) ([]domain.PositionCTR, error) {
+	return uc.store.StatsPositionCTR(ctx, surface, from, to, domain.MaxStatsPosition)
+}
+
+// Timeseries ряды метрик по корзинам с разбивкой и, по запросу, сравнением с предыдущим периодом
+func (uc *StatsUC) Timeseries(ctx context.Context, p domain.TimeseriesParams) (domain.Timeseries, error) {
+	if err := p.Validate(); err != nil {
+		return domain.Timeseries{}, err
+	}
+	rows, err := uc.store.StatsTimeseries(ctx, p, nil)
+	if err != nil {
+		return domain.Timeseries{}, err
+	}
+	res := buildTimeseries(p, rows)
+	if !p.Compare {
+		return res, nil
+	}
+
+	// Предыдущий период считаем по тем же значениям разбивки, что и текущий
+	prev := p.Previous()
+	keys := make([]string, 0, len(res.Series))
+	for _, s := range res.Series {
+		keys = append(keys, s.Key)
+	}
+	prevRows, err := uc.store.StatsTimeseries(ctx, prev, keys)
+	if err != nil {
+		return domain.Timeseries{}, err
+	}
+	compareTimeseries(&res, prev, prevRows)
+	return res, nil
+}
+
+// buildTimeseries раскладывает строки по рядам и заполняет пустые корзины нулями.
+// Ряды упорядочены по убыванию итога первой метрики.
+func buildTimeseries(p domain.TimeseriesParams, rows []domain.TimeseriesRow) domain.Timeseries {
+	res := domain.Timeseries{
+		From:        p.From,
+		To:          p.To,
+		Granularity: p.Granularity,
+		Metrics:     p.Metrics,
+		Breakdown:   p.Breakdown,
+		Series:      []domain.TimeseriesSeries{},
+	}
+	buckets := p.Buckets()
+	index := bucketIndex(buckets)
+
+	byKey := map[string]int{}
+	for _, row := range rows {
+		i, ok := byKey[row.Key]
+		if !ok {
+			i = len(res.Series)
+			byKey[row.Key] = i
+			res.Series = append(res.Series, newSeries(row.Key, buckets, p.Metrics))
+		}
+		b, ok := index[row.Bucket.Unix()]
+		if !ok {
+			continue
+		}
+		s := &res.Series[i]
+		for _, m := range p.Metrics {
+			v := row.Values.Get(m)
+			s.Points[b].Values[m] += v
+			s.Totals[m] += v
+		}
+	}
+	// Без разбивки ряд есть всегда, даже если событий не было
+	if p.Breakdown == domain.BreakdownNone && len(res.Series) == 0 {
+		res.Series = append(res.Series, newSeries("", buckets, p.Metrics))
+	}
+
+	first := p.Metrics[0]
+	sort.SliceStable(res.Series, func(i, j int) bool {
+		return res.Series[i].Totals[first] > res.Series[j].Totals[first]
+	})
+	return res
+}
+
+// compareTimeseries добавляет к точкам значения той же по счёту корзины предыдущего периода
+// и относительное изменение итогов
+func compareTimeseries(res *domain.Timeseries, prev domain.TimeseriesParams, rows []domain.TimeseriesRow) {
+	res.PrevFrom, res.PrevTo = &prev.From, &prev.To
+	index := bucketIndex(prev.Buckets())
+
+	byKey := map[string]int{}
+	for i := range res.Series {
+		s := &res.Series[i]
+		byKey[s.Key] = i
+		s.PrevTotals = make(map[domain.StatsMetric]float64, len(res.Metrics))
+		for j := range s.Points {
+			s.Points[j].Previous = make(map[domain.StatsMetric]float64, len(res.Metrics))
+			for _, m := range res.Metrics {
+				s.Points[j].Previous[m] = 0
+			}
+		}
+		for _, m := range res.Metrics {
+			s.PrevTotals[m] = 0
+		}
+	}
+
+	for _, row := range rows {
+		i, ok := byKey[row.Key]
+		if !ok {
+			continue
+		}
+		b, ok := index[row.Bucket.Unix()]
+		if !ok || b >= len(res.Series[i].Points) {
+			continue
+		}
+		s := &res.Series[i]
+		for _, m := range res.Metrics {
+			v := row.Values.Get(m)
+			s.Points[b].Previous[m] += v
+			s.PrevTotals[m] += v
+		}
+	}
+
+	for i := range res.Series {
+		s := &res.Series[i]
+		s.Change = map[domain.StatsMetric]float64{}
+		for _, m := range res.Metrics {
+			if s.PrevTotals[m] > 0 {
+				s.Change[m] = (s.Totals[m] - s.PrevTotals[m]) / s.PrevTotals[m]
+			}
+		}
+	}
+}
+
+func newSeries(key string, buckets []time.Time, metrics []domain.StatsMetric) domain.TimeseriesSeries {
+	s := domain.TimeseriesSeries{
+		Key:    key,
+		Points: make([]domain.TimeseriesPoint, len(buckets)),
+		Totals: make(map[domain.StatsMetric]float64, len(metrics)),
+	}
+	for i, b := range buckets {
+		s.Points[i] = domain.TimeseriesPoint{TS: b, Values: make(map[domain.StatsMetric]float64, len(metrics))}
+		for _, m := range metrics {
+			s.Points[i].Values[m] = 0
+		}
+	}
+	for _, m := range metrics {
+		s.Totals[m] = 0
+	}
+	return s
+}
+
+func bucketIndex(buckets []time.Time) map[int64]int {
+	index := make(map[int64]int, len(buckets))
+	for i, b := range buckets {
+		index[b.Unix()] = i
+	}
+	return index
+}
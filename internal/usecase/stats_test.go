@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeseriesParams_AlignsToBuckets(t *testing.T) {
+	p := domain.TimeseriesParams{
+		From:        time.Date(2024, 3, 6, 15, 30, 0, 0, time.UTC), // среда
+		To:          time.Date(2024, 3, 20, 1, 0, 0, 0, time.UTC),
+		Granularity: domain.GranularityWeek,
+		Metrics:     []domain.StatsMetric{domain.MetricViews},
+	}
+	assert.NoError(t, p.Validate())
+
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), p.From) // понедельник
+	assert.Equal(t, time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC), p.To)
+	assert.Len(t, p.Buckets(), 3)
+
+	prev := p.Previous()
+	assert.Equal(t, time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC), prev.From)
+	assert.Equal(t, p.From, prev.To)
+}
+
+func TestTimeseriesParams_RangeTooLarge(t *testing.T) {
+	p := domain.TimeseriesParams{
+		From:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:          time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		Granularity: domain.GranularityHour,
+		Metrics:     []domain.StatsMetric{domain.MetricViews},
+	}
+	assert.ErrorIs(t, p.Validate(), domain.ErrStatsRangeTooLarge)
+}
+
+func TestBuildTimeseries_FillsGapsAndCompares(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	p := domain.TimeseriesParams{
+		From:        day(1),
+		To:          day(4),
+		Granularity: domain.GranularityDay,
+		Metrics:     []domain.StatsMetric{domain.MetricViews, domain.MetricDwell},
+		Breakdown:   domain.BreakdownLang,
+	}
+	assert.NoError(t, p.Validate())
+
+	res := buildTimeseries(p, []domain.TimeseriesRow{
+		{Bucket: day(1), Key: "en", Values: domain.StatsValues{Views: 2, DwellMs: 100}},
+		{Bucket: day(3), Key: "en", Values: domain.StatsValues{Views: 3}},
+		{Bucket: day(2), Key: "ru", Values: domain.StatsValues{Views: 10}},
+	})
+
+	if !assert.Len(t, res.Series, 2) {
+		return
+	}
+	// ряды по убыванию первой метрики
+	assert.Equal(t, "ru", res.Series[0].Key)
+	en := res.Series[1]
+	if !assert.Len(t, en.Points, 3) {
+		return
+	}
+	assert.Equal(t, 0.0, en.Points[1].Values[domain.MetricViews]) // пустая корзина заполнена нулём
+	assert.Equal(t, 5.0, en.Totals[domain.MetricViews])
+	assert.Equal(t, 100.0, en.Totals[domain.MetricDwell])
+
+	prev := p.Previous()
+	compareTimeseries(&res, prev, []domain.TimeseriesRow{
+		{Bucket: day(1).AddDate(0, 0, -3), Key: "en", Values: domain.StatsValues{Views: 4}},
+		{Bucket: day(1).AddDate(0, 0, -1), Key: "de", Values: domain.StatsValues{Views: 7}},
+	})
+	en = res.Series[1]
+	assert.Equal(t, 4.0, en.Points[0].Previous[domain.MetricViews])
+	assert.InDelta(t, 0.25, en.Change[domain.MetricViews], 1e-9)
+	// у ru в прошлом периоде ничего не было — изменения нет
+	_, ok := res.Series[0].Change[domain.MetricViews]
+	assert.False(t, ok)
+}
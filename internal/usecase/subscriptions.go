@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+)
+
+// SubscriptionsUCInterface интерфейс для тестирования
+type SubscriptionsUCInterface interface {
+	Follow(ctx context.Context, followerID, authorID uuid.UUID) (domain.AuthorProfile, error)
+	Unfollow(ctx context.Context, followerID, authorID uuid.UUID) (domain.AuthorProfile, error)
+	Author(ctx context.Context, authorID, viewerID uuid.UUID) (domain.AuthorProfile, error)
+}
+
+type SubscriptionsUC struct {
+	store repo.Store
+}
+
+func NewSubscriptionsUC(store repo.Store) *SubscriptionsUC {
+	return &SubscriptionsUC{store: store}
+}
+
+// Follow подписывает пользователя на автора (повторная подписка не ошибка)
+func (uc *SubscriptionsUC) Follow(ctx context.Context, followerID, authorID uuid.UUID) (domain.AuthorProfile, error) {
+	if followerID == authorID {
+		return domain.AuthorProfile{}, domain.ErrCannotFollowSelf
+	}
+	if err := uc.ensureAuthor(ctx, authorID); err != nil {
+		return domain.AuthorProfile{}, err
+	}
+	if _, err := uc.store.Follow(ctx, followerID, authorID); err != nil {
+		return domain.AuthorProfile{}, err
+	}
+	return uc.store.GetAuthorProfile(ctx, authorID, followerID)
+}
+
+// Unfollow отписывает пользователя от автора (отписка без подписки не ошибка)
+func (uc *SubscriptionsUC) Unfollow(ctx context.Context, followerID, authorID uuid.UUID) (domain.AuthorProfile, error) {
+	if err := uc.ensureAuthor(ctx, authorID); err != nil {
+		return domain.AuthorProfile{}, err
+	}
+	if _, err := uc.store.Unfollow(ctx, followerID, authorID); err != nil {
+		return domain.AuthorProfile{}, err
+	}
+	return uc.store.GetAuthorProfile(ctx, authorID, followerID)
+}
+
+// Author возвращает профиль автора с числом подписчиков; viewerID может быть uuid.Nil для гостей
+func (uc *SubscriptionsUC) Author(ctx context.Context, authorID, viewerID uuid.UUID) (domain.AuthorProfile, error) {
+	if err := uc.ensureAuthor(ctx, authorID); err != nil {
+		return domain.AuthorProfile{}, err
+	}
+	return uc.store.GetAuthorProfile(ctx, authorID, viewerID)
+}
+
+func (uc *SubscriptionsUC) ensureAuthor(ctx context.Context, authorID uuid.UUID) error {
+	exists, err := uc.store.UserExists(ctx, authorID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrAuthorNotFound
+	}
+	return nil
+}
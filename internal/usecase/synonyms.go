@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/arasvet/microtube/internal/repo"
+	"github.com/google/uuid"
+)
+
+// SynonymsUCInterface интерфейс для тестирования
+type SynonymsUCInterface interface {
+	List(ctx context.Context) ([]domain.Synonym, error)
+	Create(ctx context.Context, s domain.Synonym) (domain.Synonym, error)
+	Update(ctx context.Context, s domain.Synonym) (domain.Synonym, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// SynonymsUC управление словарём синонимов поиска (для админов)
+type SynonymsUC struct {
+	store repo.Store
+}
+
+func NewSynonymsUC(store repo.Store) *SynonymsUC {
+	return &SynonymsUC{store: store}
+}
+
+func (uc *SynonymsUC) List(ctx context.Context) ([]domain.Synonym, error) {
+	return uc.store.ListSynonyms(ctx)
+}
+
+func (uc *SynonymsUC) Create(ctx context.Context, s domain.Synonym) (domain.Synonym, error) {
+	s.Normalize()
+	if err := s.Validate(); err != nil {
+		return domain.Synonym{}, err
+	}
+	s.ID = uuid.New()
+	return uc.store.CreateSynonym(ctx, s)
+}
+
+func (uc *SynonymsUC) Update(ctx context.Context, s domain.Synonym) (domain.Synonym, error) {
+	s.Normalize()
+	if err := s.Validate(); err != nil {
+		return domain.Synonym{}, err
+	}
+	return uc.store.UpdateSynonym(ctx, s)
+}
+
+func (uc *SynonymsUC) Delete(ctx context.Context, id uuid.UUID) error {
+	return uc.store.DeleteSynonym(ctx, id)
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/arasvet/microtube/internal/domain"
+	"github.com/google/uuid"
+)
+
+// Веса окон в смешанном trending-скоре
+const (
+	trendingHourWeight = 0.5
+	trendingDayWeight  = 0.3
+	trendingWeekWeight = 0.2
+)
+
+// RefreshTrending пересчитывает trending-рейтинги всех окон и сохраняет их в Redis
+func (uc *FeedUC) RefreshTrending(ctx context.Context) error {
+	halfLifeDays := uc.cfg.TrendingHalfLife.Hours() / 24
+	if halfLifeDays <= 0 {
+		halfLifeDays = 2
+	}
+
+	counters, err := uc.store.TrendingCounters(ctx, halfLifeDays)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	// Рейтинг живёт несколько циклов обновления, чтобы пережить сбой одного пересчёта
+	ttl := 3 * uc.cfg.TrendingRefresh
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+
+	for _, window := range domain.TrendingWindows {
+		scores := make(map[uuid.UUID]float64)
+		for _, c := range counters {
+			if c.WeekRaw < int64(uc.cfg.TrendingMinEvents) {
+				continue // отсекаем шум от видео с единичными просмотрами
+			}
+			score := trendingScore(c, window, uc.cfg.TrendingAgeHalfLife, now)
+			if score > 0 {
+				scores[c.VideoID] = score
+			}
+		}
+		if err := uc.trending.SaveTrending(ctx, window, scores, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trendingScore скорость набора активности (в час) в окне, умноженная на затухание по возрасту видео
+func trendingScore(c domain.TrendingCounters, window domain.TrendingWindow, ageHalfLife time.Duration, now time.Time) float64 {
+	hourRate := c.Hour
+	dayRate := c.Day / 24
+	weekRate := c.Week / (24 * 7)
+
+	var velocity float64
+	switch window {
+	case domain.TrendingWindowHour:
+		velocity = hourRate
+	case domain.TrendingWindowDay:
+		velocity = dayRate
+	case domain.TrendingWindowWeek:
+		velocity = weekRate
+	default:
+		velocity = trendingHourWeight*hourRate + trendingDayWeight*dayRate + trendingWeekWeight*weekRate
+	}
+
+	if ageHalfLife > 0 {
+		age := now.Sub(c.UploadedAt)
+		if age > 0 {
+			velocity *= math.Exp2(-age.Hours() / ageHalfLife.Hours())
+		}
+	}
+	return velocity
+}
@@ -0,0 +1,32 @@
+SET search_path TO app, public;
+
+-- Словарь синонимов для расширения поисковых запросов (k8s -> kubernetes и т.п.)
+CREATE TABLE IF NOT EXISTS search_synonyms (
+    id          uuid PRIMARY KEY,
+    term        text UNIQUE NOT NULL,
+    synonyms    text[] NOT NULL DEFAULT '{}',
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS search_synonyms_synonyms_idx
+    ON search_synonyms USING GIN (synonyms);
+
+INSERT INTO search_synonyms (id, term, synonyms) VALUES
+    (gen_random_uuid(), 'golang', '{go}'),
+    (gen_random_uuid(), 'k8s', '{kubernetes}'),
+    (gen_random_uuid(), 'postgres', '{postgresql,pg}'),
+    (gen_random_uuid(), 'ml', '{machine learning}'),
+    (gen_random_uuid(), 'ai', '{artificial intelligence}')
+ON CONFLICT (term) DO NOTHING;
+
+-- Словарь слов из заголовков для подсказок "возможно, вы имели в виду"
+CREATE MATERIALIZED VIEW IF NOT EXISTS title_vocabulary AS
+SELECT word, ndoc
+FROM ts_stat($$ SELECT to_tsvector('simple', title) FROM app.videos $$);
+
+CREATE UNIQUE INDEX IF NOT EXISTS title_vocabulary_word_idx
+    ON title_vocabulary (word);
+
+CREATE INDEX IF NOT EXISTS title_vocabulary_word_trgm_idx
+    ON title_vocabulary USING GIN (word gin_trgm_ops);
@@ -0,0 +1,8 @@
+SET search_path TO app, public;
+
+-- Индексы для расчёта trending: свежие события и последние дни суточной аналитики
+CREATE INDEX IF NOT EXISTS events_ts_idx
+    ON events (ts);
+
+CREATE INDEX IF NOT EXISTS video_daily_day_idx
+    ON video_daily (day);
@@ -0,0 +1,34 @@
+SET search_path TO app, public;
+
+-- История просмотров: одна запись на пару пользователь-видео
+CREATE TABLE IF NOT EXISTS watch_history (
+    user_id          uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    video_id         uuid NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    first_watched_at timestamptz NOT NULL,
+    last_watched_at  timestamptz NOT NULL,
+    watched_ms       bigint NOT NULL DEFAULT 0,
+    completed        boolean NOT NULL DEFAULT false,
+    PRIMARY KEY (user_id, video_id)
+);
+
+CREATE INDEX IF NOT EXISTS watch_history_user_last_idx
+    ON watch_history (user_id, last_watched_at DESC, video_id DESC);
+
+-- Настройки пользователя (пауза истории)
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id         uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    history_paused  boolean NOT NULL DEFAULT false,
+    updated_at      timestamptz NOT NULL DEFAULT now()
+);
+
+-- Переносим уже накопленные просмотры в историю
+INSERT INTO watch_history (user_id, video_id, first_watched_at, last_watched_at, watched_ms, completed)
+SELECT e.user_id, e.video_id, MIN(e.ts), MAX(e.ts),
+       COALESCE(MAX(e.dwell_ms), 0),
+       bool_or(e.type = 'view_complete')
+FROM events e
+JOIN videos v ON v.id = e.video_id
+JOIN users u ON u.id = e.user_id
+WHERE e.type IN ('view_start', 'view_complete')
+GROUP BY e.user_id, e.video_id
+ON CONFLICT (user_id, video_id) DO NOTHING;
@@ -0,0 +1,34 @@
+SET search_path TO app, public;
+
+-- Негативные сигналы пользователя
+ALTER TYPE event_type ADD VALUE IF NOT EXISTS 'dislike';
+ALTER TYPE event_type ADD VALUE IF NOT EXISTS 'unlike';
+ALTER TYPE event_type ADD VALUE IF NOT EXISTS 'hide_video';
+ALTER TYPE event_type ADD VALUE IF NOT EXISTS 'not_interested_tag';
+
+-- Тег для not_interested_tag
+ALTER TABLE events ADD COLUMN IF NOT EXISTS tag text;
+
+ALTER TABLE video_counters ADD COLUMN IF NOT EXISTS dislikes bigint NOT NULL DEFAULT 0;
+ALTER TABLE video_counters ADD COLUMN IF NOT EXISTS hides    bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE video_daily ADD COLUMN IF NOT EXISTS dislikes bigint NOT NULL DEFAULT 0;
+ALTER TABLE video_daily ADD COLUMN IF NOT EXISTS unlikes  bigint NOT NULL DEFAULT 0;
+ALTER TABLE video_daily ADD COLUMN IF NOT EXISTS hides    bigint NOT NULL DEFAULT 0;
+
+-- Скрытые пользователем видео: не показываются в рекомендациях и фидах
+CREATE TABLE IF NOT EXISTS user_hidden_videos (
+    user_id    uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    video_id   uuid NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    hidden_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, video_id)
+);
+
+-- Накопленная неприязнь пользователя к тегам (dislike видео и not_interested_tag)
+CREATE TABLE IF NOT EXISTS user_tag_feedback (
+    user_id    uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tag        text NOT NULL,
+    weight     double precision NOT NULL DEFAULT 0,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, tag)
+);
@@ -0,0 +1,11 @@
+SET search_path TO app, public;
+
+-- Статистика бандита исследования свежих видео: показы в слоте exploration
+-- и начатые просмотры (награда) после первого такого показа.
+CREATE TABLE IF NOT EXISTS video_bandit (
+    video_id       uuid PRIMARY KEY REFERENCES videos(id) ON DELETE CASCADE,
+    impressions    bigint NOT NULL DEFAULT 0,
+    clicks         bigint NOT NULL DEFAULT 0,
+    first_shown_at timestamptz NOT NULL DEFAULT now(),
+    last_shown_at  timestamptz NOT NULL DEFAULT now()
+);
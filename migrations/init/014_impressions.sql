@@ -0,0 +1,33 @@
+SET search_path TO app, public;
+
+-- Выдачи /search, /videos/feed и /recommendations. serve_id отдаётся клиенту
+-- и возвращается им в событиях клика и просмотра.
+CREATE TABLE IF NOT EXISTS serves (
+    serve_id   uuid PRIMARY KEY,
+    ts         timestamptz NOT NULL,
+    surface    text NOT NULL CHECK (surface IN ('feed', 'recommendations', 'search')),
+    user_id    uuid,
+    session_id text,
+    query      text
+);
+
+-- Показы: какие видео и на каких позициях вернула выдача.
+-- clicked_at — первый клик или начатый просмотр с этим serve_id.
+CREATE TABLE IF NOT EXISTS impressions (
+    serve_id   uuid NOT NULL REFERENCES serves(serve_id) ON DELETE CASCADE,
+    position   int NOT NULL,
+    video_id   uuid NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    surface    text NOT NULL,
+    source     text NOT NULL DEFAULT '',
+    reason     text NOT NULL DEFAULT '',
+    ts         timestamptz NOT NULL,
+    clicked_at timestamptz,
+    PRIMARY KEY (serve_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS impressions_serve_video_idx ON impressions (serve_id, video_id);
+CREATE INDEX IF NOT EXISTS impressions_ts_idx ON impressions (ts);
+CREATE INDEX IF NOT EXISTS impressions_video_ts_idx ON impressions (video_id, ts);
+
+-- serve_id выдачи, из которой пришло событие
+ALTER TABLE events ADD COLUMN IF NOT EXISTS serve_id uuid;
@@ -0,0 +1,40 @@
+SET search_path TO app, public;
+
+-- Продуктовые метрики: агрегаты поверх events, пересчитываются фоновой задачей за последние дни
+
+-- Активные за день: пользователь (u:<user_id>) или анонимная сессия (s:<session_id>)
+CREATE TABLE IF NOT EXISTS activity_daily (
+    day      date NOT NULL,
+    actor    text NOT NULL,
+    user_id  uuid,
+    PRIMARY KEY (day, actor)
+);
+
+CREATE INDEX IF NOT EXISTS activity_daily_user_idx ON activity_daily (user_id, day) WHERE user_id IS NOT NULL;
+
+-- Недельные когорты по дате регистрации: сколько пользователей когорты были активны в неделю activity_week
+CREATE TABLE IF NOT EXISTS cohort_weekly (
+    cohort_week    date NOT NULL,
+    activity_week  date NOT NULL,
+    users          bigint NOT NULL,
+    PRIMARY KEY (cohort_week, activity_week)
+);
+
+CREATE INDEX IF NOT EXISTS cohort_weekly_activity_idx ON cohort_weekly (activity_week);
+
+-- Воронка поиска по сессиям за день: искали -> кликнули -> начали смотреть -> досмотрели
+CREATE TABLE IF NOT EXISTS funnel_daily (
+    day        date PRIMARY KEY,
+    searched   bigint NOT NULL,
+    clicked    bigint NOT NULL,
+    viewed     bigint NOT NULL,
+    completed  bigint NOT NULL
+);
+
+-- Момент последнего пересчёта агрегатов по имени задачи
+CREATE TABLE IF NOT EXISTS metrics_refresh (
+    name          text PRIMARY KEY,
+    refreshed_at  timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS users_created_at_idx ON users (created_at);
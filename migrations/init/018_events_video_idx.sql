@@ -0,0 +1,5 @@
+SET search_path TO app, public;
+
+-- Пересчёт счётчиков видео из сырых событий (сверка video_counters за всё время)
+CREATE INDEX IF NOT EXISTS events_video_ts_idx
+    ON events (video_id, ts) WHERE video_id IS NOT NULL;
@@ -0,0 +1,90 @@
+SET search_path TO app, public;
+
+-- events партиционируется по месяцам ts (UTC). Ключ партиционирования входит в первичный ключ,
+-- поэтому идемпотентность приёма — по (event_id, ts): повтор события приходит с тем же ts.
+ALTER TABLE events RENAME TO events_unpartitioned;
+
+CREATE TABLE events (
+    event_id    uuid        NOT NULL,
+    ts          timestamptz NOT NULL,
+    type        event_type  NOT NULL,
+    session_id  text        NOT NULL,
+    user_id     uuid,
+    video_id    uuid,
+    query       text,
+    dwell_ms    int,
+    tag         text,
+    serve_id    uuid,
+    position_ms integer,
+    CONSTRAINT events_video_fk FOREIGN KEY (video_id) REFERENCES videos(id),
+    CONSTRAINT events_user_fk  FOREIGN KEY (user_id)  REFERENCES users(id)
+) PARTITION BY RANGE (ts);
+
+-- Страховка: события за месяцы без партиции (далеко в будущем) не теряются,
+-- а переносятся в партицию месяца при её создании
+CREATE TABLE events_default PARTITION OF events DEFAULT;
+
+-- Создаёт недостающие помесячные партиции events_YYYY_MM с месяца from_ts по месяц to_ts включительно.
+-- Вызывается миграцией и фоновой задачей API; возвращает число созданных партиций.
+CREATE OR REPLACE FUNCTION ensure_event_partitions(from_ts timestamptz, to_ts timestamptz) RETURNS integer
+LANGUAGE plpgsql AS $$
+DECLARE
+    m       timestamp := date_trunc('month', from_ts AT TIME ZONE 'UTC');
+    lo      timestamptz;
+    hi      timestamptz;
+    part    text;
+    created integer := 0;
+BEGIN
+    -- реплики API вызывают функцию одновременно
+    PERFORM pg_advisory_xact_lock(hashtext('event_partitions'));
+    WHILE m <= to_ts AT TIME ZONE 'UTC' LOOP
+        part := 'events_' || to_char(m, 'YYYY_MM');
+        lo := m AT TIME ZONE 'UTC';
+        hi := (m + interval '1 month') AT TIME ZONE 'UTC';
+        IF to_regclass('app.' || part) IS NULL THEN
+            EXECUTE format('CREATE TABLE app.%I (LIKE app.events INCLUDING DEFAULTS)', part);
+            EXECUTE format('WITH moved AS (DELETE FROM app.events_default WHERE ts >= $1 AND ts < $2 RETURNING *)
+                            INSERT INTO app.%I SELECT * FROM moved', part) USING lo, hi;
+            EXECUTE format('ALTER TABLE app.events ATTACH PARTITION app.%I FOR VALUES FROM (%L) TO (%L)', part, lo, hi);
+            created := created + 1;
+        END IF;
+        m := m + interval '1 month';
+    END LOOP;
+    RETURN created;
+END
+$$;
+
+SELECT ensure_event_partitions(COALESCE((SELECT MIN(ts) FROM events_unpartitioned), now()), now() + interval '3 months');
+
+INSERT INTO events (event_id, ts, type, session_id, user_id, video_id, query, dwell_ms, tag, serve_id, position_ms)
+SELECT event_id, ts, type, session_id, user_id, video_id, query, dwell_ms, tag, serve_id, position_ms
+FROM events_unpartitioned;
+
+DROP TABLE events_unpartitioned;
+
+-- Индексы создаются на родителе и наследуются партициями, в том числе будущими
+ALTER TABLE events ADD CONSTRAINT events_pkey PRIMARY KEY (event_id, ts);
+CREATE INDEX IF NOT EXISTS events_ts_idx ON events (ts);
+CREATE INDEX IF NOT EXISTS events_user_ts_idx ON events (user_id, ts) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS events_session_ts_idx ON events (session_id, ts);
+CREATE INDEX IF NOT EXISTS events_video_ts_idx ON events (video_id, ts) WHERE video_id IS NOT NULL;
+
+-- Партиции, удалённые по сроку хранения; range_to последней — граница, раньше которой событий нет
+CREATE TABLE IF NOT EXISTS events_retention (
+    partition  text PRIMARY KEY,
+    range_from timestamptz NOT NULL,
+    range_to   timestamptz NOT NULL,
+    events     bigint NOT NULL,
+    dropped_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- Вклад удалённых событий в video_counters: сверка счётчиков за всё время складывает его с events
+CREATE TABLE IF NOT EXISTS video_counters_base (
+    video_id  uuid PRIMARY KEY REFERENCES videos(id),
+    views     bigint NOT NULL DEFAULT 0,
+    completes bigint NOT NULL DEFAULT 0,
+    likes     bigint NOT NULL DEFAULT 0,
+    unlikes   bigint NOT NULL DEFAULT 0,
+    dislikes  bigint NOT NULL DEFAULT 0,
+    hides     bigint NOT NULL DEFAULT 0
+);
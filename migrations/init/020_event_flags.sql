@@ -0,0 +1,5 @@
+SET search_path TO app, public;
+
+-- Причина, по которой событие признано ботом или накруткой. Такие события хранятся,
+-- но не попадают в счётчики, статистику и обучение рекомендаций.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS flag text;
@@ -0,0 +1,11 @@
+SET search_path TO app, public;
+
+CREATE INDEX IF NOT EXISTS serves_ts_idx ON serves (ts);
+
+-- Удаления выдач и показов старше срока хранения; range_to последнего — граница,
+-- раньше которой показов нет и video_daily/video_hourly не сверяются
+CREATE TABLE IF NOT EXISTS serves_retention (
+    range_to   timestamptz PRIMARY KEY,
+    serves     bigint NOT NULL,
+    deleted_at timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,9 @@
+SET search_path TO app, public;
+
+-- Награда бандита — клик по показу из слота exploration, а не любой начатый просмотр видео:
+-- пересчитываем накопленные клики по показам
+UPDATE video_bandit b
+SET clicks = (
+    SELECT COUNT(*) FROM impressions i
+    WHERE i.video_id = b.video_id AND i.reason = 'exploration' AND i.clicked_at IS NOT NULL
+);
@@ -0,0 +1,16 @@
+SET search_path TO app, public;
+
+-- Первичный ключ партиционированной events — (event_id, ts), и событие с тем же event_id,
+-- но другим ts, прошло бы как новое. Уникальность event_id держит отдельная
+-- непартиционированная таблица, в которую приём пишет в той же транзакции.
+CREATE TABLE IF NOT EXISTS event_ids (
+    event_id uuid PRIMARY KEY,
+    ts       timestamptz NOT NULL
+);
+
+-- По ts удаляются строки вместе с партицией событий
+CREATE INDEX IF NOT EXISTS event_ids_ts_idx ON event_ids (ts);
+
+INSERT INTO event_ids (event_id, ts)
+SELECT event_id, MIN(ts) FROM events GROUP BY event_id
+ON CONFLICT (event_id) DO NOTHING;
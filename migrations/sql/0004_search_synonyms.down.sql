@@ -0,0 +1,4 @@
+SET search_path TO app, public;
+
+DROP MATERIALIZED VIEW IF EXISTS title_vocabulary;
+DROP TABLE IF EXISTS search_synonyms;
@@ -0,0 +1,4 @@
+SET search_path TO app, public;
+
+DROP INDEX IF EXISTS video_daily_day_idx;
+DROP INDEX IF EXISTS events_ts_idx;
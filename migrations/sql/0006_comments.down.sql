@@ -0,0 +1,4 @@
+SET search_path TO app, public;
+
+DROP TABLE IF EXISTS comment_likes;
+DROP TABLE IF EXISTS comments;
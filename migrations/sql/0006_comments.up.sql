@@ -0,0 +1,34 @@
+SET search_path TO app, public;
+
+-- Комментарии к видео (двухуровневые треды)
+CREATE TABLE IF NOT EXISTS comments (
+    id          uuid PRIMARY KEY,
+    video_id    uuid NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    user_id     uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id   uuid REFERENCES comments(id) ON DELETE CASCADE,
+    body        text NOT NULL,
+    likes       bigint NOT NULL DEFAULT 0,
+    replies     bigint NOT NULL DEFAULT 0,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    deleted_at  timestamptz
+);
+
+-- Keyset-пагинация корневых комментариев и ответов
+CREATE INDEX IF NOT EXISTS comments_video_created_idx
+    ON comments (video_id, created_at DESC, id DESC) WHERE parent_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS comments_parent_created_idx
+    ON comments (parent_id, created_at DESC, id DESC) WHERE parent_id IS NOT NULL;
+
+-- Активность для фида commented
+CREATE INDEX IF NOT EXISTS comments_created_idx
+    ON comments (created_at) WHERE deleted_at IS NULL;
+
+-- Лайки комментариев
+CREATE TABLE IF NOT EXISTS comment_likes (
+    comment_id  uuid NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id     uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (comment_id, user_id)
+);
@@ -0,0 +1,4 @@
+SET search_path TO app, public;
+
+DROP INDEX IF EXISTS videos_author_uploaded_idx;
+DROP TABLE IF EXISTS subscriptions;
@@ -0,0 +1,18 @@
+SET search_path TO app, public;
+
+-- Подписки зрителей на авторов
+CREATE TABLE IF NOT EXISTS subscriptions (
+    follower_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    author_id   uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (follower_id, author_id),
+    CONSTRAINT subscriptions_not_self CHECK (follower_id <> author_id)
+);
+
+-- Подсчёт подписчиков автора
+CREATE INDEX IF NOT EXISTS subscriptions_author_idx
+    ON subscriptions (author_id);
+
+-- Фид подписок: новые видео автора с keyset-пагинацией
+CREATE INDEX IF NOT EXISTS videos_author_uploaded_idx
+    ON videos (author_id, uploaded_at DESC, id DESC);
@@ -0,0 +1,4 @@
+SET search_path TO app, public;
+
+DROP TABLE IF EXISTS user_settings;
+DROP TABLE IF EXISTS watch_history;
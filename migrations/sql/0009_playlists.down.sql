@@ -0,0 +1,4 @@
+SET search_path TO app, public;
+
+DROP TABLE IF EXISTS playlist_items;
+DROP TABLE IF EXISTS playlists;
@@ -0,0 +1,33 @@
+SET search_path TO app, public;
+
+-- Плейлисты пользователей, включая встроенный "смотреть позже"
+CREATE TABLE IF NOT EXISTS playlists (
+    id          uuid PRIMARY KEY,
+    owner_id    uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title       text NOT NULL,
+    visibility  text NOT NULL DEFAULT 'private'
+        CHECK (visibility IN ('public', 'unlisted', 'private')),
+    kind        text NOT NULL DEFAULT 'user'
+        CHECK (kind IN ('user', 'watch_later')),
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS playlists_owner_idx
+    ON playlists (owner_id, created_at DESC);
+
+-- Не больше одного "смотреть позже" на пользователя
+CREATE UNIQUE INDEX IF NOT EXISTS playlists_watch_later_uniq
+    ON playlists (owner_id) WHERE kind = 'watch_later';
+
+-- Видео в плейлисте по порядку (позиции с 0)
+CREATE TABLE IF NOT EXISTS playlist_items (
+    playlist_id uuid NOT NULL REFERENCES playlists(id) ON DELETE CASCADE,
+    video_id    uuid NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    position    int  NOT NULL,
+    added_at    timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (playlist_id, video_id)
+);
+
+CREATE INDEX IF NOT EXISTS playlist_items_position_idx
+    ON playlist_items (playlist_id, position);
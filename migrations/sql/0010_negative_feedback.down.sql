@@ -0,0 +1,15 @@
+SET search_path TO app, public;
+
+DROP TABLE IF EXISTS user_tag_feedback;
+DROP TABLE IF EXISTS user_hidden_videos;
+
+ALTER TABLE video_daily DROP COLUMN IF EXISTS hides;
+ALTER TABLE video_daily DROP COLUMN IF EXISTS unlikes;
+ALTER TABLE video_daily DROP COLUMN IF EXISTS dislikes;
+
+ALTER TABLE video_counters DROP COLUMN IF EXISTS hides;
+ALTER TABLE video_counters DROP COLUMN IF EXISTS dislikes;
+
+ALTER TABLE events DROP COLUMN IF EXISTS tag;
+
+-- Значения из enum event_type в Postgres не удаляются; они остаются неиспользуемыми
@@ -0,0 +1,4 @@
+SET search_path TO app, public;
+
+DROP INDEX IF EXISTS events_session_ts_idx;
+DROP TABLE IF EXISTS video_neighbors;
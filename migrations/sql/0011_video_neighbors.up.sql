@@ -0,0 +1,19 @@
+SET search_path TO app, public;
+
+-- Соседи видео по совместным просмотрам в сессиях ("кто смотрел X, смотрел и Y").
+-- Пересчитывается целиком фоновой задачей covisitation.
+CREATE TABLE IF NOT EXISTS video_neighbors (
+    video_id    uuid NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    neighbor_id uuid NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    score       double precision NOT NULL,
+    sessions    int NOT NULL,
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (video_id, neighbor_id)
+);
+
+CREATE INDEX IF NOT EXISTS video_neighbors_score_idx
+    ON video_neighbors (video_id, score DESC);
+
+-- Последовательности просмотров в сессиях
+CREATE INDEX IF NOT EXISTS events_session_ts_idx
+    ON events (session_id, ts) WHERE video_id IS NOT NULL;
@@ -0,0 +1,6 @@
+SET search_path TO app, public;
+
+DROP INDEX IF EXISTS events_user_ts_idx;
+DROP TABLE IF EXISTS video_factors;
+DROP TABLE IF EXISTS user_factors;
+DROP TABLE IF EXISTS als_model;
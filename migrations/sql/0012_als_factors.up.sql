@@ -0,0 +1,28 @@
+SET search_path TO app, public;
+
+-- Параметры последней обученной ALS-модели (одна строка)
+CREATE TABLE IF NOT EXISTS als_model (
+    id          smallint PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    version     bigint NOT NULL,
+    factors     int NOT NULL,
+    lambda      double precision NOT NULL,
+    alpha       double precision NOT NULL,
+    users       int NOT NULL,
+    videos      int NOT NULL,
+    trained_at  timestamptz NOT NULL DEFAULT now()
+);
+
+-- Факторы пользователей и видео, выгружаются cmd/train целиком
+CREATE TABLE IF NOT EXISTS user_factors (
+    user_id  uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    factors  double precision[] NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS video_factors (
+    video_id uuid PRIMARY KEY REFERENCES videos(id) ON DELETE CASCADE,
+    factors  double precision[] NOT NULL
+);
+
+-- Сигналы пользователя для обучения и fold-in
+CREATE INDEX IF NOT EXISTS events_user_ts_idx
+    ON events (user_id, ts) WHERE user_id IS NOT NULL AND video_id IS NOT NULL;
@@ -0,0 +1,5 @@
+SET search_path TO app, public;
+
+DROP TABLE IF EXISTS experiment_exposures;
+DROP TABLE IF EXISTS experiment_variants;
+DROP TABLE IF EXISTS experiments;
@@ -0,0 +1,44 @@
+SET search_path TO app, public;
+
+-- A/B-эксперименты над выдачей фидов, рекомендаций и поиска
+CREATE TABLE IF NOT EXISTS experiments (
+    id          uuid PRIMARY KEY,
+    key         text NOT NULL UNIQUE,
+    description text NOT NULL DEFAULT '',
+    surface     text NOT NULL
+        CHECK (surface IN ('feed', 'recommendations', 'search')),
+    status      text NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'running', 'stopped')),
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    started_at  timestamptz,
+    stopped_at  timestamptz
+);
+
+-- На каждой поверхности одновременно идёт не больше одного эксперимента
+CREATE UNIQUE INDEX IF NOT EXISTS experiments_running_surface_uniq
+    ON experiments (surface) WHERE status = 'running';
+
+-- Варианты эксперимента с долями трафика и параметрами выдачи
+CREATE TABLE IF NOT EXISTS experiment_variants (
+    experiment_id uuid NOT NULL REFERENCES experiments(id) ON DELETE CASCADE,
+    name          text NOT NULL,
+    position      int  NOT NULL,
+    weight        int  NOT NULL CHECK (weight > 0),
+    params        jsonb NOT NULL DEFAULT '{}',
+    PRIMARY KEY (experiment_id, name)
+);
+
+-- Первая показанная единице (пользователю или сессии) выдача варианта
+CREATE TABLE IF NOT EXISTS experiment_exposures (
+    experiment_id    uuid NOT NULL REFERENCES experiments(id) ON DELETE CASCADE,
+    unit_id          text NOT NULL,
+    variant          text NOT NULL,
+    user_id          uuid,
+    session_id       text,
+    first_exposed_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (experiment_id, unit_id)
+);
+
+CREATE INDEX IF NOT EXISTS experiment_exposures_variant_idx
+    ON experiment_exposures (experiment_id, variant);
@@ -0,0 +1,5 @@
+SET search_path TO app, public;
+
+ALTER TABLE events DROP COLUMN IF EXISTS serve_id;
+DROP TABLE IF EXISTS impressions;
+DROP TABLE IF EXISTS serves;
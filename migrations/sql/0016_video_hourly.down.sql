@@ -0,0 +1,3 @@
+SET search_path TO app, public;
+
+DROP TABLE IF EXISTS video_hourly;
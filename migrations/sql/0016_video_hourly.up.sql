@@ -0,0 +1,16 @@
+SET search_path TO app, public;
+
+-- Почасовая аналитика, поддерживается вместе с video_daily
+CREATE TABLE IF NOT EXISTS video_hourly (
+    video_id     uuid NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    hour         timestamptz NOT NULL,
+    views        bigint NOT NULL DEFAULT 0,
+    completes    bigint NOT NULL DEFAULT 0,
+    likes        bigint NOT NULL DEFAULT 0,
+    clicks       bigint NOT NULL DEFAULT 0,
+    impressions  bigint NOT NULL DEFAULT 0,
+    dwell_ms_sum bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (video_id, hour)
+);
+
+CREATE INDEX IF NOT EXISTS video_hourly_hour_idx ON video_hourly (hour);
@@ -0,0 +1,8 @@
+SET search_path TO app, public;
+
+DROP TABLE IF EXISTS video_retention;
+DROP TABLE IF EXISTS video_watch_progress;
+
+ALTER TABLE events DROP COLUMN IF EXISTS position_ms;
+
+-- Значения из enum event_type в Postgres не удаляются; они остаются неиспользуемыми
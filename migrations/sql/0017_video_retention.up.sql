@@ -0,0 +1,23 @@
+SET search_path TO app, public;
+
+-- Событие позиции воспроизведения для кривых удержания аудитории
+ALTER TYPE event_type ADD VALUE IF NOT EXISTS 'progress';
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS position_ms integer;
+
+-- Максимальная дошедшая точка просмотра видео в сессии: корзина 0..20 по 5% длительности
+CREATE TABLE IF NOT EXISTS video_watch_progress (
+    video_id    uuid NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    session_id  text NOT NULL,
+    max_bucket  smallint NOT NULL,
+    updated_at  timestamptz NOT NULL,
+    PRIMARY KEY (video_id, session_id)
+);
+
+-- Гистограмма удержания: сколько зрителей дошли до каждой корзины
+CREATE TABLE IF NOT EXISTS video_retention (
+    video_id  uuid NOT NULL REFERENCES videos(id) ON DELETE CASCADE,
+    bucket    smallint NOT NULL CHECK (bucket BETWEEN 0 AND 20),
+    viewers   bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (video_id, bucket)
+);
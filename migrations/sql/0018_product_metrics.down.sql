@@ -0,0 +1,7 @@
+SET search_path TO app, public;
+
+DROP INDEX IF EXISTS users_created_at_idx;
+DROP TABLE IF EXISTS metrics_refresh;
+DROP TABLE IF EXISTS funnel_daily;
+DROP TABLE IF EXISTS cohort_weekly;
+DROP TABLE IF EXISTS activity_daily;
@@ -0,0 +1,3 @@
+SET search_path TO app, public;
+
+DROP INDEX IF EXISTS events_video_ts_idx;
@@ -0,0 +1,36 @@
+SET search_path TO app, public;
+
+DROP TABLE IF EXISTS video_counters_base;
+DROP TABLE IF EXISTS events_retention;
+
+CREATE TABLE events_unpartitioned (
+    event_id    uuid PRIMARY KEY,
+    ts          timestamptz NOT NULL,
+    type        event_type  NOT NULL,
+    session_id  text        NOT NULL,
+    user_id     uuid,
+    video_id    uuid,
+    query       text,
+    dwell_ms    int,
+    tag         text,
+    serve_id    uuid,
+    position_ms integer,
+    CONSTRAINT events_video_fk FOREIGN KEY (video_id) REFERENCES videos(id),
+    CONSTRAINT events_user_fk  FOREIGN KEY (user_id)  REFERENCES users(id)
+);
+
+-- Дубли event_id с разным ts в непартиционированной таблице не помещаются: остаётся самый ранний
+INSERT INTO events_unpartitioned
+SELECT DISTINCT ON (event_id) event_id, ts, type, session_id, user_id, video_id, query, dwell_ms, tag, serve_id, position_ms
+FROM events
+ORDER BY event_id, ts;
+
+DROP TABLE events;
+DROP FUNCTION IF EXISTS ensure_event_partitions(timestamptz, timestamptz);
+ALTER TABLE events_unpartitioned RENAME TO events;
+ALTER INDEX events_unpartitioned_pkey RENAME TO events_pkey;
+
+CREATE INDEX IF NOT EXISTS events_ts_idx ON events (ts);
+CREATE INDEX IF NOT EXISTS events_session_ts_idx ON events (session_id, ts) WHERE video_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS events_user_ts_idx ON events (user_id, ts) WHERE user_id IS NOT NULL AND video_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS events_video_ts_idx ON events (video_id, ts) WHERE video_id IS NOT NULL;
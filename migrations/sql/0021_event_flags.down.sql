@@ -0,0 +1,3 @@
+SET search_path TO app, public;
+
+ALTER TABLE events DROP COLUMN IF EXISTS flag;
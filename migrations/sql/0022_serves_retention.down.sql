@@ -0,0 +1,4 @@
+SET search_path TO app, public;
+
+DROP TABLE IF EXISTS serves_retention;
+DROP INDEX IF EXISTS serves_ts_idx;
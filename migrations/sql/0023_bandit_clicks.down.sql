@@ -0,0 +1 @@
+-- Пересчёт кликов не откатывается: прежние значения включали органические просмотры